@@ -1,8 +1,9 @@
 package app
 
 import (
-	"encoding/json"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 
 	"fmt"
@@ -29,25 +30,38 @@ const (
 	PluginNameBase = "base"
 	// PluginNameEyes defines the eyes plugin's name
 	PluginNameEyes = "eyes"
+
+	// DefaultInvariantCheckInterval defines how many blocks
+	// pass between two checks of the ledger's invariants.
+	// A check scans every wallet, hence it doesn't run every block.
+	DefaultInvariantCheckInterval = 100
 )
 
 // Ledger defines the attributes of the app
 type Ledger struct {
 	eyesCli    *eyes.Client
 	state      *state.State
-	cacheState *state.State
+	cacheState *state.State // CheckTx's, reset on Commit
 	plugins    *bctypes.Plugins
+	history    *state.History // optional
+	genesis    *types.GenesisDoc
+
+	invariantCheckInterval uint64 // 0 disables the checks
 }
 
 // NewLedger creates a new instance of the app
 func NewLedger(eyesCli *eyes.Client) *Ledger {
 	state := state.NewState(eyesCli)
+	// A restarted node checks Txs against its last block until the next one
+	state.LoadBlockHeight()
 	plugins := bctypes.NewPlugins()
 	return &Ledger{
 		eyesCli:    eyesCli,
 		state:      state,
-		cacheState: nil,
+		cacheState: state.CacheWrap(),
 		plugins:    plugins,
+
+		invariantCheckInterval: DefaultInvariantCheckInterval,
 	}
 }
 
@@ -56,6 +70,41 @@ func (app *Ledger) Info() abci.ResponseInfo {
 	return abci.ResponseInfo{Data: common.Fmt("Ledger v%v", version)}
 }
 
+// SetGenesis sets the genesis document the state is initialized with
+// when the chain starts. Its chain ID is set right away, in CheckTx's
+// state too, as the state only keeps it in memory and a restarted node
+// checks Txs before InitChain or Commit.
+func (app *Ledger) SetGenesis(doc *types.GenesisDoc) {
+	app.genesis = doc
+	app.state.SetChainID(doc.ChainID)
+	app.cacheState = app.state.CacheWrap()
+}
+
+// SetHistory enables historical queries by recording
+// the state's changes in the given History. The History's last height
+// must be the state's, unless it's empty: as they're committed one after
+// the other, a node stopped in between would otherwise serve a history
+// missing the changes of its last block. The History must then be
+// rebuilt from an empty database.
+func (app *Ledger) SetHistory(history *state.History) error {
+	chainID, height := app.state.ChainID(), app.state.BlockHeight()
+	if last := history.LastHeight(); last != 0 && last != height {
+		return fmt.Errorf("The history's last height %d isn't the state's %d, delete its database to rebuild it", last, height)
+	}
+	app.history = history
+	app.state = state.NewState(history.Wrap(app.eyesCli))
+	app.state.SetChainID(chainID)
+	app.state.SetBlockHeight(height)
+	app.cacheState = app.state.CacheWrap()
+	return nil
+}
+
+// SetInvariantCheckInterval sets how many blocks pass between two
+// checks of the ledger's invariants, 0 disables the checks.
+func (app *Ledger) SetInvariantCheckInterval(interval uint64) {
+	app.invariantCheckInterval = interval
+}
+
 func (app *Ledger) RegisterPlugin(plugin bctypes.Plugin) {
 	app.plugins.RegisterPlugin(plugin)
 }
@@ -75,42 +124,7 @@ func (app *Ledger) SetOption(key string, value string) (log string) {
 	switch key {
 	case "chainID":
 		app.state.SetChainID(value)
-		return "Success"
-	case "account":
-		var err error
-		var acc *types.Account
-		wire.ReadJSONPtr(&acc, []byte(value), &err)
-		if err != nil {
-			panic("Error decoding acc message: " + err.Error())
-		}
-		app.state.SetAccount(acc.ID, acc)
-		state.SetAccountInIndex(app.state, *acc)
-		app.Commit()
-		return "Success"
-	case "user":
-		var err error
-		var user *types.User
-		wire.ReadJSONPtr(&user, []byte(value), &err)
-		if err != nil {
-			panic("Error decoding user message: " + err.Error())
-		}
-
-		app.state.SetUser(user.PubKey.Address(), user)
-		app.Commit()
-		return "Success"
-	case "legalEntity":
-		var legalEntity types.LegalEntity
-
-		err := json.Unmarshal([]byte(value), &legalEntity)
-
-		if err != nil {
-			panic("Error decoding legalEntity message: " + err.Error())
-		}
-
-		app.state.SetLegalEntity(legalEntity.ID, &legalEntity)
-		state.SetLegalEntityInIndex(app.state, &legalEntity)
-		app.Commit()
-
+		app.cacheState = app.state.CacheWrap()
 		return "Success"
 	}
 	return "Unrecognized option key " + key
@@ -121,7 +135,9 @@ func (app *Ledger) DeliverTx(txBytes []byte) (res abci.Result) {
 	return app.executeTx(txBytes, false)
 }
 
-// CheckTx handles checkTx
+// CheckTx handles checkTx. Txs are checked against a cache of the
+// state holding the nonces of the Txs in the mempool, as basecoin does
+// with sequences, so that a signer may send Txs faster than one a block.
 func (app *Ledger) CheckTx(txBytes []byte) (res abci.Result) {
 	return app.executeTx(txBytes, true)
 }
@@ -139,22 +155,35 @@ func (app *Ledger) Commit() (res abci.Result) {
 	if res.IsErr() {
 		common.PanicSanity("Error getting hash: " + res.Error())
 	}
+	if app.history != nil {
+		app.history.Commit()
+	}
+	// The mempool's remaining Txs are rechecked against the new state
+	app.cacheState = app.state.CacheWrap()
 	return res
 }
 
 // InitChain initializes the chain
 func (app *Ledger) InitChain(validators []*abci.Validator) {
+	if app.genesis != nil {
+		state.LoadGenesis(app.state, app.genesis)
+	}
 	for _, plugin := range app.plugins.GetList() {
 		plugin.InitChain(app.state, validators)
 	}
+	app.cacheState = app.state.CacheWrap()
 }
 
 // abci::BeginBlock
 func (app *Ledger) BeginBlock(hash []byte, header *abci.Header) {
+	app.state.SetBlockHeight(header.Height)
+	app.state.StoreBlockHeight()
+	if app.history != nil {
+		app.history.BeginBlock(header.Height)
+	}
 	for _, plugin := range app.plugins.GetList() {
 		plugin.BeginBlock(app.state, hash, header)
 	}
-	app.cacheState = app.state.CacheWrap()
 }
 
 // abci::EndBlock
@@ -163,6 +192,12 @@ func (app *Ledger) EndBlock(height uint64) (res abci.ResponseEndBlock) {
 		pluginRes := plugin.EndBlock(app.state, height)
 		res.Diffs = append(res.Diffs, pluginRes.Diffs...)
 	}
+	// Halt rather than keep running on top of an inconsistent ledger
+	if app.invariantCheckInterval > 0 && height%app.invariantCheckInterval == 0 {
+		if err := state.CheckInvariants(app.state); err != nil {
+			common.PanicCrisis(common.Fmt("Ledger invariant violated at height %d: %v", height, err))
+		}
+	}
 	return
 }
 
@@ -177,7 +212,14 @@ func (app *Ledger) executeTx(txBytes []byte, simulate bool) (res abci.Result) {
 		return abci.ErrBaseEncodingError.AppendLog("Error decoding tx: " + err.Error())
 	}
 	// Validate and exec tx
-	res = state.ExecTx(app.state, app.plugins, tx, simulate, nil)
+	execState := app.state
+	if simulate {
+		execState = app.cacheState
+	}
+	res = state.ExecTx(execState, app.plugins, tx, simulate, nil)
+	if !simulate {
+		state.RecordTx(app.state, txBytes, tx, res)
+	}
 	if res.IsErr() {
 		return res.PrependLog("Error in DeliverTx")
 	}
@@ -186,14 +228,46 @@ func (app *Ledger) executeTx(txBytes []byte, simulate bool) (res abci.Result) {
 
 func (app *Ledger) executeQuery(req abci.RequestQuery) (res abci.ResponseQuery) {
 	
-	resource, object, err := splitQueryPath(req.Path)
+	query, err := splitQueryPath(req.Path)
 	if err != nil {
 		res.Code = abci.CodeType_UnknownRequest
 		res.Log = common.Fmt("in executeQuery(): %s", err)
 		return
 	}
 	
-	return state.ExecQuery(app.state, resource, object)
+	// Tendermint's RPC may not forward the request's height,
+	// hence it can be given as a query parameter too.
+	height := req.Height
+	if h := query.Params.Get("height"); height == 0 && len(h) > 0 {
+		if height, err = strconv.ParseUint(h, 10, 64); err != nil {
+			res.Code = abci.CodeType_BaseInvalidInput
+			res.Log = common.Fmt("in executeQuery(): invalid height: %q", h)
+			return
+		}
+	}
+
+	queryState := app.state
+	if height != 0 {
+		if app.history == nil {
+			res.Code = abci.CodeType_BaseInvalidInput
+			res.Log = "in executeQuery(): historical queries are disabled"
+			return
+		}
+		store, err := app.history.At(app.eyesCli, height)
+		if err != nil {
+			res.Code = abci.CodeType_BaseInvalidInput
+			res.Log = common.Fmt("in executeQuery(): %s", err)
+			return
+		}
+		queryState = state.NewState(store)
+		queryState.SetChainID(app.state.ChainID())
+	}
+	res = state.ExecQuery(queryState, query)
+	res.Height = height
+	if height == 0 && app.history != nil {
+		res.Height = app.history.LastHeight()
+	}
+	return res
 }
 
 // Splits the string at the first '/'.
@@ -206,22 +280,27 @@ func splitKey(key string) (prefix string, suffix string) {
 	return key, ""
 }
 
-// Split query path
-func splitQueryPath(path string) (string, string, error) {
-	var resource, object string
-	re := regexp.MustCompile(`^/(?P<resource>[A-Za-z0-9_]+)(?:/(?P<object>[A-Za-z0-9\-]+)/?)?$`)
+// Split query path, e.g. /resource/object/subresource?key=value
+func splitQueryPath(path string) (q state.Query, err error) {
+	re := regexp.MustCompile(`^/(?P<resource>[A-Za-z0-9_]+)(?:/(?P<object>[A-Za-z0-9\-]+)(?:/(?P<subresource>[A-Za-z0-9_]+))?)?/?(?:\?(?P<params>.*))?$`)
 	names := re.SubexpNames()
 	matches := re.FindAllStringSubmatch(path, -1)
 	if len(matches) < 1 {
-		return "", "", fmt.Errorf("malformed resource path: %q", path)
+		return q, fmt.Errorf("malformed resource path: %q", path)
 	}
 	for i, n := range matches[0] {
 		switch names[i] {
 		case "resource":
-			resource = n
+			q.Resource = n
 		case "object":
-			object = n
+			q.Object = n
+		case "subresource":
+			q.SubResource = n
+		case "params":
+			if q.Params, err = url.ParseQuery(n); err != nil {
+				return q, fmt.Errorf("malformed query parameters: %q", n)
+			}
 		}
 	}
-	return resource, object, nil
+	return q, nil
 }
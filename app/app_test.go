@@ -1,10 +1,11 @@
 package app
 
 import (
+	"net/url"
+	"reflect"
 	"testing"
 	
 	bscoin "github.com/tendermint/basecoin/types"
-	"github.com/tendermint/clearchain/types"
 	
 	abci "github.com/tendermint/abci/types"
 	bctypes "github.com/tendermint/basecoin/types"
@@ -19,28 +20,28 @@ func Test_splitQueryPath(t *testing.T) {
 	tests := []struct {
 		name    string
 		args    args
-		want    string
-		want1   string
+		want    state.Query
 		wantErr bool
 	}{
-		{"validPath_generic", args{"/resource/object"}, "resource", "object", false},
-		{"validPath_no_resource", args{"/resource"}, "resource", "", false},
-		{"validPath_legal_entity_all", args{"/legal_entity"}, "legal_entity", "", false},
-		{"validPath_account_id", args{"/account/1d2df1ae-accb-11e6-bbbb-00ff5244ae7f"}, "account", "1d2df1ae-accb-11e6-bbbb-00ff5244ae7f", false},
+		{"validPath_generic", args{"/resource/object"}, state.Query{Resource: "resource", Object: "object", Params: url.Values{}}, false},
+		{"validPath_no_resource", args{"/resource"}, state.Query{Resource: "resource", Params: url.Values{}}, false},
+		{"validPath_legal_entity_all", args{"/legal_entity"}, state.Query{Resource: "legal_entity", Params: url.Values{}}, false},
+		{"validPath_account_id", args{"/account/1d2df1ae-accb-11e6-bbbb-00ff5244ae7f"}, state.Query{Resource: "account", Object: "1d2df1ae-accb-11e6-bbbb-00ff5244ae7f", Params: url.Values{}}, false},
+		{"validPath_pagination", args{"/account?cursor=10&limit=5"}, state.Query{Resource: "account", Params: url.Values{"cursor": {"10"}, "limit": {"5"}}}, false},
+		{"validPath_subresource", args{"/legal_entity/b40cbf4e-5923-4ccd-beec-e22a9117b91b/accounts?limit=5"}, state.Query{Resource: "legal_entity", Object: "b40cbf4e-5923-4ccd-beec-e22a9117b91b", SubResource: "accounts", Params: url.Values{"limit": {"5"}}}, false},
+		{"invalidPath_too_deep", args{"/legal_entity/id/accounts/id"}, state.Query{}, true},
+		{"invalidPath_no_slash", args{"account"}, state.Query{}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, got1, err := splitQueryPath(tt.args.path)
+			got, err := splitQueryPath(tt.args.path)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("splitQueryPath() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			if got != tt.want {
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("splitQueryPath() got = %v, want %v", got, tt.want)
 			}
-			if got1 != tt.want1 {
-				t.Errorf("splitQueryPath() got1 = %v, want %v", got1, tt.want1)
-			}
 		})
 	}
 }
@@ -58,9 +59,7 @@ func TestLedger_executeQuery(t *testing.T) {
 	
 	makeNewClient :=  bscoin.NewMemKVStore()
 	s := state.NewState(makeNewClient)
-	accountIndex := types.NewAccountIndex()
-	accountIndex.Add("testId")
-	s.SetAccountIndex(accountIndex)
+	s.AccountIndex().Add("testId")
 	
 	tests := []struct {
 		name    string
@@ -92,3 +91,37 @@ func TestLedger_executeQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestLedger_SetHistory(t *testing.T) {
+	tests := []struct {
+		name        string
+		appHeight   uint64
+		historyLast uint64 // 0 if empty
+		wantErr     bool
+	}{
+		{"emptyHistory", 5, 0, false},
+		{"sameHeight", 5, 5, false},
+		{"historyBehind", 5, 4, true},
+		{"historyAhead", 5, 6, true},
+		{"emptyState", 0, 5, true},
+	}
+	for _, tt := range tests {
+		eyesCli := eyes.NewLocalClient("", 0)
+		s := state.NewState(eyesCli)
+		s.SetBlockHeight(tt.appHeight)
+		s.StoreBlockHeight()
+		history := state.NewHistory(bscoin.NewMemKVStore(), 0)
+		if tt.historyLast != 0 {
+			history.BeginBlock(tt.historyLast)
+			history.Commit()
+		}
+
+		app := NewLedger(eyesCli)
+		if err := app.SetHistory(history); (err != nil) != tt.wantErr {
+			t.Errorf("%q. Ledger.SetHistory() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if enabled := app.history != nil; enabled == tt.wantErr {
+			t.Errorf("%q. Ledger history enabled = %v, want %v", tt.name, enabled, !tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,501 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/satori/go.uuid"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// DefaultBatchConcurrency is the number of Txs SubmitBatch sends at once
+const DefaultBatchConcurrency = 4
+
+// Formats of batch files
+const (
+	BatchFormatJSONL = "jsonl" // A JSON object per line
+	BatchFormatCSV   = "csv"   // A header line naming the fields, then a record per line
+)
+
+// Types of the Txs of a batch, the value of their "type" field
+const (
+	BatchTypeCreateUser        = "create_user"
+	BatchTypeCreateAccount     = "create_account"
+	BatchTypeCreateLegalEntity = "create_legal_entity"
+	BatchTypeIssue             = "issue"
+	BatchTypeRedeem            = "redeem"
+	BatchTypeTransfer          = "transfer"
+)
+
+// Statuses of a BatchResult
+const (
+	BatchStatusPending = "pending" // Sent, its outcome is unknown yet
+	BatchStatusOK      = "ok"
+	BatchStatusFailed  = "failed"
+)
+
+// BatchTx specifies a Tx of a batch. In a batch file, its fields are named
+// as in the comments below, amounts are decimal, e.g. 100.00, and public
+// keys are base64 encoded.
+type BatchTx struct {
+	Line     int    // Line of the batch file, from 1
+	Type     string // type
+	Signer   string // signer: key of BatchOptions.Signers, the Client's signer if empty
+	ClientID string // client_id: generated if empty
+
+	AccountID   string        // account_id: create_account, issue, redeem
+	Name        string        // name: create_user, create_legal_entity
+	PubKey      crypto.PubKey // pub_key: create_user
+	CanCreate   bool          // can_create: create_user
+	EntityID    string        // entity_id: create_legal_entity
+	EntityType  byte          // entity_type: create_legal_entity, by name or value
+	ParentID    string        // parent_id: create_legal_entity
+	SenderID    string        // sender_id: transfer
+	RecipientID string        // recipient_id: transfer
+	Amount      string        // amount: issue, redeem, transfer
+	Currency    string        // currency: issue, redeem, transfer
+	Reference   string        // reference: transfer
+	Memo        string        // memo: transfer
+}
+
+// BatchResult is the outcome of a BatchTx. The report of a batch is made
+// of BatchResults, one JSON object per line, see ReadBatchReport.
+type BatchResult struct {
+	Line     int    `json:"line"`
+	ClientID string `json:"client_id,omitempty"`
+	Status   string `json:"status"`
+	Hash     string `json:"hash,omitempty"` // Hex encoded, as shown by 'ledgerctl tx show'
+	Height   uint64 `json:"height,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// BatchOptions configures SubmitBatch.
+type BatchOptions struct {
+	Signers     map[string]Signer   // Signers referenced by the BatchTxs' Signer
+	Concurrency int                 // Number of Txs sent at once, DefaultBatchConcurrency if 0
+	Previous    map[int]BatchResult // Results of an earlier run of the batch by line, see ReadBatchReport
+	Report      func(BatchResult)   // Called with every result, pending ones included, one at a time
+}
+
+// ReadBatch reads a batch file in the given format.
+func ReadBatch(r io.Reader, format string) ([]BatchTx, error) {
+	switch format {
+	case BatchFormatJSONL:
+		return readBatchJSONL(r)
+	case BatchFormatCSV:
+		return readBatchCSV(r)
+	default:
+		return nil, fmt.Errorf("Unknown batch format %q, use %s or %s", format, BatchFormatJSONL, BatchFormatCSV)
+	}
+}
+
+func readBatchJSONL(r io.Reader) ([]BatchTx, error) {
+	txs := []BatchTx{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var object map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(scanner.Text()))
+		decoder.UseNumber()
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("Line %d: %v", line, err)
+		}
+		fields := make(map[string]string, len(object))
+		for name, value := range object {
+			switch value := value.(type) {
+			case nil:
+			case string:
+				fields[name] = value
+			case json.Number:
+				fields[name] = value.String()
+			case bool:
+				fields[name] = strconv.FormatBool(value)
+			default:
+				return nil, fmt.Errorf("Line %d: invalid value of %q", line, name)
+			}
+		}
+		tx, err := parseBatchTx(line, fields)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+	return txs, scanner.Err()
+}
+
+func readBatchCSV(r io.Reader) ([]BatchTx, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the header line: %v", err)
+	}
+	txs := []BatchTx{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return txs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		fields := make(map[string]string, len(header))
+		for i, name := range header {
+			if len(record[i]) > 0 {
+				fields[strings.TrimSpace(name)] = record[i]
+			}
+		}
+		tx, err := parseBatchTx(line, fields)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+	}
+}
+
+// parseBatchTx parses the fields of a line of a batch file.
+func parseBatchTx(line int, fields map[string]string) (BatchTx, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tx := BatchTx{Line: line}
+	for _, name := range names {
+		value := fields[name]
+		var err error
+		switch name {
+		case "type":
+			tx.Type = value
+		case "signer":
+			tx.Signer = value
+		case "client_id":
+			tx.ClientID = value
+		case "account_id":
+			tx.AccountID = value
+		case "name":
+			tx.Name = value
+		case "pub_key":
+			var pubKeyBytes []byte
+			if pubKeyBytes, err = base64.StdEncoding.DecodeString(value); err == nil {
+				tx.PubKey, err = crypto.PubKeyFromBytes(pubKeyBytes)
+			}
+		case "can_create":
+			tx.CanCreate, err = strconv.ParseBool(value)
+		case "entity_id":
+			tx.EntityID = value
+		case "entity_type":
+			var ok bool
+			if tx.EntityType, ok = types.ParseEntityType(value); !ok {
+				err = errors.New("unknown entity type")
+			}
+		case "parent_id":
+			tx.ParentID = value
+		case "sender_id":
+			tx.SenderID = value
+		case "recipient_id":
+			tx.RecipientID = value
+		case "amount":
+			tx.Amount = value
+		case "currency":
+			tx.Currency = value
+		case "reference":
+			tx.Reference = value
+		case "memo":
+			tx.Memo = value
+		default:
+			err = errors.New("unknown field")
+		}
+		if err != nil {
+			return BatchTx{}, fmt.Errorf("Line %d: invalid %s %q: %v", line, name, value, err)
+		}
+	}
+	if err := tx.validate(); err != nil {
+		return BatchTx{}, fmt.Errorf("Line %d: %v", line, err)
+	}
+	return tx, nil
+}
+
+// validate checks that the fields a BatchTx's type needs are set.
+func (tx BatchTx) validate() error {
+	var missing []string
+	require := func(name string, set bool) {
+		if !set {
+			missing = append(missing, name)
+		}
+	}
+	switch tx.Type {
+	case BatchTypeCreateUser:
+		require("name", len(tx.Name) > 0)
+		require("pub_key", tx.PubKey != nil)
+	case BatchTypeCreateAccount:
+		require("account_id", len(tx.AccountID) > 0)
+	case BatchTypeCreateLegalEntity:
+		require("entity_id", len(tx.EntityID) > 0)
+		require("entity_type", tx.EntityType != 0)
+		require("parent_id", len(tx.ParentID) > 0)
+	case BatchTypeIssue, BatchTypeRedeem:
+		require("account_id", len(tx.AccountID) > 0)
+		require("amount", len(tx.Amount) > 0)
+		require("currency", len(tx.Currency) > 0)
+	case BatchTypeTransfer:
+		require("sender_id", len(tx.SenderID) > 0)
+		require("recipient_id", len(tx.RecipientID) > 0)
+		require("amount", len(tx.Amount) > 0)
+		require("currency", len(tx.Currency) > 0)
+	default:
+		return fmt.Errorf("Unknown tx type %q", tx.Type)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Missing %s of %s", strings.Join(missing, ", "), tx.Type)
+	}
+	return nil
+}
+
+// ReadBatchReport reads the report of a batch, and returns the last result
+// of each line.
+func ReadBatchReport(r io.Reader) (map[int]BatchResult, error) {
+	results := map[int]BatchResult{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var result BatchResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			return nil, fmt.Errorf("Report line %d: %v", line, err)
+		}
+		results[result.Line] = result
+	}
+	return results, scanner.Err()
+}
+
+// SubmitBatch sends a batch of Txs, and returns their results in order. A
+// signer's Txs are executed in order, see sendPipelined, while the Txs of
+// different signers are sent concurrently: a Tx must not depend on the Txs
+// of another signer of the same batch.
+//
+// A Tx failing doesn't stop the batch, which can be resumed by running it
+// again with the results of the first run as opts.Previous: Txs executed
+// then are skipped, and the others are sent with the ClientID of their
+// previous attempt, so that they are executed at most once.
+func (c *Client) SubmitBatch(ctx context.Context, txs []BatchTx, opts BatchOptions) []BatchResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	results := make([]BatchResult, len(txs))
+	var reportMu sync.Mutex
+	report := func(i int, result BatchResult) {
+		reportMu.Lock()
+		defer reportMu.Unlock()
+		results[i] = result
+		if opts.Report != nil {
+			opts.Report(result)
+		}
+	}
+
+	// Group the Txs to send by signer, keeping the order of the batch
+	txs = append([]BatchTx{}, txs...)
+	queues := map[string][]int{}
+	signerNames := []string{}
+	for i, tx := range txs {
+		previous, ok := opts.Previous[tx.Line]
+		if ok && previous.Status == BatchStatusOK {
+			results[i] = previous
+			continue
+		}
+		if len(tx.ClientID) == 0 {
+			if ok && len(previous.ClientID) > 0 {
+				txs[i].ClientID = previous.ClientID
+			} else {
+				txs[i].ClientID = uuid.NewV4().String()
+			}
+		}
+		if _, ok := queues[tx.Signer]; !ok {
+			signerNames = append(signerNames, tx.Signer)
+		}
+		queues[tx.Signer] = append(queues[tx.Signer], i)
+	}
+
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, name := range signerNames {
+		wg.Add(1)
+		go func(name string, queue []int) {
+			defer wg.Done()
+			fail := func(i int, err error) {
+				report(i, BatchResult{Line: txs[i].Line, ClientID: txs[i].ClientID, Status: BatchStatusFailed, Error: err.Error()})
+			}
+
+			// The Txs which can't be built fail without using a nonce
+			sc, err := c.batchClient(name, opts.Signers)
+			ready, builders := []int{}, []txBuilder{}
+			for _, i := range queue {
+				if err != nil {
+					fail(i, err)
+					continue
+				}
+				build, buildErr := sc.batchBuilder(ctx, txs[i])
+				if buildErr != nil {
+					fail(i, buildErr)
+					continue
+				}
+				ready, builders = append(ready, i), append(builders, build)
+			}
+			if len(ready) == 0 {
+				return
+			}
+
+			sc.sendMu.Lock()
+			defer sc.sendMu.Unlock()
+			sent := make([]bool, len(ready))
+			sendPipelined(ctx, len(ready), slots,
+				func() (uint64, error) { return sc.nextNonce(ctx, sc.signer.Address()) },
+				func(j int, nonce uint64) (*TxReceipt, error) {
+					tx := txs[ready[j]]
+					signed, err := sc.sign(ctx, builders[j], nonce, tx.ClientID)
+					if err != nil {
+						return nil, err
+					}
+					if !sent[j] {
+						report(ready[j], BatchResult{Line: tx.Line, ClientID: tx.ClientID, Status: BatchStatusPending})
+						sent[j] = true
+					}
+					return sc.submit(ctx, signed, tx.ClientID)
+				},
+				func(j int, receipt *TxReceipt, err error) {
+					if err != nil {
+						fail(ready[j], err)
+						return
+					}
+					tx := txs[ready[j]]
+					report(ready[j], BatchResult{Line: tx.Line, ClientID: tx.ClientID, Status: BatchStatusOK,
+						Hash: fmt.Sprintf("%X", receipt.Hash), Height: receipt.Height})
+				})
+		}(name, queues[name])
+	}
+	wg.Wait()
+	return results
+}
+
+// sendPipelined sends the n Txs of a signer in order, taking a slot for
+// each Tx in flight. As the ledger checks a Tx against the nonces of the
+// signer's Txs in its mempool, the Txs are sent with the signer's next
+// nonces in turn, without waiting for the previous ones to be committed.
+// A Tx rejected for its nonce, e.g. as it overtook the Tx before it or
+// that Tx failed, is sent again with the signer's next nonce once the Txs
+// before it are done, so that the Txs are still executed in order.
+func sendPipelined(ctx context.Context, n int, slots chan struct{}, nextNonce func() (uint64, error),
+	send func(j int, nonce uint64) (*TxReceipt, error), done func(j int, receipt *TxReceipt, err error)) {
+	var nonce uint64 // Of the next Tx, 0 until known
+	previous := make(chan struct{})
+	close(previous)
+	for j := 0; j < n; j++ {
+		// Closed once the Tx and those before it are done
+		finished := make(chan struct{})
+		err := ctx.Err()
+		if err == nil && nonce == 0 {
+			nonce, err = nextNonce()
+		}
+		if err != nil {
+			<-previous
+			done(j, nil, err)
+			close(finished)
+			previous = finished
+			continue
+		}
+
+		slots <- struct{}{}
+		go func(j int, nonce uint64, previous, finished chan struct{}) {
+			receipt, err := send(j, nonce)
+			if IsInvalidNonce(err) {
+				<-previous
+				if nonce, err = nextNonce(); err == nil {
+					receipt, err = send(j, nonce)
+				}
+			}
+			<-slots
+			done(j, receipt, err)
+			<-previous
+			close(finished)
+		}(j, nonce, previous, finished)
+		previous = finished
+		nonce++
+	}
+	<-previous
+}
+
+// batchClient returns the Client sending the Txs of a signer of a batch.
+func (c *Client) batchClient(name string, signers map[string]Signer) (*Client, error) {
+	if len(name) == 0 {
+		if c.signer == nil {
+			return nil, ErrNoSigner
+		}
+		return c, nil
+	}
+	signer, ok := signers[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown signer %q", name)
+	}
+	return c.withSigner(signer), nil
+}
+
+// batchBuilder returns the builder of the Tx a BatchTx specifies.
+func (c *Client) batchBuilder(ctx context.Context, tx BatchTx) (txBuilder, error) {
+	var amount int64
+	if len(tx.Amount) > 0 {
+		var err error
+		if amount, err = c.ParseAmount(ctx, tx.Amount, tx.Currency); err != nil {
+			return nil, err
+		}
+	}
+	switch tx.Type {
+	case BatchTypeCreateUser:
+		return func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+			return &types.CreateUserTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+				Name: tx.Name, PubKey: tx.PubKey, CanCreate: tx.CanCreate}
+		}, nil
+	case BatchTypeCreateAccount:
+		return func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+			return &types.CreateAccountTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+				AccountID: tx.AccountID}
+		}, nil
+	case BatchTypeCreateLegalEntity:
+		return func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+			return &types.CreateLegalEntityTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+				EntityID: tx.EntityID, Type: tx.EntityType, Name: tx.Name, ParentID: tx.ParentID}
+		}, nil
+	case BatchTypeIssue:
+		return func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+			return &types.IssueTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+				AccountID: tx.AccountID, Amount: amount, Currency: tx.Currency}
+		}, nil
+	case BatchTypeRedeem:
+		return func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+			return &types.RedeemTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+				AccountID: tx.AccountID, Amount: amount, Currency: tx.Currency}
+		}, nil
+	case BatchTypeTransfer:
+		t := Transfer{SenderID: tx.SenderID, RecipientID: tx.RecipientID, Amount: amount, Currency: tx.Currency,
+			Reference: tx.Reference, Memo: tx.Memo}
+		return func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+			return t.tx(addr, nonce, validUntil, clientID)
+		}, nil
+	default:
+		return nil, fmt.Errorf("Unknown tx type %q", tx.Type)
+	}
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+func TestReadBatch(t *testing.T) {
+	pubKey := crypto.GenPrivKeyEd25519().PubKey()
+	want := []BatchTx{
+		{Line: 2, Type: BatchTypeCreateLegalEntity, EntityID: "gcm", EntityType: types.EntityTypeGCMByte, Name: "GCM", ParentID: "ch"},
+		{Line: 3, Type: BatchTypeCreateUser, Signer: "admin", Name: "trader", PubKey: pubKey, CanCreate: true},
+		{Line: 4, Type: BatchTypeIssue, ClientID: "issue-1", AccountID: "account", Amount: "100.00", Currency: "EUR"},
+	}
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		want    []BatchTx
+		wantErr bool
+	}{
+		{"jsonl", BatchFormatJSONL, `
+{"type": "create_legal_entity", "entity_id": "gcm", "entity_type": "gcm", "name": "GCM", "parent_id": "ch"}
+{"type": "create_user", "signer": "admin", "name": "trader", "pub_key": "` + Encode(pubKey.Bytes()) + `", "can_create": true}
+{"type": "issue", "client_id": "issue-1", "account_id": "account", "amount": "100.00", "currency": "EUR", "memo": null}
+`, want, false},
+		{"csv", BatchFormatCSV, `type,signer,client_id,account_id,name,pub_key,can_create,entity_id,entity_type,parent_id,amount,currency
+create_legal_entity,,,,GCM,,,gcm,2,ch,,
+create_user,admin,,,trader,` + Encode(pubKey.Bytes()) + `,true,,,,,
+issue,,issue-1,account,,,,,,,100.00,EUR
+`, want, false},
+		{"unknownFormat", "xml", "", nil, true},
+		{"unknownType", BatchFormatJSONL, `{"type": "burn"}`, nil, true},
+		{"unknownField", BatchFormatJSONL, `{"type": "create_account", "account_id": "account", "acount_id": "account"}`, nil, true},
+		{"missingField", BatchFormatJSONL, `{"type": "transfer", "sender_id": "a", "recipient_id": "b", "currency": "EUR"}`, nil, true},
+		{"invalidPubKey", BatchFormatJSONL, `{"type": "create_user", "name": "trader", "pub_key": "AAAA"}`, nil, true},
+		{"invalidEntityType", BatchFormatCSV, "type,entity_id,entity_type,parent_id\ncreate_legal_entity,gcm,bank,ch\n", nil, true},
+		{"invalidJSON", BatchFormatJSONL, `{"type": "create_account"`, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ReadBatch(strings.NewReader(tt.data), tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. ReadBatch() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ReadBatch() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadBatchReport(t *testing.T) {
+	report := `{"line":1,"client_id":"a","status":"pending"}
+{"line":2,"client_id":"b","status":"pending"}
+{"line":1,"client_id":"a","status":"ok","hash":"5F2B","height":7}
+
+{"line":2,"client_id":"b","status":"failed","error":"timeout"}
+`
+	want := map[int]BatchResult{
+		1: {Line: 1, ClientID: "a", Status: BatchStatusOK, Hash: "5F2B", Height: 7},
+		2: {Line: 2, ClientID: "b", Status: BatchStatusFailed, Error: "timeout"},
+	}
+	got, err := ReadBatchReport(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("ReadBatchReport() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadBatchReport() = %v, want %v", got, want)
+	}
+	if _, err := ReadBatchReport(strings.NewReader(`{"line":1,`)); err == nil {
+		t.Error("ReadBatchReport() of a truncated report didn't fail")
+	}
+}
+
+func TestClient_SubmitBatch_withoutSigners(t *testing.T) {
+	done := BatchResult{Line: 1, ClientID: "a", Status: BatchStatusOK, Hash: "5F2B", Height: 7}
+	txs := []BatchTx{
+		{Line: 1, Type: BatchTypeCreateAccount, AccountID: "a"},
+		{Line: 2, Type: BatchTypeCreateAccount, AccountID: "b"},
+		{Line: 3, Type: BatchTypeCreateAccount, AccountID: "c", Signer: "alice"},
+	}
+	previous := map[int]BatchResult{
+		1: done,
+		2: {Line: 2, ClientID: "b", Status: BatchStatusPending},
+	}
+	reported := []BatchResult{}
+	c := New("127.0.0.1:46657", WithChainID("chain"))
+	got := c.SubmitBatch(context.Background(), txs, BatchOptions{
+		Previous: previous,
+		Report:   func(result BatchResult) { reported = append(reported, result) },
+	})
+
+	if len(got) != len(txs) {
+		t.Fatalf("SubmitBatch() returned %d results, want %d", len(got), len(txs))
+	}
+	if !reflect.DeepEqual(got[0], done) {
+		t.Errorf("SubmitBatch() result of an executed tx = %v, want %v", got[0], done)
+	}
+	if want := (BatchResult{Line: 2, ClientID: "b", Status: BatchStatusFailed, Error: ErrNoSigner.Error()}); !reflect.DeepEqual(got[1], want) {
+		t.Errorf("SubmitBatch() result without signer = %v, want %v", got[1], want)
+	}
+	if got[2].Status != BatchStatusFailed || len(got[2].ClientID) == 0 || !strings.Contains(got[2].Error, "alice") {
+		t.Errorf("SubmitBatch() result of an unknown signer = %v", got[2])
+	}
+	if len(reported) != 2 {
+		t.Errorf("SubmitBatch() reported %v, want the results of lines 2 and 3", reported)
+	}
+}
+
+func Test_sendPipelined(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		slots   int
+		failing int // Tx failing once its nonce is valid, -1 if none
+	}{
+		{"sequential", 5, 1, -1},
+		{"pipelined", 20, 4, -1},
+		{"failedTx", 20, 4, 7},
+	}
+	for _, tt := range tests {
+		// The fake ledger executes a Tx right away if its nonce is valid
+		var mu sync.Mutex
+		var nonce uint64 = 10 // Of the signer's last Tx
+		executed := []int{}
+		nextNonce := func() (uint64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return nonce + 1, nil
+		}
+		send := func(j int, txNonce uint64) (*TxReceipt, error) {
+			time.Sleep(time.Duration(rand.Intn(1000)) * time.Microsecond)
+			mu.Lock()
+			defer mu.Unlock()
+			if txNonce != nonce+1 {
+				return nil, &Error{Code: abci.CodeType_BaseInvalidSequence}
+			}
+			if j == tt.failing {
+				return nil, &Error{Code: abci.CodeType_BaseInsufficientFunds}
+			}
+			nonce = txNonce
+			executed = append(executed, j)
+			return &TxReceipt{Height: txNonce}, nil
+		}
+		errs := make([]error, tt.n)
+		sendPipelined(context.Background(), tt.n, make(chan struct{}, tt.slots), nextNonce, send,
+			func(j int, receipt *TxReceipt, err error) { errs[j] = err })
+
+		want := []int{}
+		for j := 0; j < tt.n; j++ {
+			if j != tt.failing {
+				want = append(want, j)
+			}
+			if (errs[j] != nil) != (j == tt.failing) {
+				t.Errorf("%q. Tx %d error = %v", tt.name, j, errs[j])
+			}
+		}
+		if !reflect.DeepEqual(executed, want) {
+			t.Errorf("%q. executed Txs %v, want %v", tt.name, executed, want)
+		}
+	}
+}
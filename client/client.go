@@ -1,231 +1,505 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/satori/go.uuid"
 	abci "github.com/tendermint/abci/types"
 	"github.com/tendermint/clearchain/types"
-	//	"github.com/tendermint/go-common"
 	"github.com/tendermint/go-crypto"
 	"github.com/tendermint/go-logger"
-	//	"github.com/tendermint/go-rpc/types"
 	"github.com/tendermint/go-wire"
 	"github.com/tendermint/light-client/rpc"
-	//	abcicli "github.com/tendermint/abci/client"
-	//	"github.com/gorilla/websocket"
-	//	"github.com/tendermint/go-rpc/client"
-	//	_ "github.com/tendermint/tendermint/rpc/core/types" // Register RPCResponse > Result types
+)
+
+// DefaultMaxSendAttempts is the number of times a Tx is sent before giving up
+const DefaultMaxSendAttempts = 3
+
+// The node's websocket, and the event of its new blocks, as named by
+// Tendermint's types.EventStringNewBlock
+const (
+	websocketPath = "/websocket"
+	newBlockEvent = "NewBlock"
 )
 
 var log = logger.New("module", "client")
-var chainID string
 
-var httpClient *rpc.HTTPClient
+// Client sends Txs and queries to a ledger node. A Client is safe for
+// concurrent use: its Txs are sent one at a time, as each needs the
+// signer's next nonce, but for those of a batch, see SubmitBatch.
+type Client struct {
+	endpoint        string
+	rpc             *rpc.HTTPClient
+	chainID         string
+	signer          Signer        // optional, needed to send Txs
+	timeout         time.Duration // 0 if calls only end with their context
+	validFor        uint64        // 0 if Txs never expire
+	maxSendAttempts int
+
+	sendMu sync.Mutex
+}
+
+// Option configures a Client.
+type Option func(*Client)
 
-// SetChainID assigns and initializes the chain's ID
-func SetChainID(id string) {
-	chainID = id
+// WithChainID sets the ID of the chain Txs are signed for.
+func WithChainID(chainID string) Option {
+	return func(c *Client) { c.chainID = chainID }
 }
 
-func CreateUser(privateKey crypto.PrivKey,
-	newUsersName string,
-	newUsersPubKey crypto.PubKey,
-	newUserCanCreateLegalEntity bool) {
-	tx := &types.CreateUserTx{Address: privateKey.PubKey().Address(),
-		Name:      newUsersName,
-		PubKey:    newUsersPubKey,
-		CanCreate: newUserCanCreateLegalEntity}
+// WithSigner sets the Signer Txs are signed with.
+func WithSigner(signer Signer) Option {
+	return func(c *Client) { c.signer = signer }
+}
 
-	res := sendDeliverTxSync(privateKey, tx)
+// WithTimeout bounds the duration of every call, on top of its context.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) { c.timeout = timeout }
+}
 
-	if res.IsErr() {
-		panic(fmt.Sprintf("Wrong response from server: %v", res))
-	} else {
-		Commit()
-	}
+// WithValidFor makes Txs expire once the given number of blocks is
+// committed after they are sent, 0 means they never expire.
+func WithValidFor(blocks uint64) Option {
+	return func(c *Client) { c.validFor = blocks }
 }
 
-func CreateAccount(privateKey crypto.PrivKey,
-	accountID string) {
-	tx := &types.CreateAccountTx{Address: privateKey.PubKey().Address(),
-		AccountID: accountID}
+// WithMaxSendAttempts sets the number of times a Tx is sent before giving up.
+func WithMaxSendAttempts(attempts int) Option {
+	return func(c *Client) { c.maxSendAttempts = attempts }
+}
 
-	res := sendDeliverTxSync(privateKey, tx)
+// New creates a Client of the node listening for RPC at endpoint, e.g. 127.0.0.1:46657.
+func New(endpoint string, opts ...Option) *Client {
+	c := &Client{
+		endpoint:        endpoint,
+		rpc:             rpc.NewClient(endpoint, ""),
+		maxSendAttempts: DefaultMaxSendAttempts,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
 
-	if res.IsErr() {
-		panic(fmt.Sprintf("Wrong response from server: %v", res))
-	} else {
-		Commit()
+// withSigner returns a Client of the same node and options, but another signer.
+func (c *Client) withSigner(signer Signer) *Client {
+	return &Client{
+		endpoint:        c.endpoint,
+		rpc:             c.rpc,
+		chainID:         c.chainID,
+		signer:          signer,
+		timeout:         c.timeout,
+		validFor:        c.validFor,
+		maxSendAttempts: c.maxSendAttempts,
 	}
+}
 
-	log.Info("Created account with ID: " + accountID)
+// ChainID returns the ID of the chain Txs are signed for.
+func (c *Client) ChainID() string {
+	return c.chainID
 }
 
-func CreateLegalEntity(privateKey crypto.PrivKey,
-	entityID string, entityType byte, name string, parentID string) {
-	tx := &types.CreateLegalEntityTx{Address: privateKey.PubKey().Address(),
-		EntityID: entityID,
-		Type:     entityType,
-		Name:     name,
-		ParentID: parentID}
+// Signer returns the Signer Txs are signed with, nil if the Client can
+// only send queries.
+func (c *Client) Signer() Signer {
+	return c.signer
+}
 
-	res := sendDeliverTxSync(privateKey, tx)
+// TxReceipt describes an executed Tx.
+type TxReceipt struct {
+	ClientID string
+	Hash     []byte // Hash of the Tx's bytes, see types.TxHash
+	Height   uint64 // Height of the block the Tx was executed in
+	Data     []byte
+	Log      string
+}
 
-	if res.IsErr() {
-		panic(fmt.Sprintf("Wrong response from server: %v", res))
-	} else {
-		Commit()
-	}
-	log.Info("Created legal entity with ID: " + entityID)
+// CreateUser creates a user of the signer's legal entity.
+func (c *Client) CreateUser(ctx context.Context, name string, pubKey crypto.PubKey, canCreate bool) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.CreateUserTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			Name: name, PubKey: pubKey, CanCreate: canCreate}
+	})
 }
 
-//Creates money transfer entry in blockchain
-func TransferMoney(privateKey crypto.PrivKey, senderID string, recipientID string, counterSignerAddresses [][]byte, amount int64, currency string) {
-	senderAccount := GetAccount(senderID).Account[0]
-	newSequenceID := senderAccount.GetWallet(currency).Sequence + 1
+// CreateAccount creates an account of the signer's legal entity.
+func (c *Client) CreateAccount(ctx context.Context, accountID string) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.CreateAccountTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			AccountID: accountID}
+	})
+}
 
-	counterSigners := make([]types.TxTransferCounterSigner, len(counterSignerAddresses))
-	for i, address := range counterSignerAddresses {
-		privKey, err := crypto.PrivKeyFromBytes(address)
-		if err != nil {
-			panic(fmt.Sprintf("counterSigner signing failed with: %v", err.Error()))
-		}
-		counterSigners[i] = types.TxTransferCounterSigner{Address: privKey.PubKey().Address()}
+// CreateLegalEntity creates a legal entity below parentID.
+func (c *Client) CreateLegalEntity(ctx context.Context, entityID string, entityType byte, name string, parentID string) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.CreateLegalEntityTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			EntityID: entityID, Type: entityType, Name: name, ParentID: parentID}
+	})
+}
 
-		err = counterSigners[i].SignTx(privKey, chainID)
-		if err != nil {
-			panic(fmt.Sprintf("counterSigner signing failed with: %v", err.Error()))
-		}
+// Transfer defines a money transfer between two accounts.
+type Transfer struct {
+	SenderID       string
+	RecipientID    string
+	Amount         int64 // In the currency's minor units
+	Currency       string
+	Reference      string
+	Memo           string
+	CounterSigners [][]byte // Addresses of the users who must counter-sign the transfer
+}
 
+// TransferMoney transfers money between two accounts. The transfer is
+// counter-signed by counterSigners, whose addresses must be the transfer's
+// CounterSigners. Counter signers whose keys are held elsewhere sign a
+// transfer built by BuildTransfer instead, which is then sent by Broadcast.
+func (c *Client) TransferMoney(ctx context.Context, t Transfer, counterSigners ...Signer) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return t.tx(addr, nonce, validUntil, clientID)
+	}, counterSigners...)
+}
+
+// BuildTransfer builds an unsigned transfer committed by the user of the
+// given address, with the committer's next nonce. The committer must not
+// send other Txs until the transfer, once signed by the committer and its
+// counter signers, is broadcast.
+func (c *Client) BuildTransfer(ctx context.Context, committer []byte, t Transfer) (*types.TransferTx, error) {
+	nonce, err := c.nextNonce(ctx, committer)
+	if err != nil {
+		return nil, err
 	}
+	validUntil, err := c.validUntilHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return t.tx(committer, nonce, validUntil, uuid.NewV4().String()), nil
+}
 
-	tx := &types.TransferTx{
-		Committer: types.TxTransferCommitter{
-			Address: privateKey.PubKey().Address()},
-		Sender: types.TxTransferSender{
-			AccountID: senderID,
-			Amount:    amount,
-			Currency:  currency,
-			Sequence:  newSequenceID},
-		Recipient: types.TxTransferRecipient{
-			AccountID: recipientID},
+func (t Transfer) tx(addr []byte, nonce, validUntil uint64, clientID string) *types.TransferTx {
+	counterSigners := make([]types.TxTransferCounterSigner, len(t.CounterSigners))
+	for i, address := range t.CounterSigners {
+		counterSigners[i] = types.TxTransferCounterSigner{Address: address}
+	}
+	return &types.TransferTx{
+		Committer:      types.TxTransferCommitter{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID},
+		Sender:         types.TxTransferSender{AccountID: t.SenderID, Amount: t.Amount, Currency: t.Currency},
+		Recipient:      types.TxTransferRecipient{AccountID: t.RecipientID},
+		Reference:      t.Reference,
+		Memo:           t.Memo,
 		CounterSigners: counterSigners,
 	}
+}
 
-	res := sendDeliverTxSync(privateKey, tx)
+// IssueMoney credits an account with newly created money, only the
+// clearing house's users can issue money.
+func (c *Client) IssueMoney(ctx context.Context, accountID string, amount int64, currency string) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.IssueTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			AccountID: accountID, Amount: amount, Currency: currency}
+	})
+}
 
-	if res.IsErr() {
-		panic(fmt.Sprintf("Wrong response from server: %v", res))
-	} else {
-		Commit()
-	}
-	log.Info("Created transfer entry")
+// RedeemMoney debits an account and destroys the money, only the
+// clearing house's users can redeem money.
+func (c *Client) RedeemMoney(ctx context.Context, accountID string, amount int64, currency string) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.RedeemTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			AccountID: accountID, Amount: amount, Currency: currency}
+	})
 }
 
-// GetAccount makes a request to the ledger to return an accounts
-func GetAccount(accountRequested string) (returned types.AccountsReturned) {
-	
-	res := sendQuery("/account/" + accountRequested)
-	err := json.Unmarshal(res.Value, &returned)
-	if err != nil {
-		panic(fmt.Sprintf("JSON unmarshal for message %v failed with: %v ", returned, err))
-	}
+// AddCurrency registers a new currency or instrument, only the
+// clearing house's users can manage the currency registry.
+func (c *Client) AddCurrency(ctx context.Context, currency types.CurrencyEntry) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.AddCurrencyTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			Currency: currency}
+	})
+}
 
-	return
+// UpdateCurrency changes the name or the minimum unit of a registered currency.
+func (c *Client) UpdateCurrency(ctx context.Context, currency types.CurrencyEntry) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.UpdateCurrencyTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			Currency: currency}
+	})
 }
 
-// AccountIndex makes a request to the ledger to returns all account IDs
-func GetAllAccounts() (returned types.AccountIndex) {
-	
-	res := sendQuery("/account")
-	err := json.Unmarshal(res.Value, &returned)
-	if err != nil {
-		panic(fmt.Sprintf("JSON unmarshal for message %v failed with: %v ", res, err))
-	}
-	return
+// RetireCurrency stops a currency from being issued or transferred.
+func (c *Client) RetireCurrency(ctx context.Context, symbol string) (*TxReceipt, error) {
+	return c.send(ctx, func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx {
+		return &types.RetireCurrencyTx{Address: addr, Nonce: nonce, ValidUntilHeight: validUntil, ClientID: clientID,
+			Symbol: symbol}
+	})
 }
 
-func GetLegalEntity(id string) (returned types.LegalEntitiesReturned) {
-	
-	res := sendQuery("/legal_entity/" + id)
+//--------------------------------------------------------------------------------
 
-	err := json.Unmarshal(res.Value, &returned)
-	if err != nil {
-		panic(fmt.Sprintf("JSON unmarshal for message %v failed with: %v ", res.Value, err))
-	}
-	return
+// txBuilder builds a Tx of the signer with the given nonce, ValidUntilHeight and ClientID.
+type txBuilder func(addr []byte, nonce, validUntil uint64, clientID string) types.SignedTx
+
+// send builds, signs and broadcasts a Tx, then waits for it to be committed.
+// The Tx is signed by the Client's signer, then by counterSigners if any.
+// The Tx may have been executed even though a call failed, e.g. timed out:
+// its ClientID tells, otherwise it can safely be sent again.
+func (c *Client) send(ctx context.Context, build txBuilder, counterSigners ...Signer) (*TxReceipt, error) {
+	return c.sendWithID(ctx, uuid.NewV4().String(), build, counterSigners...)
 }
 
-func GetAllLegalEntities() (returned types.LegalEntityIndex) {
+// sendWithID is send with a given ClientID, e.g. that of a Tx whose
+// call failed: the ledger executes it at most once.
+func (c *Client) sendWithID(ctx context.Context, clientID string, build txBuilder, counterSigners ...Signer) (*TxReceipt, error) {
+	if c.signer == nil {
+		return nil, ErrNoSigner
+	}
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
 
-	res := sendQuery("/legal_entity")
-	err := json.Unmarshal(res.Value, &returned)
+	nonce, err := c.nextNonce(ctx, c.signer.Address())
 	if err != nil {
-		panic(fmt.Sprintf("JSON unmarshal for message %v failed with: %v ", res, err))
+		return nil, err
 	}
-	return
+	tx, err := c.sign(ctx, build, nonce, clientID, counterSigners...)
+	if err != nil {
+		return nil, err
+	}
+	return c.submit(ctx, tx, clientID)
 }
 
-func sendQuery(path string) abci.ResponseQuery {
-
-	resultABCI, err := httpClient.ABCIQuery(path, []byte(""), false)
+// sign builds a Tx of the Client's signer with the given nonce, then signs
+// it, and countersigns it with counterSigners if any.
+func (c *Client) sign(ctx context.Context, build txBuilder, nonce uint64, clientID string, counterSigners ...Signer) (types.SignedTx, error) {
+	validUntil, err := c.validUntilHeight(ctx)
 	if err != nil {
-		panic(err.Error())
+		return nil, err
 	}
-
-	return resultABCI.Response
+	tx := build(c.signer.Address(), nonce, validUntil, clientID)
+	for _, signer := range append([]Signer{c.signer}, counterSigners...) {
+		if err := SignTx(tx, signer, c.chainID); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
 }
 
-func sendDeliverTxSync(privateKey crypto.PrivKey, tx types.SignedTx) abci.Result {
-	txBytes, result := getTXBytes(privateKey, tx, false)
-	if result.IsErr() {
-		return result
+// Broadcast sends a Tx signed elsewhere, e.g. built by BuildTransfer and
+// signed offline, then waits for it to be committed. As with the Txs the
+// Client signs, a Tx whose call failed can safely be broadcast again. The
+// Tx must have a ClientID, which tells whether it was executed.
+func (c *Client) Broadcast(ctx context.Context, tx types.NoncedTx) (*TxReceipt, error) {
+	if len(tx.GetClientID()) == 0 {
+		return nil, &Error{Code: abci.CodeType_BaseInvalidInput, Log: "Missing client_id, the Tx couldn't be told apart from a new one"}
 	}
+	return c.submit(ctx, tx, tx.GetClientID())
+}
 
-	_, err := httpClient.BroadcastTxCommit(txBytes)
+// submit broadcasts a signed Tx until it is committed, or the attempts
+// are exhausted.
+func (c *Client) submit(ctx context.Context, tx types.Tx, clientID string) (*TxReceipt, error) {
+	txBytes := wire.BinaryBytes(struct{ types.Tx }{tx})
 
-	if err != nil {
-		panic(err.Error())
+	for attempt := 1; ; attempt++ {
+		receipt, err := c.broadcast(ctx, txBytes)
+		if err == nil {
+			receipt.ClientID = clientID
+			return receipt, nil
+		}
+		if _, ok := err.(*Error); ok || ctx.Err() != nil {
+			return nil, err
+		}
+		if result, lookupErr := c.GetTxResult(ctx, clientID); lookupErr == nil {
+			return &TxReceipt{ClientID: clientID, Hash: types.TxHash(txBytes), Height: result.Height, Data: result.Data, Log: result.Log}, nil
+		}
+		if attempt >= c.maxSendAttempts {
+			return nil, err
+		}
+		log.Warn(fmt.Sprintf("Sending tx %s failed, retrying: %v", clientID, err))
 	}
-
-	return abci.OK
 }
 
-func sendToTendermint(privateKey crypto.PrivKey, tx types.SignedTx, fn func(tx []byte) (res abci.Result), isQuery bool) abci.Result {
-	txBytes, result := getTXBytes(privateKey, tx, isQuery)
-
-	if result.IsErr() {
-		return result
+// broadcast sends a Tx's bytes and waits for the Tx to be committed. A Tx
+// rejected as a duplicate was executed before: its original result is returned.
+func (c *Client) broadcast(ctx context.Context, txBytes []byte) (*TxReceipt, error) {
+	var res *TxReceipt
+	err := c.call(ctx, func() error {
+		result, err := c.rpc.BroadcastTxCommit(txBytes)
+		if err != nil {
+			return err
+		}
+		check, deliver := result.CheckTx, result.DeliverTx
+		if check.Code == types.CodeTypeDuplicateTx || deliver.Code == types.CodeTypeDuplicateTx {
+			data := check.Data
+			if deliver.Code == types.CodeTypeDuplicateTx {
+				data = deliver.Data
+			}
+			var original types.TxResult
+			if err := json.Unmarshal(data, &original); err != nil {
+				return fmt.Errorf("Error decoding the original result of a duplicate tx: %v", err)
+			}
+			res = &TxReceipt{Hash: types.TxHash(txBytes), Height: original.Height, Data: original.Data, Log: original.Log}
+			return nil
+		}
+		if err := newError(check.Code, check.Log); err != nil {
+			return err
+		}
+		if err := newError(deliver.Code, deliver.Log); err != nil {
+			return err
+		}
+		res = &TxReceipt{Hash: types.TxHash(txBytes), Height: uint64(result.Height), Data: deliver.Data, Log: deliver.Log}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	return fn(txBytes)
+	return res, nil
 }
 
-func getTXBytes(privateKey crypto.PrivKey, tx types.SignedTx, isQuery bool) (txs []byte, result abci.Result) {
-	if err := tx.SignTx(privateKey, chainID); err != nil {
-		return nil, abci.ErrBaseInvalidSignature.AppendLog(err.Error())
+// nextNonce returns the nonce the next Tx signed by a user must have
+func (c *Client) nextNonce(ctx context.Context, addr []byte) (uint64, error) {
+	returned, err := c.GetUser(ctx, addr)
+	if err != nil {
+		return 0, err
 	}
-
-	if isQuery {
-		txs = []byte{tx.TxType()}
-		txs = append(txs, wire.BinaryBytes(struct{ types.Tx }{tx})...)
-	} else {
-		txs = wire.BinaryBytes(struct{ types.Tx }{tx})
+	if len(returned.Users) == 0 {
+		return 0, &Error{Code: abci.CodeType_BaseUnknownAddress, Log: fmt.Sprintf("Unknown user: %X", addr)}
 	}
+	return returned.Users[0].Nonce + 1, nil
+}
 
-	return txs, abci.OK
+// Height returns the height of the last block committed by the node.
+func (c *Client) Height(ctx context.Context) (uint64, error) {
+	var height uint64
+	err := c.call(ctx, func() error {
+		status, err := c.rpc.Status()
+		if err != nil {
+			return err
+		}
+		height = uint64(status.LatestBlockHeight)
+		return nil
+	})
+	return height, err
 }
 
-// StartClient is a convenience function to start the client app
-func StartClient(serverAddress string) {
-	//serverAddress := "127.0.0.1:46657"
-	httpClient = rpc.NewClient(serverAddress, "")
+// Block is a block committed by the ledger, with its Txs.
+type Block struct {
+	Height uint64
+	Txs    []types.Tx
+}
 
-	log.Info("Tendermint server connection established to " + serverAddress)
+// GetBlock returns the block committed at a height.
+func (c *Client) GetBlock(ctx context.Context, height uint64) (*Block, error) {
+	var block *Block
+	err := c.call(ctx, func() error {
+		result, err := c.rpc.Block(int(height))
+		if err != nil {
+			return err
+		}
+		b := &Block{Height: height, Txs: []types.Tx{}}
+		for _, txBytes := range result.Block.Data.Txs {
+			var tx types.Tx
+			// The ledger rejects the Txs it can't decode, which change nothing
+			if err := wire.ReadBinaryBytes(txBytes, &tx); err == nil {
+				b.Txs = append(b.Txs, tx)
+			}
+		}
+		block = b
+		return nil
+	})
+	return block, err
 }
 
-func Commit() {
+// NotifyBlocks subscribes to the node's new blocks over a websocket of
+// its own, and notifies them on the returned channel until ctx is done or
+// the websocket fails, which close it. A notification is dropped while
+// the previous one wasn't received: the receiver finds the blocks it
+// missed by their height, see Height and GetBlock.
+func (c *Client) NotifyBlocks(ctx context.Context) (<-chan struct{}, error) {
+	ws := rpc.NewClient(c.endpoint, websocketPath)
+	if err := ws.StartWebsocket(); err != nil {
+		return nil, err
+	}
+	if err := ws.Subscribe(newBlockEvent); err != nil {
+		ws.StopWebsocket()
+		return nil, err
+	}
+	results, errs := ws.GetEventChannels()
+	notify := make(chan struct{}, 1)
+	go func() {
+		defer close(notify)
+		defer ws.StopWebsocket()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-errs:
+				log.Warn(fmt.Sprintf("Subscription to new blocks failed: %v", err))
+				return
+			case <-results:
+				select {
+				case notify <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return notify, nil
+}
+
+// validUntilHeight returns the ValidUntilHeight of the next Tx, 0 if
+// Txs don't expire
+func (c *Client) validUntilHeight(ctx context.Context) (uint64, error) {
+	if c.validFor == 0 {
+		return 0, nil
+	}
+	height, err := c.Height(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return height + c.validFor, nil
 }
 
+// query sends a query and decodes its JSON response into v.
+func (c *Client) query(ctx context.Context, path string, v interface{}) (abci.ResponseQuery, error) {
+	var res abci.ResponseQuery
+	err := c.call(ctx, func() error {
+		result, err := c.rpc.ABCIQuery(path, []byte(""), false)
+		if err != nil {
+			return err
+		}
+		res = result.Response
+		return nil
+	})
+	if err != nil {
+		return abci.ResponseQuery{}, err
+	}
+	if err := newError(res.Code, res.Log); err != nil {
+		return res, err
+	}
+	if err := json.Unmarshal(res.Value, v); err != nil {
+		return res, fmt.Errorf("JSON unmarshal for message %v failed with: %v", res, err)
+	}
+	return res, nil
+}
 
+// call runs fn until it returns or the context, bounded by the Client's
+// timeout, is done. The RPC client can't be cancelled: fn then completes
+// in the background, hence what it sets must only be read if call succeeds.
+func (c *Client) call(ctx context.Context, fn func() error) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
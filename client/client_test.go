@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/types"
+)
+
+func TestClient_call(t *testing.T) {
+	errCall := errors.New("call failed")
+	block := make(chan struct{})
+	defer close(block)
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		timeout time.Duration
+		fn      func() error
+		wantErr error
+	}{
+		{"returns", context.Background(), 0, func() error { return nil }, nil},
+		{"fails", context.Background(), 0, func() error { return errCall }, errCall},
+		{"timesOut", context.Background(), time.Millisecond, func() error { <-block; return nil }, context.DeadlineExceeded},
+		{"canceled", canceled, 0, func() error { <-block; return nil }, context.Canceled},
+	}
+	for _, tt := range tests {
+		c := New("127.0.0.1:46657", WithTimeout(tt.timeout))
+		if err := c.call(tt.ctx, tt.fn); err != tt.wantErr {
+			t.Errorf("%q. Client.call() error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestClient_send_noSigner(t *testing.T) {
+	c := New("127.0.0.1:46657", WithChainID("chain"))
+	if _, err := c.CreateAccount(context.Background(), "account"); err != ErrNoSigner {
+		t.Errorf("Client.CreateAccount() error = %v, want %v", err, ErrNoSigner)
+	}
+}
+
+func TestClient_Broadcast_noClientID(t *testing.T) {
+	c := New("127.0.0.1:46657", WithChainID("chain"))
+	_, err := c.Broadcast(context.Background(), &types.IssueTx{Address: []byte("address"), Nonce: 1})
+	if !IsInvalidInput(err) {
+		t.Errorf("Client.Broadcast() error = %v, want an invalid input error", err)
+	}
+}
+
+func TestErrorPredicates(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		wantNotFound     bool
+		wantInvalidInput bool
+		wantUnauthorized bool
+		wantInvalidNonce bool
+	}{
+		{"ok", newError(abci.CodeType_OK, ""), false, false, false, false},
+		{"notLedgerError", errors.New("unknown address"), false, false, false, false},
+		{"notFound", newError(abci.CodeType_BaseUnknownAddress, "Unknown tx"), true, false, false, false},
+		{"invalidInput", newError(abci.CodeType_BaseInvalidInput, "Invalid amount"), false, true, false, false},
+		{"unauthorized", newError(abci.CodeType_Unauthorized, ""), false, false, true, false},
+		{"invalidSignature", newError(abci.CodeType_BaseInvalidSignature, ""), false, false, true, false},
+		{"invalidNonce", newError(abci.CodeType_BaseInvalidSequence, ""), false, false, false, true},
+		{"duplicateTx", newError(types.CodeTypeDuplicateTx, ""), false, false, false, false},
+	}
+	for _, tt := range tests {
+		if got := IsNotFound(tt.err); got != tt.wantNotFound {
+			t.Errorf("%q. IsNotFound() = %v, want %v", tt.name, got, tt.wantNotFound)
+		}
+		if got := IsInvalidInput(tt.err); got != tt.wantInvalidInput {
+			t.Errorf("%q. IsInvalidInput() = %v, want %v", tt.name, got, tt.wantInvalidInput)
+		}
+		if got := IsUnauthorized(tt.err); got != tt.wantUnauthorized {
+			t.Errorf("%q. IsUnauthorized() = %v, want %v", tt.name, got, tt.wantUnauthorized)
+		}
+		if got := IsInvalidNonce(tt.err); got != tt.wantInvalidNonce {
+			t.Errorf("%q. IsInvalidNonce() = %v, want %v", tt.name, got, tt.wantInvalidNonce)
+		}
+	}
+}
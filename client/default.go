@@ -0,0 +1,309 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// The package-level functions below send Txs and queries with a default
+// Client, configured by SetChainID, SetValidFor and StartClient, and panic
+// on failure. They serve command line tools; services should create their
+// own Client with New.
+
+var (
+	defaultEndpoint string
+	chainID         string
+	validFor        uint64
+)
+
+// SetChainID assigns and initializes the chain's ID
+func SetChainID(id string) {
+	chainID = id
+}
+
+// SetValidFor makes the Txs sent afterwards expire once the given
+// number of blocks is committed, 0 means they never expire
+func SetValidFor(blocks uint64) {
+	validFor = blocks
+}
+
+// StartClient is a convenience function to start the client app
+func StartClient(serverAddress string) {
+	//serverAddress := "127.0.0.1:46657"
+	defaultEndpoint = serverAddress
+
+	log.Info("Tendermint server connection established to " + serverAddress)
+}
+
+func Commit() {
+}
+
+// defaultClient returns a Client of the default endpoint and chain.
+func defaultClient(opts ...Option) *Client {
+	return New(defaultEndpoint, append([]Option{WithChainID(chainID), WithValidFor(validFor)}, opts...)...)
+}
+
+func mustSend(_ *TxReceipt, err error) {
+	if err != nil {
+		panic(fmt.Sprintf("Wrong response from server: %v", err))
+	}
+	Commit()
+}
+
+func mustQuery(err error) {
+	if err != nil {
+		panic(fmt.Sprintf("Wrong response from server: %v", err))
+	}
+}
+
+func CreateUser(signer Signer,
+	newUsersName string,
+	newUsersPubKey crypto.PubKey,
+	newUserCanCreateLegalEntity bool) {
+	mustSend(defaultClient(WithSigner(signer)).CreateUser(context.Background(),
+		newUsersName, newUsersPubKey, newUserCanCreateLegalEntity))
+}
+
+func CreateAccount(signer Signer,
+	accountID string) {
+	mustSend(defaultClient(WithSigner(signer)).CreateAccount(context.Background(), accountID))
+
+	log.Info("Created account with ID: " + accountID)
+}
+
+func CreateLegalEntity(signer Signer,
+	entityID string, entityType byte, name string, parentID string) {
+	mustSend(defaultClient(WithSigner(signer)).CreateLegalEntity(context.Background(),
+		entityID, entityType, name, parentID))
+	log.Info("Created legal entity with ID: " + entityID)
+}
+
+// TransferMoney creates a money transfer entry in the blockchain,
+// counter-signed by counterSigners
+func TransferMoney(signer Signer, senderID string, recipientID string, counterSigners []Signer, amount int64, currency string, reference string, memo string) {
+	counterSignerAddresses := make([][]byte, len(counterSigners))
+	for i, counterSigner := range counterSigners {
+		counterSignerAddresses[i] = counterSigner.Address()
+	}
+
+	mustSend(defaultClient(WithSigner(signer)).TransferMoney(context.Background(), Transfer{
+		SenderID:       senderID,
+		RecipientID:    recipientID,
+		Amount:         amount,
+		Currency:       currency,
+		Reference:      reference,
+		Memo:           memo,
+		CounterSigners: counterSignerAddresses,
+	}, counterSigners...))
+	log.Info("Created transfer entry")
+}
+
+// BuildTransfer returns an unsigned money transfer committed by the user of
+// the given address, to be signed by its committer and counter signers
+func BuildTransfer(committer []byte, t Transfer) *types.TransferTx {
+	tx, err := defaultClient().BuildTransfer(context.Background(), committer, t)
+	mustQuery(err)
+	return tx
+}
+
+// Broadcast sends a Tx signed by all its signers, e.g. a transfer built
+// by BuildTransfer
+func Broadcast(tx types.NoncedTx) {
+	mustSend(defaultClient().Broadcast(context.Background(), tx))
+	log.Info("Broadcast tx with client ID: " + tx.GetClientID())
+}
+
+// SubmitBatch sends a batch of Txs, signed by signer unless they name one
+// of opts.Signers, and returns their results; it doesn't panic if some fail
+func SubmitBatch(signer Signer, txs []BatchTx, opts BatchOptions) []BatchResult {
+	return defaultClient(WithSigner(signer)).SubmitBatch(context.Background(), txs, opts)
+}
+
+// IssueMoney credits an account with newly created money, only the
+// clearing house's users can issue money
+func IssueMoney(signer Signer, accountID string, amount int64, currency string) {
+	mustSend(defaultClient(WithSigner(signer)).IssueMoney(context.Background(), accountID, amount, currency))
+	log.Info(fmt.Sprintf("Issued %d %s to account with ID: %s", amount, currency, accountID))
+}
+
+// RedeemMoney debits an account and destroys the money, only the
+// clearing house's users can redeem money
+func RedeemMoney(signer Signer, accountID string, amount int64, currency string) {
+	mustSend(defaultClient(WithSigner(signer)).RedeemMoney(context.Background(), accountID, amount, currency))
+	log.Info(fmt.Sprintf("Redeemed %d %s from account with ID: %s", amount, currency, accountID))
+}
+
+// AddCurrency registers a new currency or instrument, only the
+// clearing house's users can manage the currency registry
+func AddCurrency(signer Signer, currency types.CurrencyEntry) {
+	mustSend(defaultClient(WithSigner(signer)).AddCurrency(context.Background(), currency))
+	log.Info("Added currency: " + currency.Symbol)
+}
+
+// UpdateCurrency changes the name or the minimum unit of a registered currency
+func UpdateCurrency(signer Signer, currency types.CurrencyEntry) {
+	mustSend(defaultClient(WithSigner(signer)).UpdateCurrency(context.Background(), currency))
+	log.Info("Updated currency: " + currency.Symbol)
+}
+
+// RetireCurrency stops a currency from being issued or transferred
+func RetireCurrency(signer Signer, symbol string) {
+	mustSend(defaultClient(WithSigner(signer)).RetireCurrency(context.Background(), symbol))
+	log.Info("Retired currency: " + symbol)
+}
+
+// GetAccount makes a request to the ledger to return an accounts
+func GetAccount(accountRequested string) types.AccountsReturned {
+	returned, err := defaultClient().GetAccount(context.Background(), accountRequested)
+	mustQuery(err)
+	return returned
+}
+
+// GetAccountAtHeight makes a request to the ledger to return an account
+// as it was at the given block height
+func GetAccountAtHeight(accountRequested string, height uint64) types.AccountsReturned {
+	returned, err := defaultClient().GetAccountAtHeight(context.Background(), accountRequested, height)
+	mustQuery(err)
+	return returned
+}
+
+// GetAllAccounts makes requests to the ledger to return all account IDs
+func GetAllAccounts() types.AccountIndex {
+	returned, err := defaultClient().GetAllAccounts(context.Background())
+	mustQuery(err)
+	return returned
+}
+
+// GetLegalEntityAccounts makes requests to the ledger to return
+// the IDs of all accounts owned by a legal entity
+func GetLegalEntityAccounts(id string) types.AccountIndex {
+	returned, err := defaultClient().GetLegalEntityAccounts(context.Background(), id)
+	mustQuery(err)
+	return returned
+}
+
+func GetLegalEntity(id string) types.LegalEntitiesReturned {
+	returned, err := defaultClient().GetLegalEntity(context.Background(), id)
+	mustQuery(err)
+	return returned
+}
+
+// GetAllLegalEntities makes requests to the ledger to return all legal entity IDs
+func GetAllLegalEntities() types.LegalEntityIndex {
+	returned, err := defaultClient().GetAllLegalEntities(context.Background())
+	mustQuery(err)
+	return returned
+}
+
+// GetLegalEntityBalances makes a request to the ledger to return the aggregated
+// balances of a legal entity, optionally rolled up across its descendants
+func GetLegalEntityBalances(id string, rollup bool) types.BalanceReport {
+	returned, err := defaultClient().GetLegalEntityBalances(context.Background(), id, rollup)
+	mustQuery(err)
+	return returned
+}
+
+// GetSupply makes a request to the ledger to return the total supply of
+// every currency alongside the sum of all wallet balances
+func GetSupply() types.SupplyReport {
+	returned, err := defaultClient().GetSupply(context.Background())
+	mustQuery(err)
+	return returned
+}
+
+// GetCurrency makes a request to the ledger to return a currency of the registry
+func GetCurrency(symbol string) *types.CurrencyEntry {
+	returned, err := defaultClient().GetCurrency(context.Background(), symbol)
+	mustQuery(err)
+	return returned
+}
+
+// ParseAmount converts a decimal amount such as 100.00 to the currency's
+// minor units, as registered on the ledger
+func ParseAmount(amount string, currency string) int64 {
+	a, err := defaultClient().ParseAmount(context.Background(), amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// ListCurrencies makes a request to the ledger to return a page of the
+// currency registry matching the filters in params (retired, cursor, limit).
+func ListCurrencies(params url.Values) types.CurrenciesReturned {
+	returned, err := defaultClient().ListCurrencies(context.Background(), params)
+	mustQuery(err)
+	return returned
+}
+
+// ListAccounts makes a request to the ledger to return a page of accounts
+// matching the filters in params (entity_id, currency, non_zero, cursor, limit).
+func ListAccounts(params url.Values) types.AccountsReturned {
+	returned, err := defaultClient().ListAccounts(context.Background(), params)
+	mustQuery(err)
+	return returned
+}
+
+// ListLegalEntities makes a request to the ledger to return a page of legal
+// entities matching the filters in params (type, parent_id, cursor, limit).
+func ListLegalEntities(params url.Values) types.LegalEntitiesReturned {
+	returned, err := defaultClient().ListLegalEntities(context.Background(), params)
+	mustQuery(err)
+	return returned
+}
+
+// ListUsers makes a request to the ledger to return a page of users
+// matching the filters in params (entity_id, cursor, limit).
+func ListUsers(params url.Values) types.UsersReturned {
+	returned, err := defaultClient().ListUsers(context.Background(), params)
+	mustQuery(err)
+	return returned
+}
+
+// GetTransfer makes a request to the ledger to return a transfer of its history
+func GetTransfer(id string) types.TransfersReturned {
+	returned, err := defaultClient().GetTransfer(context.Background(), id)
+	mustQuery(err)
+	return returned
+}
+
+// ListTransfers makes a request to the ledger to return a page of the transfer
+// history matching the filters in params (reference, cursor, limit).
+func ListTransfers(params url.Values) types.TransfersReturned {
+	returned, err := defaultClient().ListTransfers(context.Background(), params)
+	mustQuery(err)
+	return returned
+}
+
+// GetUser makes a request to the ledger to return a user by address
+func GetUser(addr []byte) types.UsersReturned {
+	returned, err := defaultClient().GetUser(context.Background(), addr)
+	mustQuery(err)
+	return returned
+}
+
+// GetTxResult makes a request to the ledger to return the result of the Tx
+// carrying a ClientID, nil if the Tx was not executed (yet)
+func GetTxResult(clientID string) *types.TxResult {
+	returned, err := defaultClient().GetTxResult(context.Background(), clientID)
+	if IsNotFound(err) {
+		return nil
+	}
+	mustQuery(err)
+	return returned
+}
+
+// GetTx makes a request to the ledger to return the record of the Tx
+// with the given hash, nil if no such Tx was delivered (yet)
+func GetTx(hash []byte) *types.TxRecord {
+	returned, err := defaultClient().GetTx(context.Background(), hash)
+	if IsNotFound(err) {
+		return nil
+	}
+	mustQuery(err)
+	return returned
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	abci "github.com/tendermint/abci/types"
+)
+
+// ErrNoSigner is returned when sending a Tx with a Client that has no Signer.
+var ErrNoSigner = errors.New("client has no signer to sign transactions with")
+
+// Error is returned when the ledger rejects a Tx or a query, with the
+// ABCI result code and log it answered with.
+type Error struct {
+	Code abci.CodeType
+	Log  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("ledger error %v: %s", e.Code, e.Log)
+}
+
+// newError maps a result code other than OK to an *Error.
+func newError(code abci.CodeType, log string) error {
+	if code == abci.CodeType_OK {
+		return nil
+	}
+	return &Error{Code: code, Log: log}
+}
+
+// IsNotFound tells whether the error is the ledger reporting an unknown object.
+func IsNotFound(err error) bool {
+	return hasCode(err, abci.CodeType_BaseUnknownAddress)
+}
+
+// IsInvalidInput tells whether the ledger rejected the request's content.
+func IsInvalidInput(err error) bool {
+	return hasCode(err, abci.CodeType_BaseInvalidInput)
+}
+
+// IsUnauthorized tells whether the ledger rejected a Tx its signer
+// is not allowed to send, or whose signature is invalid.
+func IsUnauthorized(err error) bool {
+	return hasCode(err, abci.CodeType_Unauthorized) || hasCode(err, abci.CodeType_BaseInvalidSignature)
+}
+
+// IsInvalidNonce tells whether the ledger rejected a Tx whose nonce is not
+// the signer's next one, e.g. because another Tx of the signer went first.
+func IsInvalidNonce(err error) bool {
+	return hasCode(err, abci.CodeType_BaseInvalidSequence)
+}
+
+func hasCode(err error, code abci.CodeType) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == code
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/tendermint/go-crypto"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Parameters of the scrypt key derivation of new key files
+const (
+	keyFileScryptN = 1 << 15
+	keyFileScryptR = 8
+	keyFileScryptP = 1
+)
+
+// ErrWrongPassphrase is returned when decrypting a key with the wrong passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// keyFile is the JSON format of an encrypted private key. The key is sealed
+// with NaCl's secretbox, with a secret derived from a passphrase by scrypt.
+// The public key is stored in the clear so that the key can be identified
+// without the passphrase.
+type keyFile struct {
+	PubKey     []byte `json:"pub_key"`
+	Salt       []byte `json:"salt"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// EncryptKey encrypts a private key with a passphrase, in the format
+// of a key file.
+func EncryptKey(privKey crypto.PrivKey, passphrase []byte) ([]byte, error) {
+	f := keyFile{
+		PubKey: privKey.PubKey().Bytes(),
+		Salt:   crypto.CRandBytes(32),
+		N:      keyFileScryptN,
+		R:      keyFileScryptR,
+		P:      keyFileScryptP,
+		Nonce:  crypto.CRandBytes(24),
+	}
+	secret, err := f.secret(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], f.Nonce)
+	f.Ciphertext = secretbox.Seal(nil, privKey.Bytes(), &nonce, secret)
+	return json.MarshalIndent(f, "", "  ")
+}
+
+// DecryptKey decrypts a private key encrypted by EncryptKey.
+func DecryptKey(data []byte, passphrase []byte) (crypto.PrivKey, error) {
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("Invalid key file: %v", err)
+	}
+	if len(f.Nonce) != 24 {
+		return nil, errors.New("Invalid key file: invalid nonce")
+	}
+	secret, err := f.secret(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var nonce [24]byte
+	copy(nonce[:], f.Nonce)
+	plaintext, ok := secretbox.Open(nil, f.Ciphertext, &nonce, secret)
+	if !ok {
+		return nil, ErrWrongPassphrase
+	}
+	privKey, err := crypto.PrivKeyFromBytes(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid key file: %v", err)
+	}
+	if !bytes.Equal(privKey.PubKey().Bytes(), f.PubKey) {
+		return nil, errors.New("Invalid key file: the private key doesn't match the public key")
+	}
+	return privKey, nil
+}
+
+// KeyFilePubKey returns the public key of an encrypted key, which doesn't
+// need the passphrase.
+func KeyFilePubKey(data []byte) (crypto.PubKey, error) {
+	var f keyFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("Invalid key file: %v", err)
+	}
+	return crypto.PubKeyFromBytes(f.PubKey)
+}
+
+// WriteKeyFile encrypts a private key with a passphrase and writes it to
+// a new file, only readable by its owner.
+func WriteKeyFile(path string, privKey crypto.PrivKey, passphrase []byte) error {
+	data, err := EncryptKey(privKey, passphrase)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// NewKeyFileSigner creates a Signer of the private key of a key file,
+// decrypted with a passphrase.
+func NewKeyFileSigner(path string, passphrase []byte) (*KeySigner, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	privKey, err := DecryptKey(data, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(privKey), nil
+}
+
+// secret derives the secretbox key from a passphrase.
+func (f keyFile) secret(passphrase []byte) (*[32]byte, error) {
+	key, err := scrypt.Key(passphrase, f.Salt, f.N, f.R, f.P, 32)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid key file: %v", err)
+	}
+	var secret [32]byte
+	copy(secret[:], key)
+	return &secret, nil
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tendermint/go-crypto"
+)
+
+// keyFileExt is the extension of the key files of a Keystore
+const keyFileExt = ".key"
+
+var keyNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9_.-]{0,63}$`)
+
+// ErrKeyNotFound is returned when a Keystore has no key of the given name.
+var ErrKeyNotFound = errors.New("key not found")
+
+// Keystore is a directory of passphrase-encrypted key files, see
+// EncryptKey, which are referenced by name.
+type Keystore struct {
+	dir string
+}
+
+// KeyInfo describes a key of a Keystore, which doesn't need its passphrase.
+type KeyInfo struct {
+	Name   string
+	PubKey crypto.PubKey
+}
+
+// Address returns the address of the key's public key.
+func (k KeyInfo) Address() []byte {
+	return k.PubKey.Address()
+}
+
+// DefaultKeystoreDir returns the directory of the keystore of the user
+// running the process.
+func DefaultKeystoreDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".ledgerctl", "keys")
+}
+
+// OpenKeystore opens the keystore of a directory, which is created if
+// needed, only accessible by its owner.
+func OpenKeystore(dir string) (*Keystore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &Keystore{dir: dir}, nil
+}
+
+// ValidateKeyName checks that a key name is made of at most 64 letters,
+// digits, '_', '-' and '.', and doesn't start with '.'.
+func ValidateKeyName(name string) error {
+	if !keyNameRegexp.MatchString(name) {
+		return fmt.Errorf("Invalid key name %q: use at most 64 letters, digits, '_', '-' and '.'", name)
+	}
+	return nil
+}
+
+// Add encrypts a private key with a passphrase and stores it under name,
+// which must not be used yet.
+func (k *Keystore) Add(name string, privKey crypto.PrivKey, passphrase []byte) error {
+	data, err := EncryptKey(privKey, passphrase)
+	if err != nil {
+		return err
+	}
+	return k.Import(name, data)
+}
+
+// Import stores a key already encrypted by EncryptKey under name, which
+// must not be used yet.
+func (k *Keystore) Import(name string, data []byte) error {
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	if _, err := KeyFilePubKey(data); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(k.path(name), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return fmt.Errorf("Key %q already exists", name)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Export returns the encrypted key stored under name.
+func (k *Keystore) Export(name string) ([]byte, error) {
+	if err := ValidateKeyName(name); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(k.path(name))
+	if os.IsNotExist(err) {
+		return nil, ErrKeyNotFound
+	}
+	return data, err
+}
+
+// Get describes the key stored under name.
+func (k *Keystore) Get(name string) (KeyInfo, error) {
+	data, err := k.Export(name)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	pubKey, err := KeyFilePubKey(data)
+	if err != nil {
+		return KeyInfo{}, fmt.Errorf("Key %q: %v", name, err)
+	}
+	return KeyInfo{Name: name, PubKey: pubKey}, nil
+}
+
+// List describes all the keys, sorted by name.
+func (k *Keystore) List() ([]KeyInfo, error) {
+	files, err := ioutil.ReadDir(k.dir)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, f := range files {
+		name := strings.TrimSuffix(f.Name(), keyFileExt)
+		if f.Mode().IsRegular() && strings.HasSuffix(f.Name(), keyFileExt) && ValidateKeyName(name) == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	keys := make([]KeyInfo, len(names))
+	for i, name := range names {
+		if keys[i], err = k.Get(name); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// Delete removes the key stored under name.
+func (k *Keystore) Delete(name string) error {
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	err := os.Remove(k.path(name))
+	if os.IsNotExist(err) {
+		return ErrKeyNotFound
+	}
+	return err
+}
+
+// PrivKey decrypts the key stored under name with its passphrase.
+func (k *Keystore) PrivKey(name string, passphrase []byte) (crypto.PrivKey, error) {
+	data, err := k.Export(name)
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(data, passphrase)
+}
+
+// Signer returns a Signer of the key stored under name, decrypted with
+// its passphrase.
+func (k *Keystore) Signer(name string, passphrase []byte) (*KeySigner, error) {
+	privKey, err := k.PrivKey(name, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeySigner(privKey), nil
+}
+
+func (k *Keystore) path(name string) string {
+	return filepath.Join(k.dir, name+keyFileExt)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/tendermint/go-crypto"
+)
+
+func TestKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ks, err := OpenKeystore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := crypto.GenPrivKeyEd25519()
+	bob := crypto.GenPrivKeyEd25519()
+	if err := ks.Add("bob", bob, []byte("bob's passphrase")); err != nil {
+		t.Fatalf("Keystore.Add() error = %v", err)
+	}
+	if err := ks.Add("alice", alice, []byte("alice's passphrase")); err != nil {
+		t.Fatalf("Keystore.Add() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		keyName string
+		wantErr bool
+	}{
+		{"new", "carol", false},
+		{"existing", "alice", true},
+		{"hidden", ".carol", true},
+		{"path", "../carol", true},
+		{"empty", "", true},
+	}
+	for _, tt := range tests {
+		if err := ks.Add(tt.keyName, crypto.GenPrivKeyEd25519(), []byte("passphrase")); (err != nil) != tt.wantErr {
+			t.Errorf("%q. Keystore.Add() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	keys, err := ks.List()
+	if err != nil || len(keys) != 3 || keys[0].Name != "alice" || keys[1].Name != "bob" || keys[2].Name != "carol" {
+		t.Fatalf("Keystore.List() = %v, %v, want alice, bob and carol", keys, err)
+	}
+	if !reflect.DeepEqual(keys[0].Address(), alice.PubKey().Address()) {
+		t.Errorf("Keystore.List()[0].Address() = %X, want %X", keys[0].Address(), alice.PubKey().Address())
+	}
+	if _, err := ks.Signer("alice", []byte("bob's passphrase")); err != ErrWrongPassphrase {
+		t.Errorf("Keystore.Signer() error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	if signer, err := ks.Signer("alice", []byte("alice's passphrase")); err != nil || !reflect.DeepEqual(signer.PubKey(), alice.PubKey()) {
+		t.Errorf("Keystore.Signer() = %v, %v, want alice's signer", signer, err)
+	}
+
+	// An exported key can be imported with its passphrase
+	data, err := ks.Export("bob")
+	if err != nil {
+		t.Fatalf("Keystore.Export() error = %v", err)
+	}
+	if err := ks.Delete("bob"); err != nil {
+		t.Fatalf("Keystore.Delete() error = %v", err)
+	}
+	if _, err := ks.Get("bob"); err != ErrKeyNotFound {
+		t.Errorf("Keystore.Get() error = %v, want %v", err, ErrKeyNotFound)
+	}
+	if err := ks.Import("robert", data); err != nil {
+		t.Fatalf("Keystore.Import() error = %v", err)
+	}
+	if privKey, err := ks.PrivKey("robert", []byte("bob's passphrase")); err != nil || !reflect.DeepEqual(privKey, bob) {
+		t.Errorf("Keystore.PrivKey() = %v, %v, want %v", privKey, err, bob)
+	}
+	if err := ks.Import("invalid", []byte("{}")); err == nil {
+		t.Errorf("Keystore.Import() of an invalid key succeeded")
+	}
+}
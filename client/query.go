@@ -0,0 +1,242 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-wire"
+)
+
+// GetAccount returns an account, with its balances as decimal strings.
+func (c *Client) GetAccount(ctx context.Context, id string) (returned types.AccountsReturned, err error) {
+	_, err = c.query(ctx, "/account/"+id+"?decimal=true", &returned)
+	return
+}
+
+// GetAccountAtHeight returns an account as it was at the given block height.
+func (c *Client) GetAccountAtHeight(ctx context.Context, id string, height uint64) (returned types.AccountsReturned, err error) {
+	_, err = c.query(ctx, fmt.Sprintf("/account/%s?decimal=true&height=%d", id, height), &returned)
+	return
+}
+
+// GetAllAccounts returns the IDs of all accounts.
+func (c *Client) GetAllAccounts(ctx context.Context) (types.AccountIndex, error) {
+	return c.getAccountIndex(ctx, "/account")
+}
+
+// GetLegalEntityAccounts returns the IDs of all accounts owned by a legal entity.
+func (c *Client) GetLegalEntityAccounts(ctx context.Context, id string) (types.AccountIndex, error) {
+	return c.getAccountIndex(ctx, "/legal_entity/"+id+"/accounts")
+}
+
+// GetLegalEntity returns a legal entity.
+func (c *Client) GetLegalEntity(ctx context.Context, id string) (returned types.LegalEntitiesReturned, err error) {
+	_, err = c.query(ctx, "/legal_entity/"+id, &returned)
+	return
+}
+
+// GetAllLegalEntities returns the IDs of all legal entities.
+func (c *Client) GetAllLegalEntities(ctx context.Context) (returned types.LegalEntityIndex, err error) {
+	returned.Ids = []string{}
+	for cursor := ""; ; {
+		var page types.LegalEntityIndex
+		if _, err = c.query(ctx, pagePath("/legal_entity", cursor), &page); err != nil {
+			return
+		}
+		returned.Ids = append(returned.Ids, page.Ids...)
+		if cursor = page.Next; len(cursor) == 0 {
+			return
+		}
+	}
+}
+
+// GetLegalEntityBalances returns the aggregated balances of a legal entity,
+// optionally rolled up across its descendants.
+func (c *Client) GetLegalEntityBalances(ctx context.Context, id string, rollup bool) (returned types.BalanceReport, err error) {
+	params := url.Values{"decimal": {"true"}}
+	if rollup {
+		params.Set("rollup", "true")
+	}
+	_, err = c.query(ctx, listPath("/legal_entity/"+id+"/balances", params), &returned)
+	return
+}
+
+// GetSupply returns the total supply of every currency alongside the sum
+// of all wallet balances.
+func (c *Client) GetSupply(ctx context.Context) (returned types.SupplyReport, err error) {
+	_, err = c.query(ctx, "/supply?decimal=true", &returned)
+	return
+}
+
+// ExportGenesis exports the whole state as a genesis document, as of the
+// given height or the latest one if 0. The state is exported page by
+// page, all of them at the height of the first one, hence the ledger
+// must keep the history of its state unless the export fits in a page.
+func (c *Client) ExportGenesis(ctx context.Context, height uint64) (returned types.GenesisDoc, err error) {
+	for cursor := ""; ; {
+		params := url.Values{}
+		if height != 0 {
+			params.Set("height", fmt.Sprint(height))
+		}
+		if len(cursor) > 0 {
+			params.Set("cursor", cursor)
+		}
+		var page types.GenesisPage
+		res, err := c.query(ctx, listPath("/export", params), &page)
+		if err != nil {
+			return returned, err
+		}
+		if len(cursor) == 0 {
+			returned = *page.Genesis
+			height = res.Height
+		} else {
+			returned.Append(page.Genesis)
+		}
+		if cursor = page.Next; len(cursor) == 0 {
+			returned.Height = height
+			return returned, nil
+		}
+		if height == 0 {
+			return returned, errors.New("The ledger keeps no history, its state can't be exported page by page at a single height")
+		}
+	}
+}
+
+// GetCurrency returns a currency of the registry.
+func (c *Client) GetCurrency(ctx context.Context, symbol string) (*types.CurrencyEntry, error) {
+	var returned types.CurrenciesReturned
+	if _, err := c.query(ctx, "/currency/"+symbol, &returned); err != nil {
+		return nil, err
+	}
+	if len(returned.Currencies) == 0 {
+		return nil, &Error{Code: abci.CodeType_BaseUnknownAddress, Log: fmt.Sprintf("Unknown currency: %q", symbol)}
+	}
+	return returned.Currencies[0], nil
+}
+
+// ParseAmount converts a decimal amount such as 100.00 to the currency's
+// minor units, as registered on the ledger.
+func (c *Client) ParseAmount(ctx context.Context, amount string, currency string) (int64, error) {
+	entry, err := c.GetCurrency(ctx, currency)
+	if err != nil {
+		return 0, err
+	}
+	a, err := entry.ParseAmount(amount)
+	return int64(a), err
+}
+
+// ListCurrencies returns a page of the currency registry matching the
+// filters in params (retired, cursor, limit).
+func (c *Client) ListCurrencies(ctx context.Context, params url.Values) (returned types.CurrenciesReturned, err error) {
+	_, err = c.query(ctx, listPath("/currencies", params), &returned)
+	return
+}
+
+// ListAccounts returns a page of accounts matching the filters in params
+// (entity_id, currency, non_zero, cursor, limit).
+func (c *Client) ListAccounts(ctx context.Context, params url.Values) (returned types.AccountsReturned, err error) {
+	_, err = c.query(ctx, listPath("/accounts", params), &returned)
+	return
+}
+
+// ListLegalEntities returns a page of legal entities matching the filters
+// in params (type, parent_id, cursor, limit).
+func (c *Client) ListLegalEntities(ctx context.Context, params url.Values) (returned types.LegalEntitiesReturned, err error) {
+	_, err = c.query(ctx, listPath("/legal_entities", params), &returned)
+	return
+}
+
+// ListUsers returns a page of users matching the filters in params
+// (entity_id, cursor, limit).
+func (c *Client) ListUsers(ctx context.Context, params url.Values) (returned types.UsersReturned, err error) {
+	err = c.queryWire(ctx, listPath("/users", params), &returned)
+	return
+}
+
+// GetUser returns a user by address.
+func (c *Client) GetUser(ctx context.Context, addr []byte) (returned types.UsersReturned, err error) {
+	err = c.queryWire(ctx, fmt.Sprintf("/user/%X", addr), &returned)
+	return
+}
+
+// GetTransfer returns a transfer of the history.
+func (c *Client) GetTransfer(ctx context.Context, id string) (returned types.TransfersReturned, err error) {
+	_, err = c.query(ctx, "/transfer/"+id, &returned)
+	return
+}
+
+// ListTransfers returns a page of the transfer history matching the
+// filters in params (reference, cursor, limit).
+func (c *Client) ListTransfers(ctx context.Context, params url.Values) (returned types.TransfersReturned, err error) {
+	_, err = c.query(ctx, listPath("/transfers", params), &returned)
+	return
+}
+
+// GetTxResult returns the result of the executed Tx carrying a ClientID.
+// IsNotFound(err) if the Tx was not executed, or not yet.
+func (c *Client) GetTxResult(ctx context.Context, clientID string) (*types.TxResult, error) {
+	var returned types.TxResult
+	if _, err := c.query(ctx, "/tx/"+clientID, &returned); err != nil {
+		return nil, err
+	}
+	return &returned, nil
+}
+
+// GetTx returns the record of the Tx with the given hash.
+// IsNotFound(err) if no such Tx was delivered, or not yet.
+func (c *Client) GetTx(ctx context.Context, hash []byte) (*types.TxRecord, error) {
+	var returned types.TxRecord
+	if _, err := c.query(ctx, fmt.Sprintf("/tx/%X", hash), &returned); err != nil {
+		return nil, err
+	}
+	return &returned, nil
+}
+
+// queryWire sends a query whose response is encoded with go-wire's JSON
+// codec, as users' public keys need to be.
+func (c *Client) queryWire(ctx context.Context, path string, v interface{}) error {
+	var raw json.RawMessage
+	res, err := c.query(ctx, path, &raw)
+	if err != nil {
+		return err
+	}
+	wire.ReadJSONPtr(v, raw, &err)
+	if err != nil {
+		return fmt.Errorf("JSON unmarshal for message %v failed with: %v", res, err)
+	}
+	return nil
+}
+
+// getAccountIndex follows the pages of an accounts list query.
+func (c *Client) getAccountIndex(ctx context.Context, path string) (returned types.AccountIndex, err error) {
+	returned.Accounts = []string{}
+	for cursor := ""; ; {
+		var page types.AccountIndex
+		if _, err = c.query(ctx, pagePath(path, cursor), &page); err != nil {
+			return
+		}
+		returned.Accounts = append(returned.Accounts, page.Accounts...)
+		if cursor = page.Next; len(cursor) == 0 {
+			return
+		}
+	}
+}
+
+func listPath(path string, params url.Values) string {
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
+}
+
+func pagePath(path string, cursor string) string {
+	if len(cursor) == 0 {
+		return path
+	}
+	return path + "?cursor=" + url.QueryEscape(cursor)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/tendermint/go-crypto"
+)
+
+// RemoteSignerTimeout bounds the duration of a request to a remote signer,
+// which may wait for an operator or a hardware device to approve it.
+const RemoteSignerTimeout = time.Minute
+
+// The remote signer protocol: a client connects to the signer's Unix
+// socket, writes a signerRequest as a line of JSON and reads the signer's
+// signerResponse, as a line of JSON too, then closes the connection.
+
+const (
+	signerMethodPubKey = "pub_key"
+	signerMethodSign   = "sign"
+)
+
+type signerRequest struct {
+	Method string `json:"method"`
+	Msg    []byte `json:"msg,omitempty"` // Bytes to sign
+}
+
+type signerResponse struct {
+	PubKey    []byte `json:"pub_key,omitempty"`   // Binary encoded crypto.PubKey
+	Signature []byte `json:"signature,omitempty"` // Binary encoded crypto.Signature
+	Error     string `json:"error,omitempty"`
+}
+
+// RemoteSigner signs with a key held by another process, e.g. a signing
+// daemon or a hardware wallet bridge, listening on a local Unix socket.
+type RemoteSigner struct {
+	socketPath string
+	pubKey     crypto.PubKey
+}
+
+// NewRemoteSigner connects to the signer listening on a Unix socket and
+// fetches its public key.
+func NewRemoteSigner(socketPath string) (*RemoteSigner, error) {
+	s := &RemoteSigner{socketPath: socketPath}
+	res, err := s.call(signerRequest{Method: signerMethodPubKey})
+	if err != nil {
+		return nil, err
+	}
+	if s.pubKey, err = crypto.PubKeyFromBytes(res.PubKey); err != nil {
+		return nil, fmt.Errorf("Remote signer returned an invalid public key: %v", err)
+	}
+	return s, nil
+}
+
+// Address returns the address of the signer's public key.
+func (s *RemoteSigner) Address() []byte {
+	return s.pubKey.Address()
+}
+
+// PubKey returns the signer's public key.
+func (s *RemoteSigner) PubKey() crypto.PubKey {
+	return s.pubKey
+}
+
+// Sign asks the remote signer to sign msg.
+func (s *RemoteSigner) Sign(msg []byte) (crypto.Signature, error) {
+	res, err := s.call(signerRequest{Method: signerMethodSign, Msg: msg})
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.SignatureFromBytes(res.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("Remote signer returned an invalid signature: %v", err)
+	}
+	return sig, nil
+}
+
+func (s *RemoteSigner) call(req signerRequest) (*signerResponse, error) {
+	conn, err := net.DialTimeout("unix", s.socketPath, RemoteSignerTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(RemoteSignerTimeout)); err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+	var res signerResponse
+	if err := json.NewDecoder(conn).Decode(&res); err != nil {
+		return nil, fmt.Errorf("Error reading the remote signer's response: %v", err)
+	}
+	if len(res.Error) != 0 {
+		return nil, fmt.Errorf("Remote signer error: %s", res.Error)
+	}
+	return &res, nil
+}
+
+// ServeSigner serves the remote signer protocol with signer to the
+// connections accepted by l, typically a Unix socket listener, until
+// l is closed.
+func ServeSigner(l net.Listener, signer Signer) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go serveSignerConn(conn, signer)
+	}
+}
+
+func serveSignerConn(conn net.Conn, signer Signer) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(RemoteSignerTimeout))
+	var req signerRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		log.Warn(fmt.Sprintf("Invalid remote signer request: %v", err))
+		return
+	}
+	var res signerResponse
+	switch req.Method {
+	case signerMethodPubKey:
+		res.PubKey = signer.PubKey().Bytes()
+	case signerMethodSign:
+		if sig, err := signer.Sign(req.Msg); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.Signature = sig.Bytes()
+		}
+	default:
+		res.Error = "unknown method: " + req.Method
+	}
+	if err := json.NewEncoder(conn).Encode(res); err != nil {
+		log.Warn(fmt.Sprintf("Error writing remote signer response: %v", err))
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// Signer signs Txs on behalf of a user, without necessarily holding the
+// user's private key: see KeySigner, NewKeyFileSigner and RemoteSigner.
+type Signer interface {
+	Address() []byte
+	PubKey() crypto.PubKey
+	Sign(msg []byte) (crypto.Signature, error)
+}
+
+// KeySigner signs with a private key held in memory.
+type KeySigner struct {
+	privKey crypto.PrivKey
+}
+
+// NewKeySigner creates a Signer of a private key held in memory.
+func NewKeySigner(privKey crypto.PrivKey) *KeySigner {
+	return &KeySigner{privKey: privKey}
+}
+
+// Address returns the address of the signer's public key.
+func (s *KeySigner) Address() []byte {
+	return s.privKey.PubKey().Address()
+}
+
+// PubKey returns the signer's public key.
+func (s *KeySigner) PubKey() crypto.PubKey {
+	return s.privKey.PubKey()
+}
+
+// Sign signs msg with the private key.
+func (s *KeySigner) Sign(msg []byte) (crypto.Signature, error) {
+	return s.privKey.Sign(msg), nil
+}
+
+// SignTx signs a Tx for the given chain with signer. The signature is
+// verified, as a Signer such as a RemoteSigner may not be trusted.
+func SignTx(tx types.SignedTx, signer Signer, chainID string) error {
+	sig, err := sign(signer, tx.SignBytes(chainID))
+	if err != nil {
+		return err
+	}
+	if !tx.SetSignature(signer.Address(), sig) {
+		return fmt.Errorf("SignTx: the signer %X is not the Tx's signer", signer.Address())
+	}
+	return nil
+}
+
+// sign signs msg with signer and verifies the signature.
+func sign(signer Signer, msg []byte) (crypto.Signature, error) {
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		return nil, fmt.Errorf("Signing failed: %v", err)
+	}
+	if !signer.PubKey().VerifyBytes(msg, sig) {
+		return nil, fmt.Errorf("Signing failed: invalid signature of %X", signer.Address())
+	}
+	return sig, nil
+}
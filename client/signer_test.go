@@ -0,0 +1,112 @@
+package client
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+func TestSignTx(t *testing.T) {
+	privKey := crypto.GenPrivKeyEd25519()
+	other := crypto.GenPrivKeyEd25519()
+	tests := []struct {
+		name    string
+		signer  Signer
+		wantErr bool
+	}{
+		{"keySigner", NewKeySigner(privKey), false},
+		{"anotherSigner", NewKeySigner(other), true},
+		{"invalidSignature", badSigner{NewKeySigner(privKey), other}, true},
+	}
+	for _, tt := range tests {
+		tx := &types.IssueTx{Address: privKey.PubKey().Address(), Nonce: 1}
+		err := SignTx(tx, tt.signer, "chain")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. SignTx() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if want := privKey.Sign(tx.SignBytes("chain")); !tt.wantErr && !reflect.DeepEqual(tx.Signature, want) {
+			t.Errorf("%q. SignTx() signature = %v, want %v", tt.name, tx.Signature, want)
+		}
+	}
+}
+
+func TestKeyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	privKey := crypto.GenPrivKeyEd25519()
+	path := filepath.Join(dir, "key")
+	if err := WriteKeyFile(path, privKey, []byte("passphrase")); err != nil {
+		t.Fatalf("WriteKeyFile() error = %v", err)
+	}
+	if err := WriteKeyFile(path, privKey, []byte("passphrase")); err == nil {
+		t.Errorf("WriteKeyFile() overwrote an existing file")
+	}
+	if _, err := NewKeyFileSigner(path, []byte("wrong")); err != ErrWrongPassphrase {
+		t.Errorf("NewKeyFileSigner() error = %v, want %v", err, ErrWrongPassphrase)
+	}
+	signer, err := NewKeyFileSigner(path, []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("NewKeyFileSigner() error = %v", err)
+	}
+	if !reflect.DeepEqual(signer.PubKey(), privKey.PubKey()) {
+		t.Errorf("NewKeyFileSigner().PubKey() = %v, want %v", signer.PubKey(), privKey.PubKey())
+	}
+	data, _ := ioutil.ReadFile(path)
+	if pubKey, err := KeyFilePubKey(data); err != nil || !reflect.DeepEqual(pubKey, privKey.PubKey()) {
+		t.Errorf("KeyFilePubKey() = %v, %v, want %v", pubKey, err, privKey.PubKey())
+	}
+}
+
+func TestRemoteSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "signer.sock")
+	l, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	privKey := crypto.GenPrivKeyEd25519()
+	go ServeSigner(l, NewKeySigner(privKey))
+
+	signer, err := NewRemoteSigner(socketPath)
+	if err != nil {
+		t.Fatalf("NewRemoteSigner() error = %v", err)
+	}
+	if !reflect.DeepEqual(signer.Address(), privKey.PubKey().Address()) {
+		t.Errorf("RemoteSigner.Address() = %X, want %X", signer.Address(), privKey.PubKey().Address())
+	}
+	tx := &types.IssueTx{Address: privKey.PubKey().Address(), Nonce: 1}
+	if err := SignTx(tx, signer, "chain"); err != nil {
+		t.Fatalf("SignTx() error = %v", err)
+	}
+	if want := privKey.Sign(tx.SignBytes("chain")); !reflect.DeepEqual(tx.Signature, want) {
+		t.Errorf("SignTx() signature = %v, want %v", tx.Signature, want)
+	}
+	if _, err := NewRemoteSigner(filepath.Join(dir, "none.sock")); err == nil {
+		t.Errorf("NewRemoteSigner() of a missing socket succeeded")
+	}
+}
+
+// badSigner signs with another key than its public key's
+type badSigner struct {
+	Signer
+	privKey crypto.PrivKey
+}
+
+func (s badSigner) Sign(msg []byte) (crypto.Signature, error) {
+	return s.privKey.Sign(msg), nil
+}
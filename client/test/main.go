@@ -20,20 +20,21 @@ func main() {
 	//ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s=
 
 	privateKey, _ := crypto.PrivKeyFromBytes(privateKeyBytes) //User{b40cbf4e-5923-4ccd-beec-e22a9117b91b "Name" 31}
+	signer := client.NewKeySigner(privateKey)
 
 	userName := "userName"
 	canCreate := true
 	pubKey := crypto.GenPrivKeyEd25519().PubKey()
-	client.CreateUser(privateKey, userName, pubKey, canCreate)
+	client.CreateUser(signer, userName, pubKey, canCreate)
 
 	accountID := uuid.NewV4().String()
-	client.CreateAccount(privateKey, accountID)
+	client.CreateAccount(signer, accountID)
 
 	entityID := uuid.NewV4().String()
 	parentID := uuid.NewV4().String()
 	entityType := types.EntityTypeCHByte
 	legalEntityName := "newLegalEntityName"
-	client.CreateLegalEntity(privateKey, entityID, entityType, legalEntityName, parentID)
+	client.CreateLegalEntity(signer, entityID, entityType, legalEntityName, parentID)
 
 	fmt.Println("Account IDs:")
 	var accountsRequested []string = client.GetAllAccounts().Accounts
@@ -67,10 +68,9 @@ func main() {
 	//privateKey: ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s=
 	senderID := "1d2df1ae-accb-11e6-bbbb-00ff5244ae7f"
 	recipientID := "6b6d3a08-5527-4955-b4fd-f5ba7e083548"
-	counterSignerAddresses := [][]byte{}
 	amount := 10000
 	currency := "EUR"
-	client.TransferMoney(privateKey, senderID, recipientID, counterSignerAddresses, int64(amount), currency)
+	client.TransferMoney(signer, senderID, recipientID, nil, int64(amount), currency, "", "")
 }
 
 //
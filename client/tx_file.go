@@ -0,0 +1,43 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-wire"
+)
+
+// txFile is the format of the files Txs are passed around in to be signed
+// offline: the Tx, in go-wire's JSON encoding, with the chain it's signed for.
+type txFile struct {
+	ChainID string   `json:"chain_id"`
+	Tx      types.Tx `json:"tx"`
+}
+
+// EncodeTxFile encodes a Tx and the ID of the chain it's signed for, so
+// that each of its signers can review and sign it in turn.
+func EncodeTxFile(chainID string, tx types.Tx) ([]byte, error) {
+	var out bytes.Buffer
+	if err := json.Indent(&out, wire.JSONBytes(txFile{ChainID: chainID, Tx: tx}), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// DecodeTxFile decodes a Tx encoded by EncodeTxFile, with the ID of the
+// chain it's signed for.
+func DecodeTxFile(data []byte) (string, types.Tx, error) {
+	var f txFile
+	var err error
+	wire.ReadJSONPtr(&f, data, &err)
+	if err != nil {
+		return "", nil, err
+	}
+	if f.Tx == nil {
+		return "", nil, errors.New("Invalid tx file: no tx")
+	}
+	return f.ChainID, f.Tx, nil
+}
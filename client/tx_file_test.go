@@ -0,0 +1,55 @@
+package client
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+func TestTxFile(t *testing.T) {
+	committer := crypto.GenPrivKeyEd25519()
+	counterSigner := crypto.GenPrivKeyEd25519()
+	tx := Transfer{
+		SenderID:       "sender",
+		RecipientID:    "recipient",
+		Amount:         100,
+		Currency:       "EUR",
+		CounterSigners: [][]byte{counterSigner.PubKey().Address()},
+	}.tx(committer.PubKey().Address(), 1, 0, "client-id")
+	if err := SignTx(tx, NewKeySigner(committer), "chain"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := EncodeTxFile("chain", tx)
+	if err != nil {
+		t.Fatalf("EncodeTxFile() error = %v", err)
+	}
+	chainID, decoded, err := DecodeTxFile(data)
+	if err != nil {
+		t.Fatalf("DecodeTxFile() error = %v", err)
+	}
+	if chainID != "chain" {
+		t.Errorf("DecodeTxFile() chainID = %q, want %q", chainID, "chain")
+	}
+	if !reflect.DeepEqual(decoded, types.Tx(tx)) {
+		t.Fatalf("DecodeTxFile() tx = %v, want %v", decoded, tx)
+	}
+
+	// The counter signer signs the decoded transfer, as it was signed by the committer
+	transferTx := decoded.(*types.TransferTx)
+	if err := SignTx(transferTx, NewKeySigner(counterSigner), chainID); err != nil {
+		t.Fatal(err)
+	}
+	if missing := transferTx.MissingSignatures(); len(missing) != 0 {
+		t.Errorf("MissingSignatures() = %X, want none", missing)
+	}
+	if !committer.PubKey().VerifyBytes(transferTx.SignBytes(chainID), transferTx.Committer.Signature) {
+		t.Error("The committer's signature doesn't verify the decoded transfer")
+	}
+
+	if _, _, err := DecodeTxFile([]byte(`{"chain_id":"chain"}`)); err == nil {
+		t.Error("DecodeTxFile() of a file without tx didn't fail")
+	}
+}
@@ -1,20 +1,27 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
-	"reflect"
 	"path"
 	"os"
 	
 	"github.com/tendermint/abci/server"
 	"github.com/tendermint/clearchain/app"
+	"github.com/tendermint/clearchain/state"
+	"github.com/tendermint/clearchain/types"
 	common "github.com/tendermint/go-common"
+	dbm "github.com/tendermint/go-db"
 	eyes "github.com/tendermint/merkleeyes/client"
 )
 
-const EyesCacheSize = 10000
+const (
+	EyesCacheSize = 10000
+
+	// DefaultHistoryRetention is the default number of
+	// heights kept for historical queries.
+	DefaultHistoryRetention = 100000
+)
 
 func main() {
 
@@ -22,6 +29,9 @@ func main() {
 	addrPtr := flag.String("address", "tcp://0.0.0.0:46658", "Listen address")
 	eyesPtr := flag.String("eyes", "local", "MerkleEyes address, or 'local' for embedded")
 	genFilePath := flag.String("genesis", "", "Genesis file, if any")
+	historyPtr := flag.String("history", "local", "Historical state database directory, 'local' for embedded or 'none' to disable historical queries")
+	retentionPtr := flag.Uint64("history-retention", DefaultHistoryRetention, "Number of heights historical queries can go back, 0 to keep them all")
+	invariantsPtr := flag.Uint64("invariant-check-interval", app.DefaultInvariantCheckInterval, "Number of blocks between two checks of the conservation of money, 0 to disable the checks")
 	flag.Parse()
 
 	// Connect to MerkleEyes	
@@ -42,15 +52,25 @@ func main() {
 
 	// Create Clearing app
 	app := app.NewLedger(eyesCli)
+	app.SetInvariantCheckInterval(*invariantsPtr)
 
-	// If genesis file was specified, set key-value options
+	// Record the state's history for queries at past heights
+	if *historyPtr != "none" {
+		historyDir := *historyPtr
+		if historyDir == "local" {
+			historyDir = path.Join(ClearchainRoot(""), "dataTmp")
+		}
+		fmt.Println(common.Fmt("starting history database. Path: %v, retention: %v", historyDir, *retentionPtr))
+		historyDB := dbm.NewDB("history", "leveldb", historyDir)
+		if err := app.SetHistory(state.NewHistory(historyDB, *retentionPtr)); err != nil {
+			common.Exit("start history: " + err.Error())
+		}
+	}
+
+	// If genesis file was specified, initialize the chain with it
 	fmt.Println("genesis filePath: " +  *genFilePath)
 	if *genFilePath != "" {
-		kvz := loadGenesis(*genFilePath)
-		for _, kv := range kvz {
-			log := app.SetOption(kv.Key, kv.Value)
-			fmt.Println(common.Fmt("Log: %v Set %v=%v. ", log, kv.Key, kv.Value))
-		}
+		app.SetGenesis(loadGenesis(*genFilePath))
 	}
 
 	// Start the listener
@@ -69,47 +89,16 @@ func main() {
 
 //----------------------------------------
 
-// KeyValue defines the attributes of a configuration variable
-type KeyValue struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
-}
-
-func loadGenesis(filePath string) (kvz []KeyValue) {
-	kvzFromFile := []interface{}{}
+func loadGenesis(filePath string) *types.GenesisDoc {
 	bytes, err := common.ReadFile(filePath)
 	if err != nil {
 		common.Exit("loading genesis file: " + err.Error())
 	}
-	err = json.Unmarshal(bytes, &kvzFromFile)
+	doc, err := types.GenesisDocFromJSON(bytes)
 	if err != nil {
 		common.Exit("parsing genesis file: " + err.Error())
 	}
-	if len(kvzFromFile)%2 != 0 {
-		common.Exit("genesis cannot have an odd number of items.  Format = [key1, value1, key2, value2, ...]")
-	}
-	for i := 0; i < len(kvzFromFile); i += 2 {
-		keyIfc := kvzFromFile[i]
-		valueIfc := kvzFromFile[i+1]
-		var key, value string
-		key, ok := keyIfc.(string)
-		if !ok {
-			common.Exit(common.Fmt(
-				"genesis had invalid key %v of type %v", keyIfc, reflect.TypeOf(keyIfc)))
-		}
-		if v, ok := valueIfc.(string); ok {
-			value = v
-		} else {
-			valueBytes, err := json.Marshal(valueIfc)
-			if err != nil {
-				common.Exit(common.Fmt(
-					"genesis had invalid value %v: %v", v, err.Error()))
-			}
-			value = string(valueBytes)
-		}
-		kvz = append(kvz, KeyValue{key, value})
-	}
-	return kvz
+	return doc
 }
 
 func ClearchainRoot(rootDir string) string {
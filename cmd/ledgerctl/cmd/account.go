@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"text/tabwriter"
+
+	"github.com/satori/go.uuid"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+)
+
+var (
+	flagAccountCurrency string
+	flagNonZero         bool
+)
+
+func init() {
+	accountCreateCmd.Flags().StringVar(&flagAccountID, "account-id", "", "ID of the new account, a new UUID if empty")
+	accountShowCmd.Flags().StringVar(&flagAccountID, "account-id", "", "ID of the account")
+	accountShowCmd.Flags().Uint64Var(&flagHeight, "height", 0, "Show the account as it was at the given block height")
+	accountListCmd.Flags().StringVar(&flagEntityID, "entity-id", "", "Only list the accounts of this legal entity")
+	accountListCmd.Flags().StringVar(&flagAccountCurrency, "currency", "", "Only list the accounts holding a wallet of this currency")
+	accountListCmd.Flags().BoolVar(&flagNonZero, "non-zero", false, "Only list the accounts with a non-zero balance")
+	for _, cmd := range []*cobra.Command{accountIssueCmd, accountRedeemCmd} {
+		cmd.Flags().StringVar(&flagAccountID, "account-id", "", "ID of the account")
+		cmd.Flags().StringVar(&flagAmount, "amount", "", "Amount of money, e.g. 100.00")
+		cmd.Flags().StringVar(&flagCurrency, "currency", "", "Currency of the amount, e.g. EUR")
+	}
+	accountCmd.AddCommand(accountCreateCmd, accountShowCmd, accountListCmd, accountIssueCmd, accountRedeemCmd)
+	RootCmd.AddCommand(accountCmd)
+}
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Create, query, credit or debit the ledger's accounts",
+}
+
+var accountCreateCmd = &cobra.Command{
+	Use:   "create [chainID serverAddress privateKey accountID]",
+	Short: "Create an account owned by the signer's legal entity",
+	Run: func(cmd *cobra.Command, args []string) {
+		chainID, serverAddress, privateKeyParam, accountID := flagChainID, flagNode, flagKey, flagAccountID
+
+		if len(args) == 4 {
+			//ledgerctl account create test_chain_id 127.0.0.1:46657 alice 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f
+			chainID, serverAddress, privateKeyParam, accountID = args[0], args[1], args[2], args[3]
+		}
+		if len(accountID) == 0 {
+			accountID = uuid.NewV4().String()
+		}
+		signer := mustConnectSigner(chainID, serverAddress, privateKeyParam)
+
+		client.CreateAccount(signer, accountID)
+		writeAccount(client.GetAccount(accountID))
+	},
+}
+
+var accountShowCmd = &cobra.Command{
+	Use:   "show [serverAddress accountID]",
+	Short: "Show an account and the balances of its wallets",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress, accountID := flagNode, flagAccountID
+
+		if len(args) == 2 {
+			//ledgerctl account show 127.0.0.1:46657 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f --height 42
+			serverAddress, accountID = args[0], args[1]
+		}
+		mustConnect(serverAddress)
+		accountID = param(accountID, "account-id", "accountID")
+
+		if flagHeight != 0 {
+			writeAccount(client.GetAccountAtHeight(accountID, flagHeight))
+		} else {
+			writeAccount(client.GetAccount(accountID))
+		}
+	},
+}
+
+var accountListCmd = &cobra.Command{
+	Use:   "list [serverAddress]",
+	Short: "List the accounts and the balances of their wallets",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress := flagNode
+		if len(args) == 1 {
+			//ledgerctl account list 127.0.0.1:46657 --entity-id b40cbf4e-5923-4ccd-beec-e22a9117b91b --non-zero
+			serverAddress = args[0]
+		}
+		mustConnect(serverAddress)
+
+		params := url.Values{"decimal": {"true"}}
+		if len(flagEntityID) > 0 {
+			params.Set("entity_id", flagEntityID)
+		}
+		if len(flagAccountCurrency) > 0 {
+			params.Set("currency", flagAccountCurrency)
+		}
+		if flagNonZero {
+			params.Set("non_zero", "true")
+		}
+		accounts := types.AccountsReturned{Account: []*types.Account{}, Balances: map[string][]types.Balance{}}
+		for {
+			page := client.ListAccounts(params)
+			accounts.Account = append(accounts.Account, page.Account...)
+			for id, balances := range page.Balances {
+				accounts.Balances[id] = balances
+			}
+			if len(page.Next) == 0 {
+				break
+			}
+			params.Set("cursor", page.Next)
+		}
+		writeAccounts(accounts)
+	},
+}
+
+var accountIssueCmd = &cobra.Command{
+	Use:   "issue [chainID serverAddress privateKey accountID amount currency]",
+	Short: "Credit an account with newly created money, only by the clearing house",
+	Run: func(cmd *cobra.Command, args []string) {
+		signer, accountID, amount, currency := readMoneyParameters(args)
+		client.IssueMoney(signer, accountID, amount, currency)
+		writeAccount(client.GetAccount(accountID))
+	},
+}
+
+var accountRedeemCmd = &cobra.Command{
+	Use:   "redeem [chainID serverAddress privateKey accountID amount currency]",
+	Short: "Debit an account and destroy the money, only by the clearing house",
+	Run: func(cmd *cobra.Command, args []string) {
+		signer, accountID, amount, currency := readMoneyParameters(args)
+		client.RedeemMoney(signer, accountID, amount, currency)
+		writeAccount(client.GetAccount(accountID))
+	},
+}
+
+// readMoneyParameters connects to the ledger and returns the signer and
+// the amount of money to issue or redeem, given either as flags, arguments
+// or on stdin.
+func readMoneyParameters(args []string) (client.Signer, string, int64, string) {
+	chainID, serverAddress, privateKeyParam := flagChainID, flagNode, flagKey
+	accountID, amountParam, currency := flagAccountID, flagAmount, flagCurrency
+
+	if len(args) == 6 {
+		//ledgerctl account issue test_chain_id 127.0.0.1:46657 admin 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f 100.00 EUR
+		chainID, serverAddress, privateKeyParam = args[0], args[1], args[2]
+		accountID, amountParam, currency = args[3], args[4], args[5]
+	}
+	signer := mustConnectSigner(chainID, serverAddress, privateKeyParam)
+	accountID = param(accountID, "account-id", "accountID")
+	amountParam = param(amountParam, "amount", "amount (e.g. 100.00)")
+	currency = param(currency, "currency", "currency")
+
+	return signer, accountID, client.ParseAmount(amountParam, currency), currency
+}
+
+// mustConnect connects to the ledger for queries.
+func mustConnect(serverAddress string) {
+	client.StartClient(param(serverAddress, "node", "serverAddress"))
+}
+
+// mustConnectSigner connects to the ledger and returns the Signer of the
+// transactions sent to it.
+func mustConnectSigner(chainID string, serverAddress string, privateKeyParam string) client.Signer {
+	chainID = param(chainID, "chain-id", "chainID")
+	serverAddress = param(serverAddress, "node", "serverAddress")
+	signer := mustSigner(param(privateKeyParam, "key", "privateKey"))
+
+	client.SetChainID(chainID)
+	client.StartClient(serverAddress)
+	return signer
+}
+
+// accountOutput describes an account with the balances of its wallets.
+type accountOutput struct {
+	ID       string          `json:"id"`
+	EntityID string          `json:"entity_id"`
+	Balances []types.Balance `json:"balances"`
+}
+
+// newAccountOutputs returns the accounts queried with their decimal balances.
+func newAccountOutputs(returned types.AccountsReturned) []accountOutput {
+	outputs := make([]accountOutput, len(returned.Account))
+	for i, acc := range returned.Account {
+		outputs[i] = accountOutput{ID: acc.ID, EntityID: acc.EntityID, Balances: returned.Balances[acc.ID]}
+		if outputs[i].Balances == nil {
+			outputs[i].Balances = []types.Balance{}
+		}
+	}
+	return outputs
+}
+
+// writeAccount prints the account queried by client.GetAccount.
+func writeAccount(returned types.AccountsReturned) {
+	outputs := newAccountOutputs(returned)
+	if len(outputs) != 1 {
+		log.Fatalf("Expected one account, got %d", len(outputs))
+	}
+	writeOutput(outputs[0], func(w io.Writer) error {
+		return writeAccountsTable(w, outputs)
+	})
+}
+
+// writeAccounts prints a list of accounts.
+func writeAccounts(returned types.AccountsReturned) {
+	outputs := newAccountOutputs(returned)
+	writeOutput(outputs, func(w io.Writer) error {
+		return writeAccountsTable(w, outputs)
+	})
+}
+
+// writeAccountsTable writes a row per wallet, or a single one for an
+// account without wallets.
+func writeAccountsTable(w io.Writer, accounts []accountOutput) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tENTITY\tCURRENCY\tBALANCE")
+	for _, acc := range accounts {
+		if len(acc.Balances) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t\t\n", acc.ID, acc.EntityID)
+		}
+		for _, b := range acc.Balances {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", acc.ID, acc.EntityID, b.Currency, displayAmount(b))
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+)
+
+var (
+	flagBatchFormat      string
+	flagBatchConcurrency int
+	flagBatchReport      string
+	flagBatchResume      bool
+)
+
+func init() {
+	batchCmd.Flags().StringVar(&flagBatchFormat, "format", "", "Format of the batch file, jsonl or csv, guessed from its extension if empty")
+	batchCmd.Flags().IntVar(&flagBatchConcurrency, "concurrency", client.DefaultBatchConcurrency,
+		"Number of transactions sent at once, those of a signer being executed in order")
+	batchCmd.Flags().StringVar(&flagBatchReport, "report", "", "File the result of each line is appended to, <file>.report.jsonl if empty")
+	batchCmd.Flags().BoolVar(&flagBatchResume, "resume", false, "Resume the batch from its report, skipping the transactions already executed")
+	RootCmd.AddCommand(batchCmd)
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [chainID serverAddress] file",
+	Short: "Send the transactions of a JSONL or CSV file, with a report of the result of each line",
+	Long: `Send the transactions of a JSONL or CSV file, with a report of the result of each line.
+
+Each line specifies a transaction by its type (create_user, create_account,
+create_legal_entity, issue, redeem or transfer) and fields: signer,
+client_id, account_id, name, pub_key, can_create, entity_id, entity_type,
+parent_id, sender_id, recipient_id, amount, currency, reference and memo.
+A CSV file starts with a header line naming its fields. The signer field
+holds a privateKey, typically the name of a key of the keystore; the
+transactions without one are signed by the --key.
+
+The result of each line is appended to the report as a line of JSON. If
+some transactions fail, the batch can be fixed and run again with --resume:
+the transactions already executed are skipped, and the others are sent
+with the same client ID, which prevents them from being executed twice.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var chainID, serverAddress, path string
+
+		switch len(args) {
+		case 3:
+			//ledgerctl batch test_chain_id 127.0.0.1:46657 onboarding.csv --key admin
+			chainID, serverAddress, path = args[0], args[1], args[2]
+		case 1:
+			chainID, serverAddress, path = param(flagChainID, "chain-id", "chainID"), param(flagNode, "node", "serverAddress"), args[0]
+		default:
+			log.Fatal("Usage: ledgerctl batch [chainID serverAddress] file")
+		}
+
+		format := flagBatchFormat
+		if len(format) == 0 {
+			format = client.BatchFormatJSONL
+			if strings.EqualFold(filepath.Ext(path), ".csv") {
+				format = client.BatchFormatCSV
+			}
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		txs, err := client.ReadBatch(f, format)
+		f.Close()
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+
+		reportPath := flagBatchReport
+		if len(reportPath) == 0 {
+			reportPath = path + ".report.jsonl"
+		}
+		previous := mustReadBatchReport(reportPath)
+
+		var signer client.Signer
+		if len(flagKey) > 0 {
+			signer = mustSigner(flagKey)
+		}
+		signers := map[string]client.Signer{}
+		for _, tx := range txs {
+			if _, ok := signers[tx.Signer]; len(tx.Signer) > 0 && !ok {
+				signers[tx.Signer] = mustSigner(tx.Signer)
+			}
+		}
+
+		report, err := os.OpenFile(reportPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer report.Close()
+		encoder := json.NewEncoder(report)
+
+		client.SetChainID(chainID)
+		client.StartClient(serverAddress)
+		results := client.SubmitBatch(signer, txs, client.BatchOptions{
+			Signers:     signers,
+			Concurrency: flagBatchConcurrency,
+			Previous:    previous,
+			Report: func(result client.BatchResult) {
+				if err := encoder.Encode(result); err != nil {
+					log.Fatalf("Error writing the report: %v", err)
+				}
+			},
+		})
+
+		var executed, skipped, failed int
+		for _, result := range results {
+			switch {
+			case result.Status != client.BatchStatusOK:
+				failed++
+			case previous[result.Line].Status == client.BatchStatusOK:
+				skipped++
+			default:
+				executed++
+			}
+		}
+		writeOutput(results, func(w io.Writer) error {
+			for _, result := range results {
+				if result.Status != client.BatchStatusOK {
+					fmt.Fprintf(w, "Line %d failed: %s\n", result.Line, result.Error)
+				}
+			}
+			_, err := fmt.Fprintf(w, "%d executed, %d already executed, %d failed, see %s\n", executed, skipped, failed, reportPath)
+			return err
+		})
+		if failed > 0 {
+			report.Close()
+			os.Exit(1)
+		}
+	},
+}
+
+// mustReadBatchReport returns the results of the earlier run of a batch if
+// it's resumed, and otherwise checks that the batch didn't run yet.
+func mustReadBatchReport(path string) map[int]client.BatchResult {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		if flagBatchResume {
+			log.Fatalf("Can't resume the batch: no report %s", path)
+		}
+		return nil
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if !flagBatchResume {
+		log.Fatalf("The report %s exists: resume the batch with --resume, or remove the report", path)
+	}
+	previous, err := client.ReadBatchReport(f)
+	if err != nil {
+		log.Fatalf("Error reading %s: %v", path, err)
+	}
+	return previous
+}
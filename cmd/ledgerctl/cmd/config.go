@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// EnvPrefix prefixes the environment variables overriding the global
+// flags, e.g. LEDGERCTL_CHAIN_ID for --chain-id
+const EnvPrefix = "LEDGERCTL_"
+
+// Formats of the --output flag
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// configFile is the format of the config file, see configHelpCmd. Its
+// settings are named after the global flags, and a profile's settings
+// override the top-level ones.
+type configFile struct {
+	Profile  string                       `yaml:"profile"` // Default profile
+	Profiles map[string]map[string]string `yaml:"profiles"`
+	Settings map[string]string            `yaml:",inline"`
+}
+
+// defaultConfigPath returns the config file of the user running ledgerctl.
+func defaultConfigPath() string {
+	return filepath.Join(os.Getenv("HOME"), ".ledgerctl.yaml")
+}
+
+// loadSettings sets the global flags which weren't given on the command
+// line from their environment variables, or else from the config file's
+// profile, or else from the config file's top level.
+func loadSettings(flags *pflag.FlagSet) error {
+	path, explicit := flagConfig, flags.Lookup("config").Changed
+	if env, ok := os.LookupEnv(envName("config")); ok && !explicit {
+		path, explicit = env, true
+	}
+	cfg, err := readConfigFile(path, explicit)
+	if err != nil {
+		return err
+	}
+	for name := range cfg.Settings {
+		if flags.Lookup(name) == nil {
+			return fmt.Errorf("Unknown setting %q in %s", name, path)
+		}
+	}
+	for profile, settings := range cfg.Profiles {
+		for name := range settings {
+			if flags.Lookup(name) == nil {
+				return fmt.Errorf("Unknown setting %q of profile %q in %s", name, profile, path)
+			}
+		}
+	}
+
+	profile := flagProfile
+	if len(profile) == 0 {
+		profile = os.Getenv(envName("profile"))
+	}
+	if len(profile) == 0 {
+		profile = cfg.Profile
+	}
+	var profileSettings map[string]string
+	if len(profile) > 0 {
+		var ok bool
+		if profileSettings, ok = cfg.Profiles[profile]; !ok {
+			return fmt.Errorf("Unknown profile %q in %s", profile, path)
+		}
+	}
+
+	flags.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || f.Name == "config" || f.Name == "profile" {
+			return
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			value, ok = profileSettings[f.Name]
+		}
+		if !ok {
+			value, ok = cfg.Settings[f.Name]
+		}
+		if ok {
+			if setErr := flags.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("Invalid %s %q: %v", f.Name, value, setErr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if flagOutput != "" && flagOutput != outputJSON && flagOutput != outputTable {
+		return fmt.Errorf("Unknown output format %q, use %s or %s", flagOutput, outputJSON, outputTable)
+	}
+	return nil
+}
+
+// readConfigFile reads the config file, which only has to exist if it
+// was given explicitly.
+func readConfigFile(path string, explicit bool) (configFile, error) {
+	var cfg configFile
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("Error reading %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// envName returns the environment variable overriding a global flag.
+func envName(flag string) string {
+	return EnvPrefix + strings.ToUpper(strings.Replace(flag, "-", "_", -1))
+}
+
+// param returns the value of a parameter given by a flag, or else prompts
+// for it unless --no-prompt is set.
+func param(value string, flag string, prompt string) string {
+	if len(value) > 0 {
+		return value
+	}
+	if flagNoPrompt {
+		log.Fatalf("Missing parameter, set --%s", flag)
+	}
+	return readParameter(prompt)
+}
+
+// writeOutput prints v as JSON with --output json, and otherwise as a
+// table written by table.
+func writeOutput(v interface{}, table func(w io.Writer) error) {
+	var err error
+	if flagOutput == outputJSON {
+		var data []byte
+		if data, err = json.MarshalIndent(v, "", "  "); err == nil {
+			_, err = fmt.Printf("%s\n", data)
+		}
+	} else {
+		err = table(os.Stdout)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
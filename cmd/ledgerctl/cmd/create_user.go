@@ -2,37 +2,26 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/spf13/cobra"
-	"github.com/tendermint/clearchain/client"
-	"github.com/tendermint/clearchain/types"
-	"github.com/tendermint/go-crypto"
-	"github.com/tendermint/go-wire"
 	"os"
-	"strconv"
-)
 
-func init() {
-	RootCmd.AddCommand(&cobra.Command{
-		Use:   "user_to_json",
-		Short: "creates JSON representation for user that could be used in genesis.json",
-		Run: func(cmd *cobra.Command, args []string) {
-			userName := readParameter("username")
-			entityID := readParameter("entityID")
-			permissions, err := strconv.Atoi(readParameter("permissions"))
-			if err != nil {
-				panic(err)
-			}
-
-			pubKey, err := crypto.PubKeyFromBytes(client.Decode(readParameter("public Key")))
-			if err != nil {
-				panic(err)
-			}
+	"github.com/spf13/cobra"
+)
 
-			user := types.NewUser(pubKey, userName, entityID, types.Perm(permissions))
+var (
+	flagUserName    string
+	flagPermissions string
+	flagPubKey      string
+)
 
-			fmt.Println(string(wire.JSONBytes(user)))
-		},
-	})
+func init() {
+	userToJSONCmd := &cobra.Command{
+		Use:        "user_to_json [username entityID permissions publicKey]",
+		Short:      genesisUserCmd.Short,
+		Deprecated: "use 'ledgerctl genesis user' instead",
+		Run:        genesisUserCmd.Run,
+	}
+	addGenesisUserFlags(userToJSONCmd)
+	RootCmd.AddCommand(userToJSONCmd)
 }
 
 func readParameter(name string) string {
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+)
+
+var (
+	flagCurrencyName  string
+	flagSymbol        string
+	flagDecimalPlaces string
+	flagMinimumUnit   string
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{currencyAddCmd, currencyUpdateCmd} {
+		cmd.Flags().StringVar(&flagCurrencyName, "name", "", "Human-readable name of the currency")
+		cmd.Flags().StringVar(&flagDecimalPlaces, "decimal-places", "", "Number of decimal places of the currency's amounts")
+		cmd.Flags().StringVar(&flagMinimumUnit, "minimum-unit", "", "Smallest amount that can be transferred, in minor units")
+	}
+	for _, cmd := range []*cobra.Command{currencyAddCmd, currencyUpdateCmd, currencyRetireCmd} {
+		cmd.Flags().StringVar(&flagSymbol, "symbol", "", "Symbol of the currency, e.g. EUR")
+	}
+	currencyCmd.AddCommand(currencyListCmd, currencyAddCmd, currencyUpdateCmd, currencyRetireCmd)
+	RootCmd.AddCommand(currencyCmd)
+}
+
+var currencyCmd = &cobra.Command{
+	Use:   "currency",
+	Short: "Query or manage the ledger's currency registry",
+}
+
+var currencyListCmd = &cobra.Command{
+	Use:   "list [serverAddress]",
+	Short: "List the currencies and instruments of the registry",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress := flagNode
+		if len(args) == 1 {
+			//ledgerctl currency list 127.0.0.1:46657
+			serverAddress = args[0]
+		}
+		serverAddress = param(serverAddress, "node", "serverAddress")
+
+		client.StartClient(serverAddress)
+		currencies := []*types.CurrencyEntry{}
+		params := url.Values{}
+		for {
+			page := client.ListCurrencies(params)
+			currencies = append(currencies, page.Currencies...)
+			if len(page.Next) == 0 {
+				break
+			}
+			params.Set("cursor", page.Next)
+		}
+		writeOutput(currencies, func(w io.Writer) error {
+			tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(tw, "SYMBOL\tNAME\tDECIMAL PLACES\tMINIMUM UNIT\tRETIRED")
+			for _, c := range currencies {
+				fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%v\n", c.Symbol, c.Name, c.DecimalPlaces, c.MinimumUnit, c.Retired)
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+var currencyAddCmd = &cobra.Command{
+	Use:   "add [chainID serverAddress privateKey symbol decimalPlaces minimumUnit]",
+	Short: "Add a currency or instrument to the registry",
+	Run: func(cmd *cobra.Command, args []string) {
+		signer, currency := readCurrencyParameters(args)
+		client.AddCurrency(signer, currency)
+	},
+}
+
+var currencyUpdateCmd = &cobra.Command{
+	Use:   "update [chainID serverAddress privateKey symbol decimalPlaces minimumUnit]",
+	Short: "Update the name or the minimum unit of a registry's currency",
+	Run: func(cmd *cobra.Command, args []string) {
+		signer, currency := readCurrencyParameters(args)
+		client.UpdateCurrency(signer, currency)
+	},
+}
+
+var currencyRetireCmd = &cobra.Command{
+	Use:   "retire [chainID serverAddress privateKey symbol]",
+	Short: "Retire a currency, which can then only be redeemed",
+	Run: func(cmd *cobra.Command, args []string) {
+		chainID, serverAddress, privateKeyParam, symbol := flagChainID, flagNode, flagKey, flagSymbol
+
+		if len(args) == 4 {
+			//ledgerctl currency retire test_chain_id 127.0.0.1:46657 ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s= WHEAT-BU
+			chainID = args[0]
+			serverAddress = args[1]
+			privateKeyParam = args[2]
+			symbol = args[3]
+		}
+		chainID = param(chainID, "chain-id", "chainID")
+		serverAddress = param(serverAddress, "node", "serverAddress")
+		privateKeyParam = param(privateKeyParam, "key", "privateKey")
+		symbol = param(symbol, "symbol", "symbol")
+
+		signer := mustSigner(privateKeyParam)
+
+		client.SetChainID(chainID)
+		client.StartClient(serverAddress)
+		client.RetireCurrency(signer, symbol)
+	},
+}
+
+// readCurrencyParameters connects to the ledger and returns the
+// signer and the currency given either as flags, arguments or on stdin.
+func readCurrencyParameters(args []string) (client.Signer, types.CurrencyEntry) {
+	chainID, serverAddress, privateKeyParam := flagChainID, flagNode, flagKey
+	symbol, decimalPlacesParam, minimumUnitParam := flagSymbol, flagDecimalPlaces, flagMinimumUnit
+
+	if len(args) == 6 {
+		//ledgerctl currency add test_chain_id 127.0.0.1:46657 ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s= WHEAT-BU 3 250 --name "Wheat bushel"
+		chainID = args[0]
+		serverAddress = args[1]
+		privateKeyParam = args[2]
+		symbol = args[3]
+		decimalPlacesParam = args[4]
+		minimumUnitParam = args[5]
+	}
+	chainID = param(chainID, "chain-id", "chainID")
+	serverAddress = param(serverAddress, "node", "serverAddress")
+	privateKeyParam = param(privateKeyParam, "key", "privateKey")
+	symbol = param(symbol, "symbol", "symbol")
+	decimalPlacesParam = param(decimalPlacesParam, "decimal-places", "decimalPlaces")
+	minimumUnitParam = param(minimumUnitParam, "minimum-unit", "minimumUnit")
+
+	signer := mustSigner(privateKeyParam)
+	decimalPlaces, err := strconv.ParseUint(decimalPlacesParam, 10, 32)
+	if err != nil {
+		panic(err)
+	}
+	minimumUnit, err := strconv.ParseInt(minimumUnitParam, 10, 64)
+	if err != nil {
+		panic(err)
+	}
+
+	client.SetChainID(chainID)
+	client.StartClient(serverAddress)
+	return signer, types.CurrencyEntry{
+		Symbol:        symbol,
+		Name:          flagCurrencyName,
+		DecimalPlaces: uint(decimalPlaces),
+		MinimumUnit:   minimumUnit,
+	}
+}
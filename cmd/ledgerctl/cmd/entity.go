@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/satori/go.uuid"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+)
+
+var (
+	flagEntityType string
+	flagEntityName string
+	flagParentID   string
+)
+
+func init() {
+	entityCreateCmd.Flags().StringVar(&flagEntityID, "entity-id", "", "ID of the new legal entity, a new UUID if empty")
+	entityCreateCmd.Flags().StringVar(&flagEntityType, "type", "", "Type of the legal entity: ch, gcm, icm or custodian")
+	entityCreateCmd.Flags().StringVar(&flagEntityName, "name", "", "Name of the legal entity")
+	entityCreateCmd.Flags().StringVar(&flagParentID, "parent-id", "", "ID of the parent legal entity")
+	entityShowCmd.Flags().StringVar(&flagEntityID, "entity-id", "", "ID of the legal entity")
+	entityListCmd.Flags().StringVar(&flagEntityType, "type", "", "Only list the legal entities of this type")
+	entityListCmd.Flags().StringVar(&flagParentID, "parent-id", "", "Only list the children of this legal entity")
+	entityTreeCmd.Flags().StringVar(&flagEntityID, "entity-id", "", "Root of the tree, all the legal entities if empty")
+	entityCmd.AddCommand(entityCreateCmd, entityShowCmd, entityListCmd, entityTreeCmd)
+	RootCmd.AddCommand(entityCmd)
+}
+
+var entityCmd = &cobra.Command{
+	Use:   "entity",
+	Short: "Create or query the ledger's legal entities",
+}
+
+var entityCreateCmd = &cobra.Command{
+	Use:   "create [chainID serverAddress privateKey entityID type name parentID]",
+	Short: "Create a legal entity",
+	Run: func(cmd *cobra.Command, args []string) {
+		chainID, serverAddress, privateKeyParam := flagChainID, flagNode, flagKey
+		entityID, typeParam, name, parentID := flagEntityID, flagEntityType, flagEntityName, flagParentID
+
+		if len(args) == 7 {
+			//ledgerctl entity create test_chain_id 127.0.0.1:46657 admin b40cbf4e-5923-4ccd-beec-e22a9117b91b gcm "GCM Bank" 0d0e3bd4-3a35-4bf8-a1c6-4ba6d1e4a640
+			chainID, serverAddress, privateKeyParam = args[0], args[1], args[2]
+			entityID, typeParam, name, parentID = args[3], args[4], args[5], args[6]
+		}
+		if len(entityID) == 0 {
+			entityID = uuid.NewV4().String()
+		}
+		signer := mustConnectSigner(chainID, serverAddress, privateKeyParam)
+		entityType, ok := types.ParseEntityType(param(typeParam, "type", "type (ch, gcm, icm or custodian)"))
+		if !ok {
+			log.Fatal("Invalid legal entity type, use ch, gcm, icm or custodian")
+		}
+		parentID = param(parentID, "parent-id", "parentID")
+
+		client.CreateLegalEntity(signer, entityID, entityType, name, parentID)
+		writeLegalEntity(client.GetLegalEntity(entityID))
+	},
+}
+
+var entityShowCmd = &cobra.Command{
+	Use:   "show [serverAddress entityID]",
+	Short: "Show a legal entity",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress, entityID := flagNode, flagEntityID
+
+		if len(args) == 2 {
+			//ledgerctl entity show 127.0.0.1:46657 b40cbf4e-5923-4ccd-beec-e22a9117b91b
+			serverAddress, entityID = args[0], args[1]
+		}
+		mustConnect(serverAddress)
+		entityID = param(entityID, "entity-id", "entityID")
+
+		writeLegalEntity(client.GetLegalEntity(entityID))
+	},
+}
+
+var entityListCmd = &cobra.Command{
+	Use:   "list [serverAddress]",
+	Short: "List the legal entities",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress := flagNode
+		if len(args) == 1 {
+			//ledgerctl entity list 127.0.0.1:46657 --type gcm
+			serverAddress = args[0]
+		}
+		mustConnect(serverAddress)
+
+		params := url.Values{}
+		if len(flagEntityType) > 0 {
+			params.Set("type", flagEntityType)
+		}
+		if len(flagParentID) > 0 {
+			params.Set("parent_id", flagParentID)
+		}
+		entities := listLegalEntities(params)
+		writeOutput(entities, func(w io.Writer) error {
+			return writeLegalEntitiesTable(w, entities)
+		})
+	},
+}
+
+var entityTreeCmd = &cobra.Command{
+	Use:   "tree [serverAddress]",
+	Short: "Show the hierarchy of the legal entities, from their parents to their children",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress := flagNode
+		if len(args) == 1 {
+			//ledgerctl entity tree 127.0.0.1:46657 --entity-id b40cbf4e-5923-4ccd-beec-e22a9117b91b
+			serverAddress = args[0]
+		}
+		mustConnect(serverAddress)
+
+		roots := newEntityTree(listLegalEntities(url.Values{}), flagEntityID)
+		if len(flagEntityID) > 0 && len(roots) == 0 {
+			log.Fatalf("Unknown legal entity %q", flagEntityID)
+		}
+		writeOutput(roots, func(w io.Writer) error {
+			for _, root := range roots {
+				if err := root.write(w, ""); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	},
+}
+
+// listLegalEntities returns all the legal entities matching the filters in
+// params.
+func listLegalEntities(params url.Values) []*types.LegalEntity {
+	entities := []*types.LegalEntity{}
+	for {
+		page := client.ListLegalEntities(params)
+		entities = append(entities, page.LegalEntities...)
+		if len(page.Next) == 0 {
+			return entities
+		}
+		params.Set("cursor", page.Next)
+	}
+}
+
+// writeLegalEntity prints the legal entity queried by client.GetLegalEntity.
+func writeLegalEntity(returned types.LegalEntitiesReturned) {
+	if len(returned.LegalEntities) != 1 {
+		log.Fatalf("Expected one legal entity, got %d", len(returned.LegalEntities))
+	}
+	writeOutput(returned.LegalEntities[0], func(w io.Writer) error {
+		return writeLegalEntitiesTable(w, returned.LegalEntities)
+	})
+}
+
+func writeLegalEntitiesTable(w io.Writer, entities []*types.LegalEntity) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTYPE\tNAME\tPARENT\tPERMISSIONS")
+	for _, e := range entities {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", e.ID, types.EntityTypeName(e.Type), e.Name, e.EntityID, e.Permissions)
+	}
+	return tw.Flush()
+}
+
+// entityTreeNode is a legal entity of the hierarchy, with its children.
+type entityTreeNode struct {
+	ID       string            `json:"id"`
+	Type     string            `json:"type"`
+	Name     string            `json:"name"`
+	Children []*entityTreeNode `json:"children"`
+}
+
+// newEntityTree returns the hierarchy of the entities below rootID, or
+// else of all the entities whose parent isn't listed. Children are sorted
+// by name, then ID.
+func newEntityTree(entities []*types.LegalEntity, rootID string) []*entityTreeNode {
+	nodes := map[string]*entityTreeNode{}
+	for _, e := range entities {
+		nodes[e.ID] = &entityTreeNode{ID: e.ID, Type: types.EntityTypeName(e.Type), Name: e.Name, Children: []*entityTreeNode{}}
+	}
+	roots := []*entityTreeNode{}
+	for _, e := range entities {
+		node := nodes[e.ID]
+		if parent, ok := nodes[e.EntityID]; ok && e.EntityID != e.ID {
+			parent.Children = append(parent.Children, node)
+		} else if len(rootID) == 0 {
+			roots = append(roots, node)
+		}
+	}
+	if len(rootID) > 0 {
+		if root, ok := nodes[rootID]; ok {
+			roots = append(roots, root)
+		}
+	}
+	sortEntityTree(roots)
+	return roots
+}
+
+func sortEntityTree(nodes []*entityTreeNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].ID < nodes[j].ID
+	})
+	for _, node := range nodes {
+		sortEntityTree(node.Children)
+	}
+}
+
+// write writes the node and its descendants, one per line indented by
+// their depth.
+func (n *entityTreeNode) write(w io.Writer, indent string) error {
+	if _, err := fmt.Fprintf(w, "%s%s [%s] %s\n", indent, n.ID, n.Type, n.Name); err != nil {
+		return err
+	}
+	for _, child := range n.Children {
+		if err := child.write(w, indent+"  "); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+)
+
+var (
+	flagExportHeight  uint64
+	flagExportChainID string
+	flagExportOutput  string
+	flagExportForce   bool
+)
+
+func init() {
+	exportCmd.Flags().Uint64Var(&flagExportHeight, "height", 0, "Export the state as it was at the given block height, the latest one if 0")
+	exportCmd.Flags().StringVar(&flagExportChainID, "chain-id", "", "Chain ID of the exported genesis document, the current one if empty")
+	exportCmd.Flags().StringVarP(&flagExportOutput, "output-file", "O", "", "File to write the genesis document to, standard output if empty")
+	exportCmd.Flags().BoolVar(&flagExportForce, "force", false, "Write the genesis document even if it doesn't pass validation")
+	RootCmd.AddCommand(exportCmd)
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [serverAddress]",
+	Short: "Export the ledger's state as a genesis document to boot a new chain from",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress := flagNode
+		if len(args) == 1 {
+			//ledgerctl export 127.0.0.1:46657 --height 1000 --chain-id new_chain_id --output-file genesis.json
+			serverAddress = args[0]
+		}
+		serverAddress = param(serverAddress, "node", "serverAddress")
+
+		doc, err := client.New(serverAddress).ExportGenesis(context.Background(), flagExportHeight)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if len(flagExportChainID) > 0 {
+			doc.ChainID = flagExportChainID
+		}
+		if err := doc.Validate(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			if !flagExportForce {
+				os.Exit(1)
+			}
+		}
+
+		data, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if len(flagExportOutput) == 0 {
+			fmt.Println(string(data))
+			return
+		}
+		if err := ioutil.WriteFile(flagExportOutput, append(data, '\n'), 0644); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+	"github.com/tendermint/go-wire"
+)
+
+func init() {
+	addGenesisAccountFlags(genesisAccountCmd)
+	addGenesisUserFlags(genesisUserCmd)
+	genesisCmd.AddCommand(genesisValidateCmd, genesisAccountCmd, genesisUserCmd)
+	RootCmd.AddCommand(genesisCmd)
+}
+
+var genesisCmd = &cobra.Command{
+	Use:   "genesis",
+	Short: "Work with genesis documents",
+}
+
+var genesisValidateCmd = &cobra.Command{
+	Use:   "validate [genesisFile]",
+	Short: "Validate a genesis document's schema and consistency",
+	Run: func(cmd *cobra.Command, args []string) {
+		var filePath string
+
+		if len(args) == 1 {
+			//ledgerctl genesis validate genesis.json
+			filePath = args[0]
+		} else if flagNoPrompt {
+			log.Fatal("Usage: ledgerctl genesis validate genesisFile")
+		} else {
+			filePath = readParameter("genesisFile")
+		}
+
+		data, err := ioutil.ReadFile(filePath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		doc, err := types.GenesisDocFromJSON(data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("OK: chain %q, %d legal entities, %d users, %d accounts\n",
+			doc.ChainID, len(doc.LegalEntities), len(doc.Users), len(doc.Accounts))
+	},
+}
+
+var genesisAccountCmd = &cobra.Command{
+	Use:   "account [accountID entityID]",
+	Short: "Print the JSON of an account, to add to a genesis document",
+	Run: func(cmd *cobra.Command, args []string) {
+		accountID, entityID := flagAccountID, flagEntityID
+		if len(args) == 2 {
+			//ledgerctl genesis account 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f b40cbf4e-5923-4ccd-beec-e22a9117b91b
+			accountID, entityID = args[0], args[1]
+		}
+		accountID = param(accountID, "account-id", "accountID")
+		entityID = param(entityID, "entity-id", "entityID")
+
+		account := types.NewAccount(accountID, entityID)
+
+		fmt.Println(string(wire.JSONBytes(account)))
+	},
+}
+
+var genesisUserCmd = &cobra.Command{
+	Use:   "user [username entityID permissions publicKey]",
+	Short: "Print the JSON of a user, to add to a genesis document",
+	Run: func(cmd *cobra.Command, args []string) {
+		userName, entityID, permissionsParam, pubKeyParam := flagUserName, flagEntityID, flagPermissions, flagPubKey
+		if len(args) == 4 {
+			userName, entityID, permissionsParam, pubKeyParam = args[0], args[1], args[2], args[3]
+		}
+		userName = param(userName, "name", "username")
+		entityID = param(entityID, "entity-id", "entityID")
+		permissions, err := strconv.Atoi(param(permissionsParam, "permissions", "permissions"))
+		if err != nil {
+			panic(err)
+		}
+
+		pubKey, err := crypto.PubKeyFromBytes(client.Decode(param(pubKeyParam, "pub-key", "public Key")))
+		if err != nil {
+			panic(err)
+		}
+
+		user := types.NewUser(pubKey, userName, entityID, types.Perm(permissions))
+
+		fmt.Println(string(wire.JSONBytes(user)))
+	},
+}
+
+// addGenesisAccountFlags adds the flags of the account a command prints.
+func addGenesisAccountFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flagAccountID, "account-id", "", "ID of the account")
+	cmd.Flags().StringVar(&flagEntityID, "entity-id", "", "ID of the legal entity owning the account")
+}
+
+// addGenesisUserFlags adds the flags of the user a command prints.
+func addGenesisUserFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&flagUserName, "name", "", "Name of the user")
+	cmd.Flags().StringVar(&flagEntityID, "entity-id", "", "ID of the user's legal entity")
+	cmd.Flags().StringVar(&flagPermissions, "permissions", "", "Permissions of the user, as a number")
+	cmd.Flags().StringVar(&flagPubKey, "pub-key", "", "Public key of the user, base64 encoded")
+}
@@ -2,38 +2,63 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
 	"github.com/tendermint/go-wire"
 )
 
+var (
+	flagHeight  uint64
+	flagDecimal bool
+)
+
 func init() {
-	RootCmd.AddCommand(&cobra.Command{
-		Use:   "get_wallet",
+	getWalletCmd := &cobra.Command{
+		Use:   "get_wallet [chainID serverAddress accountID]",
 		Short: "get wallet for an account from blockchain",
 		Run: func(cmd *cobra.Command, args []string) {
 
-			var chainID, serverAddress, accountID string
+			chainID, serverAddress, accountID := flagChainID, flagNode, flagAccountID
 
-			if len(args) == 4 {
-				//TMSP version
-				//ledgerctl get_wallet test_chain_id tcp://127.0.0.1:46658 ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s= 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f
-				//Websocket version
-				//ledgerctl get_wallet test_chain_id 127.0.0.1:46657 ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s= 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f
-
-				chainID = args[0]
-				serverAddress = args[1]
-				accountID = args[2]
-			} else {
-				chainID = readParameter("chainID")
-				serverAddress = readParameter("serverAddress")
-				accountID = readParameter("accountID")
+			switch len(args) {
+			case 3:
+				//ledgerctl get_wallet test_chain_id 127.0.0.1:46657 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f
+				chainID, serverAddress, accountID = args[0], args[1], args[2]
+			case 4:
+				// Older form, whose privateKey isn't needed by queries
+				chainID, serverAddress, accountID = args[0], args[1], args[3]
 			}
-			
+			chainID = param(chainID, "chain-id", "chainID")
+			serverAddress = param(serverAddress, "node", "serverAddress")
+			accountID = param(accountID, "account-id", "accountID")
+
 			client.SetChainID(chainID)
 			client.StartClient(serverAddress)
-			fmt.Println(string(wire.JSONBytes(client.GetAccount(accountID).Account[0])))
+			var returned types.AccountsReturned
+			if flagHeight != 0 {
+				returned = client.GetAccountAtHeight(accountID, flagHeight)
+			} else {
+				returned = client.GetAccount(accountID)
+			}
+			// The wallet is printed as JSON unless a table is asked for
+			if flagDecimal || flagOutput == outputTable {
+				tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+				fmt.Fprintln(tw, "CURRENCY\tBALANCE")
+				for _, b := range returned.Balances[accountID] {
+					fmt.Fprintf(tw, "%s\t%s\n", b.Currency, displayAmount(b))
+				}
+				tw.Flush()
+				return
+			}
+			fmt.Println(string(wire.JSONBytes(returned.Account[0])))
 		},
-	})
+	}
+	getWalletCmd.Flags().Uint64Var(&flagHeight, "height", 0, "Get the wallet as it was at the given block height")
+	getWalletCmd.Flags().StringVar(&flagAccountID, "account-id", "", "ID of the account")
+	getWalletCmd.Flags().BoolVar(&flagDecimal, "decimal", false, "Print the balances as decimal amounts, e.g. 100.00, like --output table")
+	RootCmd.AddCommand(getWalletCmd)
 }
@@ -20,48 +20,56 @@ var (
 func init() {
 	keygenCmd.Flags().BoolVar(&flagWithSecret, "with-secret", false, "Generate keys from a secret")
 	keygenCmd.Flags().StringVarP(&flagOutputFile, "output-file", "O", "",
-		`Write the private key binary format to the given file;
-			     the public key and the address files will be saved
-			     with .pub and .addr extensions respectively`)
+		`Write the private key, encrypted with a passphrase, to the given file
+			     for use as file:<output-file>; the public key and the address
+			     files will be saved with .pub and .addr extensions respectively`)
 	RootCmd.AddCommand(keygenCmd)
 }
 
 var keygenCmd = &cobra.Command{
 	Use:   "keygen",
-	Short: "Generate secure private and public key pair",
+	Short: "Generate secure private and public key pair, see also 'keys add'",
 	Run: func(cmd *cobra.Command, args []string) {
-		var privKey crypto.PrivKey
 		log.Println("Generating public/private key pair...")
-		if flagWithSecret {
-			fmt.Fprintf(os.Stderr, "Enter a secret: ")
-			userInput, err := ReadLineBytes(os.Stdin)
-			if err != nil {
-				log.Fatal(err)
-			}
-			secret, err := bcrypt.GenerateFromPassword(userInput, 4)
-			if err != nil {
-				log.Fatal(err)
-			}
-			privKey = crypto.GenPrivKeyEd25519FromSecret(secret)
-		} else {
-			privKey = crypto.GenPrivKeyEd25519()
-		}
+		privKey := mustGenPrivKey(flagWithSecret)
 
 		privKeyBytes := privKey.Bytes()
 		pubKeyBytes := privKey.PubKey().Bytes()
 		addrBytes := privKey.PubKey().Address()
 
-		fmt.Println("\nPrivateKey:\n", client.Encode(privKeyBytes))
+		if len(flagOutputFile) == 0 {
+			fmt.Println("\nPrivateKey:\n", client.Encode(privKeyBytes))
+		}
 		fmt.Println("\nPublicKey:\n", client.Encode(pubKeyBytes))
 		fmt.Println("\nAddress:\n", client.Encode(addrBytes))
 		if len(flagOutputFile) != 0 {
-			mustWriteToFile(mustCreateFile(flagOutputFile), privKeyBytes)
+			if err := client.WriteKeyFile(flagOutputFile, privKey, mustReadNewPassphrase()); err != nil {
+				log.Fatal(err)
+			}
 			mustWriteToFile(mustCreateFile(strings.Join([]string{flagOutputFile, "pub"}, ".")), pubKeyBytes)
 			mustWriteToFile(mustCreateFile(strings.Join([]string{flagOutputFile, "addr"}, ".")), addrBytes)
 		}
 	},
 }
 
+// mustGenPrivKey generates a private key, from a secret read from stdin
+// if withSecret is set.
+func mustGenPrivKey(withSecret bool) crypto.PrivKey {
+	if !withSecret {
+		return crypto.GenPrivKeyEd25519()
+	}
+	fmt.Fprintf(os.Stderr, "Enter a secret: ")
+	userInput, err := ReadLineBytes(os.Stdin)
+	if err != nil {
+		log.Fatal(err)
+	}
+	secret, err := bcrypt.GenerateFromPassword(userInput, 4)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return crypto.GenPrivKeyEd25519FromSecret(secret)
+}
+
 func mustCreateFile(filename string) *os.File {
 	f, err := os.Create(filename)
 	if err != nil {
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/go-crypto"
+)
+
+var (
+	flagKeyWithSecret  bool
+	flagImportRaw      bool
+	flagExportRaw      bool
+	flagDeleteNoPrompt bool
+)
+
+func init() {
+	keysAddCmd.Flags().BoolVar(&flagKeyWithSecret, "with-secret", false, "Generate the key from a secret")
+	keysImportCmd.Flags().BoolVar(&flagImportRaw, "raw", false,
+		"Import an unencrypted private key, base64 encoded or binary as written by older versions of keygen")
+	keysExportCmd.Flags().BoolVar(&flagExportRaw, "raw", false, "Export the private key unencrypted and base64 encoded")
+	keysDeleteCmd.Flags().BoolVarP(&flagDeleteNoPrompt, "yes", "y", false, "Delete the key without asking for confirmation")
+	keysCmd.AddCommand(keysAddCmd, keysListCmd, keysShowCmd, keysDeleteCmd, keysImportCmd, keysExportCmd)
+	RootCmd.AddCommand(keysCmd)
+}
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Manage the keystore's passphrase-encrypted keys",
+	Long: `Manage the keystore's passphrase-encrypted keys.
+
+Commands taking a privateKey parameter accept the name of a key of the keystore,
+whose passphrase is prompted for or read from ` + PassphraseEnv + `.`,
+}
+
+var keysAddCmd = &cobra.Command{
+	Use:   "add name",
+	Short: "Generate a new key and store it encrypted with a passphrase",
+	Run: func(cmd *cobra.Command, args []string) {
+		name := mustKeyNameArg(args)
+		ks := mustOpenKeystore()
+		privKey := mustGenPrivKey(flagKeyWithSecret)
+		if err := ks.Add(name, privKey, mustReadNewPassphrase()); err != nil {
+			log.Fatal(err)
+		}
+		writeKeyInfo(client.KeyInfo{Name: name, PubKey: privKey.PubKey()})
+	},
+}
+
+var keysListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the keys of the keystore",
+	Run: func(cmd *cobra.Command, args []string) {
+		keys, err := mustOpenKeystore().List()
+		if err != nil {
+			log.Fatal(err)
+		}
+		outputs := make([]keyOutput, len(keys))
+		for i, k := range keys {
+			outputs[i] = newKeyOutput(k)
+		}
+		writeOutput(outputs, func(w io.Writer) error {
+			tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tADDRESS\tPUBLIC KEY")
+			for _, k := range outputs {
+				fmt.Fprintf(tw, "%s\t%s\t%s\n", k.Name, k.Address, k.PubKey)
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+var keysShowCmd = &cobra.Command{
+	Use:   "show name",
+	Short: "Print the public key and the address of a key",
+	Run: func(cmd *cobra.Command, args []string) {
+		key, err := mustOpenKeystore().Get(mustKeyNameArg(args))
+		if err != nil {
+			log.Fatal(err)
+		}
+		writeKeyInfo(key)
+	},
+}
+
+var keysDeleteCmd = &cobra.Command{
+	Use:   "delete name",
+	Short: "Delete a key, which can't be recovered unless it was exported",
+	Run: func(cmd *cobra.Command, args []string) {
+		name := mustKeyNameArg(args)
+		ks := mustOpenKeystore()
+		if _, err := ks.Get(name); err != nil {
+			log.Fatal(err)
+		}
+		if !flagDeleteNoPrompt {
+			if flagNoPrompt {
+				log.Fatal("Deleting a key without prompting needs --yes")
+			}
+			fmt.Fprintf(os.Stderr, "Delete key %q? [y/N] ", name)
+			answer, err := ReadLine(os.Stdin)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if answer != "y" && answer != "Y" {
+				return
+			}
+		}
+		if err := ks.Delete(name); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+var keysImportCmd = &cobra.Command{
+	Use:   "import name [file]",
+	Short: "Import a key exported by 'keys export', read from file or stdin",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || len(args) > 2 {
+			log.Fatal("Usage: ledgerctl keys import name [file]")
+		}
+		name := args[0]
+		var data []byte
+		var err error
+		if len(args) == 2 {
+			data, err = ioutil.ReadFile(args[1])
+		} else {
+			data, err = ioutil.ReadAll(os.Stdin)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		ks := mustOpenKeystore()
+		if flagImportRaw {
+			privKey := mustDecodeRawPrivKey(data)
+			err = ks.Add(name, privKey, mustReadNewPassphrase())
+		} else {
+			err = ks.Import(name, data)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		key, err := ks.Get(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		writeKeyInfo(key)
+	},
+}
+
+var keysExportCmd = &cobra.Command{
+	Use:   "export name",
+	Short: "Print a key, encrypted with its passphrase unless --raw is set",
+	Run: func(cmd *cobra.Command, args []string) {
+		name := mustKeyNameArg(args)
+		ks := mustOpenKeystore()
+		if flagExportRaw {
+			privKey, err := ks.PrivKey(name, mustReadPassphrase())
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(client.Encode(privKey.Bytes()))
+			return
+		}
+		data, err := ks.Export(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(string(data))
+	},
+}
+
+func mustOpenKeystore() *client.Keystore {
+	ks, err := client.OpenKeystore(flagKeystore)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return ks
+}
+
+func mustKeyNameArg(args []string) string {
+	if len(args) != 1 {
+		log.Fatal("A key name is required")
+	}
+	if err := client.ValidateKeyName(args[0]); err != nil {
+		log.Fatal(err)
+	}
+	return args[0]
+}
+
+// mustDecodeRawPrivKey decodes an unencrypted private key, either binary
+// or base64 encoded.
+func mustDecodeRawPrivKey(data []byte) crypto.PrivKey {
+	if privKey, err := crypto.PrivKeyFromBytes(data); err == nil {
+		return privKey
+	}
+	privKey, err := crypto.PrivKeyFromBytes(client.Decode(strings.TrimSpace(string(data))))
+	if err != nil {
+		log.Fatalf("Invalid private key: %v", err)
+	}
+	return privKey
+}
+
+// keyOutput describes a key of the keystore, base64 encoded.
+type keyOutput struct {
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	PubKey  string `json:"pub_key"`
+}
+
+func newKeyOutput(key client.KeyInfo) keyOutput {
+	return keyOutput{Name: key.Name, Address: client.Encode(key.Address()), PubKey: client.Encode(key.PubKey.Bytes())}
+}
+
+func writeKeyInfo(key client.KeyInfo) {
+	k := newKeyOutput(key)
+	writeOutput(k, func(w io.Writer) error {
+		fmt.Fprintln(w, "Name:\n", k.Name)
+		fmt.Fprintln(w, "\nPublicKey:\n", k.PubKey)
+		_, err := fmt.Fprintln(w, "\nAddress:\n", k.Address)
+		return err
+	})
+}
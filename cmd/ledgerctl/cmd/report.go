@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+)
+
+var (
+	flagRollup bool
+	flagFormat string
+)
+
+func init() {
+	reportBalancesCmd.Flags().BoolVar(&flagRollup, "rollup", false, "Include the balances of the legal entity's descendants")
+	reportBalancesCmd.Flags().StringVar(&flagEntityID, "entity-id", "", "ID of the legal entity")
+	reportBalancesCmd.Flags().StringVar(&flagFormat, "format", "table", "Output format, either table or csv, unless --output is json")
+	reportCmd.AddCommand(reportBalancesCmd)
+	RootCmd.AddCommand(reportCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate reports from the ledger's state",
+}
+
+var reportBalancesCmd = &cobra.Command{
+	Use:   "balances [serverAddress entityID]",
+	Short: "Report the balances of a legal entity's accounts by currency",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress, entityID := flagNode, flagEntityID
+
+		if len(args) == 2 {
+			//ledgerctl report balances 127.0.0.1:46657 b40cbf4e-5923-4ccd-beec-e22a9117b91b --rollup --format csv
+			serverAddress = args[0]
+			entityID = args[1]
+		}
+		serverAddress = param(serverAddress, "node", "serverAddress")
+		entityID = param(entityID, "entity-id", "entityID")
+
+		client.StartClient(serverAddress)
+		report := client.GetLegalEntityBalances(entityID, flagRollup)
+
+		writeOutput(report, func(w io.Writer) error {
+			switch flagFormat {
+			case "table":
+				return writeBalanceReportTable(w, report)
+			case "csv":
+				return writeBalanceReportCSV(w, report)
+			default:
+				return fmt.Errorf("unknown format: %q", flagFormat)
+			}
+		})
+	},
+}
+
+func writeBalanceReportTable(w io.Writer, report types.BalanceReport) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ENTITY\tNAME\tACCOUNTS\tCURRENCY\tBALANCE")
+	for _, e := range report.Entities {
+		for _, b := range e.Balances {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", e.EntityID, e.Name, e.Accounts, b.Currency, displayAmount(b))
+		}
+	}
+	for _, b := range report.Totals {
+		fmt.Fprintf(tw, "TOTAL\t\t\t%s\t%s\n", b.Currency, displayAmount(b))
+	}
+	return tw.Flush()
+}
+
+// displayAmount returns the balance's amount as a decimal string, or in
+// minor units if the ledger didn't render it.
+func displayAmount(b types.Balance) string {
+	if len(b.Display) > 0 {
+		return b.Display
+	}
+	return strconv.FormatInt(b.Amount, 10)
+}
+
+// writeBalanceReportCSV writes balances in minor units, which spreadsheets
+// and scripts can sum without knowing the currencies' decimal places.
+func writeBalanceReportCSV(w io.Writer, report types.BalanceReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"entity_id", "name", "parent_id", "accounts", "currency", "balance"})
+	for _, e := range report.Entities {
+		for _, b := range e.Balances {
+			cw.Write([]string{e.EntityID, e.Name, e.ParentID, strconv.Itoa(e.Accounts), b.Currency, strconv.FormatInt(b.Amount, 10)})
+		}
+	}
+	for _, b := range report.Totals {
+		cw.Write([]string{"TOTAL", "", "", "", b.Currency, strconv.FormatInt(b.Amount, 10)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -2,22 +2,91 @@ package cmd
 
 import (
 	"bufio"
+	"fmt"
 	"io"
+	"log"
 	"os"
 
-	"fmt"
-
 	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
 )
 
+// Global flags, which can also be set by environment variables and the
+// config file, see loadSettings
+var (
+	flagConfig   string
+	flagProfile  string
+	flagChainID  string
+	flagNode     string
+	flagKey      string
+	flagOutput   string
+	flagNoPrompt bool
+	flagValidFor uint64
+	flagKeystore string
+)
+
+func init() {
+	flags := RootCmd.PersistentFlags()
+	flags.StringVar(&flagConfig, "config", defaultConfigPath(), "Config file setting the global flags, see 'ledgerctl help config'")
+	flags.StringVar(&flagProfile, "profile", "", "Profile of the config file to use, the config file's default one if empty")
+	flags.StringVar(&flagChainID, "chain-id", "", "ID of the chain the transactions are signed for")
+	flags.StringVar(&flagNode, "node", "", "RPC address of the ledger node, e.g. 127.0.0.1:46657")
+	flags.StringVar(&flagKey, "key", "", "privateKey signing the transactions, typically the name of a key of the keystore")
+	flags.StringVar(&flagOutput, "output", "", "Output format of queries, json or table, each command's usual one if empty")
+	flags.BoolVar(&flagNoPrompt, "no-prompt", false, "Fail instead of prompting for missing parameters")
+	flags.Uint64Var(&flagValidFor, "valid-for", 0,
+		"Number of blocks the transactions sent stay valid for, 0 means they never expire")
+	flags.StringVar(&flagKeystore, "keystore", client.DefaultKeystoreDir(),
+		"Directory of the encrypted keys referenced by name")
+	RootCmd.AddCommand(configHelpCmd)
+}
+
 var RootCmd = &cobra.Command{
 	Use:   "ledgerctl",
 	Short: "Query or send commands to the ledger",
-	Long:  `Manage, query, and send transactions to the clearchain ledger`,
+	Long: `Manage, query, and send transactions to the clearchain ledger.
+
+The parameters of the commands are given by flags, and the global ones can
+also be set by environment variables and a config file, see 'ledgerctl help
+config'. Missing parameters are prompted for, unless --no-prompt is set.
+Commands also accept their parameters as positional arguments, in the order
+of their usage line.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Do Stuff Here
 		fmt.Fprintln(os.Stderr, "Run 'ledgerctl --help' for usage.")
 	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if err := loadSettings(cmd.Root().PersistentFlags()); err != nil {
+			log.Fatal(err)
+		}
+		client.SetValidFor(flagValidFor)
+	},
+}
+
+var configHelpCmd = &cobra.Command{
+	Use:   "config",
+	Short: "How to set the global flags with environment variables and a config file",
+	Long: `The global flags, e.g. --chain-id, --node and --key, are set by the first of:
+
+  1. the command line;
+  2. an environment variable named after the flag, e.g. ` + EnvPrefix + `CHAIN_ID;
+  3. the selected profile of the config file, see --profile;
+  4. the top level of the config file, ~/.ledgerctl.yaml unless --config is set.
+
+The config file is written in YAML, with settings named after the flags:
+
+  chain-id: test_chain_id
+  node: 127.0.0.1:46657
+  key: alice
+  profile: local
+  profiles:
+    local:
+      node: 127.0.0.1:46657
+    prod:
+      chain-id: clearchain
+      node: ledger.example.com:46657
+      key: ops
+      no-prompt: true`,
 }
 
 // ReadLine reads a single line from a io.Reader.
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/go-crypto"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// PassphraseEnv is the environment variable read for the passphrase of
+// key files before prompting for it
+const PassphraseEnv = "LEDGERCTL_PASSPHRASE"
+
+// Prefixes of the privateKey parameter that reference a signer
+// instead of holding a base64 encoded private key
+const (
+	keyFilePrefix      = "file:"
+	remoteSignerPrefix = "unix:"
+)
+
+var flagSocket string
+
+func init() {
+	signerServeCmd.Flags().StringVar(&flagSocket, "socket", "", "Path of the Unix socket to listen on")
+	signerCmd.AddCommand(signerServeCmd)
+	RootCmd.AddCommand(signerCmd)
+}
+
+var signerCmd = &cobra.Command{
+	Use:   "signer",
+	Short: "Run a remote signer",
+}
+
+var signerServeCmd = &cobra.Command{
+	Use:   "serve [key socketPath]",
+	Short: "Sign transactions with a key of the keystore, or a file:<path> key file, for clients connecting to a Unix socket",
+	Run: func(cmd *cobra.Command, args []string) {
+		key, socketPath := flagKey, flagSocket
+
+		if len(args) == 2 {
+			//ledgerctl signer serve alice /tmp/alice.sock
+			key = args[0]
+			socketPath = args[1]
+		}
+		key = param(key, "key", "key")
+		socketPath = param(socketPath, "socket", "socketPath")
+
+		signer := mustSigner(key)
+		l, err := net.Listen("unix", socketPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer l.Close()
+		log.Printf("Signing for %X on %s\n", signer.Address(), socketPath)
+		log.Fatal(client.ServeSigner(l, signer))
+	},
+}
+
+// mustSigner returns the Signer a privateKey parameter references: the
+// name of a key of the keystore, "file:<path>" for an encrypted key file,
+// "unix:<path>" for a remote signer listening on a Unix socket, otherwise
+// the parameter is a base64 encoded private key.
+func mustSigner(param string) client.Signer {
+	switch {
+	case client.ValidateKeyName(param) == nil:
+		signer, err := mustOpenKeystore().Signer(param, mustReadPassphrase())
+		if err != nil {
+			log.Fatalf("Key %q: %v", param, err)
+		}
+		return signer
+	case strings.HasPrefix(param, keyFilePrefix):
+		signer, err := client.NewKeyFileSigner(strings.TrimPrefix(param, keyFilePrefix), mustReadPassphrase())
+		if err != nil {
+			log.Fatal(err)
+		}
+		return signer
+	case strings.HasPrefix(param, remoteSignerPrefix):
+		signer, err := client.NewRemoteSigner(strings.TrimPrefix(param, remoteSignerPrefix))
+		if err != nil {
+			log.Fatal(err)
+		}
+		return signer
+	default:
+		log.Println("Warning: private keys given as parameters can leak through the shell history, see 'ledgerctl keys import'")
+		privKey, err := crypto.PrivKeyFromBytes(client.Decode(param))
+		if err != nil {
+			panic(err)
+		}
+		return client.NewKeySigner(privKey)
+	}
+}
+
+// mustReadPassphrase returns the passphrase of PassphraseEnv, or else
+// prompts for it.
+func mustReadPassphrase() []byte {
+	if passphrase, ok := os.LookupEnv(PassphraseEnv); ok {
+		return []byte(passphrase)
+	}
+	return mustPromptPassphrase("Enter the passphrase: ")
+}
+
+// mustReadNewPassphrase returns the passphrase of PassphraseEnv, or else
+// prompts for a new passphrase twice.
+func mustReadNewPassphrase() []byte {
+	if passphrase, ok := os.LookupEnv(PassphraseEnv); ok {
+		return []byte(passphrase)
+	}
+	passphrase := mustPromptPassphrase("Enter a passphrase: ")
+	if len(passphrase) == 0 {
+		log.Fatal("The passphrase can't be empty")
+	}
+	if !bytes.Equal(passphrase, mustPromptPassphrase("Repeat the passphrase: ")) {
+		log.Fatal("The passphrases don't match")
+	}
+	return passphrase
+}
+
+// mustPromptPassphrase reads a passphrase from stdin, without echoing
+// it if stdin is a terminal.
+func mustPromptPassphrase(prompt string) []byte {
+	fmt.Fprint(os.Stderr, prompt)
+	var passphrase []byte
+	var err error
+	if fd := int(os.Stdin.Fd()); terminal.IsTerminal(fd) {
+		passphrase, err = terminal.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+	} else {
+		passphrase, err = ReadLineBytes(os.Stdin)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return passphrase
+}
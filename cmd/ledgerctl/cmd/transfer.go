@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+)
+
+var flagTransferID string
+
+func init() {
+	addTransferFlags(transferSendCmd, "Comma separated privateKeys of the counter signers, - for none")
+	transferShowCmd.Flags().StringVar(&flagTransferID, "id", "", "ID of the transfer")
+	transferListCmd.Flags().StringVar(&flagTransfersReference, "reference", "", "Only list the transfers carrying this reference")
+	transferCmd.AddCommand(transferSendCmd, transferShowCmd, transferListCmd)
+	RootCmd.AddCommand(transferCmd)
+}
+
+var transferCmd = &cobra.Command{
+	Use:   "transfer",
+	Short: "Send money between accounts, or query the transfer history",
+	Long: `Send money between accounts, or query the transfer history.
+
+Transfers whose counter signers can't sign on the same machine are built
+and signed offline with 'ledgerctl tx'.`,
+}
+
+var transferSendCmd = &cobra.Command{
+	Use:   "send [chainID serverAddress privateKey senderID recipientID counterSigners amount currency]",
+	Short: "Transfer money from an account to another",
+	Run:   sendTransfer,
+}
+
+var transferShowCmd = &cobra.Command{
+	Use:   "show [serverAddress transferID]",
+	Short: "Show a transfer of the history",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress, id := flagNode, flagTransferID
+
+		if len(args) == 2 {
+			//ledgerctl transfer show 127.0.0.1:46657 5F2B7C0E9A4D1B3F6E8A0C2D4B6F8E1A3C5D7B9F-12
+			serverAddress, id = args[0], args[1]
+		}
+		mustConnect(serverAddress)
+		id = param(id, "id", "transferID")
+
+		returned := client.GetTransfer(id)
+		if len(returned.Transfers) != 1 {
+			log.Fatalf("Expected one transfer, got %d", len(returned.Transfers))
+		}
+		writeOutput(returned.Transfers[0], func(w io.Writer) error {
+			return writeTransfersTable(w, returned.Transfers)
+		})
+	},
+}
+
+var transferListCmd = &cobra.Command{
+	Use:   "list [serverAddress]",
+	Short: "List the ledger's transfer history, oldest first",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress := flagNode
+		if len(args) == 1 {
+			//ledgerctl transfer list 127.0.0.1:46657 --reference trade-1
+			serverAddress = args[0]
+		}
+		mustConnect(serverAddress)
+		writeTransfers(listTransfers(flagTransfersReference))
+	},
+}
+
+// sendTransfer sends the money transfer given either as flags, arguments
+// or on stdin.
+func sendTransfer(cmd *cobra.Command, args []string) {
+	chainID, serverAddress, privateKeyParam := flagChainID, flagNode, flagKey
+	senderID, recipientID, counterSignerParam, amountParam, currency := flagSenderID, flagRecipientID, flagCounterSigners, flagAmount, flagCurrency
+
+	if len(args) == 8 {
+		//ledgerctl transfer send test_chain_id tcp://127.0.0.1:46658 ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s= 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f 6b6d3a08-5527-4955-b4fd-f5ba7e083548 ASrNVL489e9TlRNmIqC+vRs96+ntDRkAi1+jWnf89Nrdc4YgmMK2CzG5yTgMPvNyEq4+b5F41q79tR0MImWtYJA= 100.00 EUR
+
+		chainID = args[0]
+		serverAddress = args[1]
+		privateKeyParam = args[2]
+		senderID = args[3]
+		recipientID = args[4]
+		counterSignerParam = args[5] // `-` as value indicates no counter signers.
+		amountParam = args[6]
+		currency = args[7]
+	}
+	chainID = param(chainID, "chain-id", "chainID")
+	serverAddress = param(serverAddress, "node", "serverAddress")
+	privateKeyParam = param(privateKeyParam, "key", "privateKey")
+	senderID = param(senderID, "sender", "senderID")
+	recipientID = param(recipientID, "recipient", "recipientID")
+	amountParam = param(amountParam, "amount", "amount (e.g. 100.00)")
+	currency = param(currency, "currency", "currency")
+
+	signer := mustSigner(privateKeyParam)
+
+	counterSigners := []client.Signer{}
+	for _, cs := range splitList(counterSignerParam) {
+		counterSigners = append(counterSigners, mustSigner(cs))
+	}
+
+	client.SetChainID(chainID)
+	client.StartClient(serverAddress)
+	amount := client.ParseAmount(amountParam, currency)
+	client.TransferMoney(signer, senderID, recipientID, counterSigners, amount, currency, flagReference, flagMemo)
+}
+
+// addTransferFlags adds the flags of the transfer a command sends or builds.
+func addTransferFlags(cmd *cobra.Command, counterSignersUsage string) {
+	cmd.Flags().StringVar(&flagSenderID, "sender", "", "ID of the sender's account")
+	cmd.Flags().StringVar(&flagRecipientID, "recipient", "", "ID of the recipient's account")
+	cmd.Flags().StringVar(&flagCounterSigners, "counter-signers", "-", counterSignersUsage)
+	cmd.Flags().StringVar(&flagAmount, "amount", "", "Amount to transfer, e.g. 100.00")
+	cmd.Flags().StringVar(&flagCurrency, "currency", "", "Currency of the amount, e.g. EUR")
+	cmd.Flags().StringVar(&flagReference, "reference", "", "Client's reference of the transfer, e.g. a trade ID")
+	cmd.Flags().StringVar(&flagMemo, "memo", "", "Free text for the recipient")
+}
+
+// splitList splits a comma separated list parameter, where '-' stands for
+// an empty list.
+func splitList(param string) []string {
+	if param == "" || param == "-" {
+		return nil
+	}
+	return strings.Split(param, ",")
+}
+
+// listTransfers returns the whole transfer history, or the transfers
+// carrying reference if it's not empty.
+func listTransfers(reference string) []*types.Transfer {
+	params := url.Values{}
+	if len(reference) > 0 {
+		params.Set("reference", reference)
+	}
+	transfers := []*types.Transfer{}
+	for {
+		page := client.ListTransfers(params)
+		transfers = append(transfers, page.Transfers...)
+		if len(page.Next) == 0 {
+			return transfers
+		}
+		params.Set("cursor", page.Next)
+	}
+}
+
+func writeTransfers(transfers []*types.Transfer) {
+	writeOutput(transfers, func(w io.Writer) error {
+		return writeTransfersTable(w, transfers)
+	})
+}
+
+func writeTransfersTable(w io.Writer, transfers []*types.Transfer) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tHEIGHT\tSENDER\tRECIPIENT\tAMOUNT\tCURRENCY\tREFERENCE\tMEMO")
+	for _, t := range transfers {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%s\t%s\t%s\n", t.ID, t.Height, t.SenderID, t.RecipientID, t.Amount, t.Currency, t.Reference, t.Memo)
+	}
+	return tw.Flush()
+}
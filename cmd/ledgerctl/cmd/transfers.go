@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var flagTransfersReference string
+
+func init() {
+	transfersCmd.Flags().StringVar(&flagTransfersReference, "reference", "", "Only list the transfers carrying this reference")
+	RootCmd.AddCommand(transfersCmd)
+}
+
+var transfersCmd = &cobra.Command{
+	Use:        "transfers [serverAddress]",
+	Short:      transferListCmd.Short,
+	Deprecated: "use 'ledgerctl transfer list' instead",
+	Run:        transferListCmd.Run,
+}
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-wire"
+)
+
+var (
+	flagCommitter string
+	flagTxOutput  string
+)
+
+func init() {
+	addTransferFlags(txBuildCmd, "Comma separated key names or addresses of the counter signers, - for none")
+	txBuildCmd.Flags().StringVar(&flagCommitter, "committer", "", "Key name or address of the committer, the --key's if empty")
+	txBuildCmd.Flags().StringVarP(&flagTxOutput, "output-file", "O", "", "File to write the transaction to, standard output if empty")
+	txSignCmd.Flags().StringVarP(&flagTxOutput, "output-file", "O", "", "File to write the signed transaction to, instead of overwriting file")
+	txCmd.AddCommand(txBuildCmd, txSignCmd, txBroadcastCmd, txShowCmd)
+	RootCmd.AddCommand(txCmd)
+}
+
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Build, sign and broadcast transactions offline, or query the delivered ones",
+	Long: `Build, sign and broadcast transactions offline, or query the delivered ones.
+
+A transfer whose counter signers hold their keys on different machines is
+built by 'tx build' into a file, which each of its committer and counter
+signers reviews and signs in turn with 'tx sign', before 'tx broadcast' sends
+it. The committer must not send other transactions in the meantime, as the
+transfer uses the committer's next nonce.`,
+}
+
+var txBuildCmd = &cobra.Command{
+	Use:   "build [chainID serverAddress committer senderID recipientID counterSigners amount currency]",
+	Short: "Build an unsigned money transfer to be signed with 'tx sign'",
+	Run: func(cmd *cobra.Command, args []string) {
+		chainID, serverAddress, committerParam := flagChainID, flagNode, flagCommitter
+		senderID, recipientID, counterSignerParam, amountParam, currency := flagSenderID, flagRecipientID, flagCounterSigners, flagAmount, flagCurrency
+		if len(committerParam) == 0 {
+			committerParam = flagKey
+		}
+
+		if len(args) == 8 {
+			//ledgerctl tx build test_chain_id 127.0.0.1:46657 alice 1d2df1ae-accb-11e6-bbbb-00ff5244ae7f 6b6d3a08-5527-4955-b4fd-f5ba7e083548 bob,carol 100.00 EUR
+			chainID = args[0]
+			serverAddress = args[1]
+			committerParam = args[2]
+			senderID = args[3]
+			recipientID = args[4]
+			counterSignerParam = args[5] // `-` as value indicates no counter signers.
+			amountParam = args[6]
+			currency = args[7]
+		}
+		chainID = param(chainID, "chain-id", "chainID")
+		serverAddress = param(serverAddress, "node", "serverAddress")
+		committerParam = param(committerParam, "committer", "committer (key name or address)")
+		senderID = param(senderID, "sender", "senderID")
+		recipientID = param(recipientID, "recipient", "recipientID")
+		amountParam = param(amountParam, "amount", "amount (e.g. 100.00)")
+		currency = param(currency, "currency", "currency")
+
+		counterSigners := [][]byte{}
+		for _, cs := range splitList(counterSignerParam) {
+			counterSigners = append(counterSigners, mustAddress(cs))
+		}
+
+		client.SetChainID(chainID)
+		client.StartClient(serverAddress)
+		tx := client.BuildTransfer(mustAddress(committerParam), client.Transfer{
+			SenderID:       senderID,
+			RecipientID:    recipientID,
+			Amount:         client.ParseAmount(amountParam, currency),
+			Currency:       currency,
+			Reference:      flagReference,
+			Memo:           flagMemo,
+			CounterSigners: counterSigners,
+		})
+		mustWriteTxFile(flagTxOutput, chainID, tx)
+	},
+}
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign [privateKey] file",
+	Short: "Sign a transaction built by 'tx build', as its committer or one of its counter signers",
+	Run: func(cmd *cobra.Command, args []string) {
+		var privateKeyParam, path string
+
+		switch len(args) {
+		case 2:
+			//ledgerctl tx sign bob transfer.json
+			privateKeyParam, path = args[0], args[1]
+		case 1:
+			privateKeyParam, path = param(flagKey, "key", "privateKey"), args[0]
+		default:
+			log.Fatal("Usage: ledgerctl tx sign [privateKey] file")
+		}
+
+		chainID, tx := mustReadTxFile(path)
+		signedTx, ok := tx.(types.SignedTx)
+		if !ok {
+			log.Fatalf("Transactions of type %T can't be signed", tx)
+		}
+		if err := client.SignTx(signedTx, mustSigner(privateKeyParam), chainID); err != nil {
+			log.Fatal(err)
+		}
+		output := flagTxOutput
+		if len(output) == 0 {
+			output = path
+		}
+		mustWriteTxFile(output, chainID, tx)
+		writeMissingSignatures(tx)
+	},
+}
+
+var txBroadcastCmd = &cobra.Command{
+	Use:   "broadcast [serverAddress] file",
+	Short: "Send a transaction signed with 'tx sign' and wait for it to be committed",
+	Run: func(cmd *cobra.Command, args []string) {
+		var serverAddress, path string
+
+		switch len(args) {
+		case 2:
+			//ledgerctl tx broadcast 127.0.0.1:46657 transfer.json
+			serverAddress, path = args[0], args[1]
+		case 1:
+			serverAddress, path = param(flagNode, "node", "serverAddress"), args[0]
+		default:
+			log.Fatal("Usage: ledgerctl tx broadcast [serverAddress] file")
+		}
+
+		chainID, tx := mustReadTxFile(path)
+		if writeMissingSignatures(tx) {
+			log.Fatal("The transaction can't be broadcast before it is fully signed")
+		}
+		noncedTx, ok := tx.(types.NoncedTx)
+		if !ok {
+			log.Fatalf("Transactions of type %T can't be broadcast", tx)
+		}
+		client.SetChainID(chainID)
+		client.StartClient(serverAddress)
+		client.Broadcast(noncedTx)
+	},
+}
+
+var txShowCmd = &cobra.Command{
+	Use:   "show [serverAddress] hash",
+	Short: "Show a delivered transaction and the result of its execution",
+	Run: func(cmd *cobra.Command, args []string) {
+		var serverAddress, hashHex string
+
+		switch len(args) {
+		case 2:
+			//ledgerctl tx show 127.0.0.1:46657 5F2B7C...
+			serverAddress, hashHex = args[0], args[1]
+		case 1:
+			serverAddress, hashHex = param(flagNode, "node", "serverAddress"), args[0]
+		default:
+			log.Fatal("Usage: ledgerctl tx show [serverAddress] hash")
+		}
+		hash, err := hex.DecodeString(hashHex)
+		if err != nil {
+			log.Fatalf("Invalid hash %q: %v", hashHex, err)
+		}
+
+		client.StartClient(serverAddress)
+		record := client.GetTx(hash)
+		if record == nil {
+			log.Fatalf("Unknown transaction: %X", hash)
+		}
+		tx, err := record.DecodeTx()
+		if err != nil {
+			log.Fatalf("Error decoding tx %X: %v", record.Hash, err)
+		}
+		writeOutput(struct {
+			*types.TxRecord
+			DecodedTx json.RawMessage `json:"decoded_tx"`
+		}{record, wire.JSONBytes(tx)}, func(w io.Writer) error {
+			return writeTxRecord(w, record)
+		})
+	},
+}
+
+// writeTxRecord prints a Tx record, with the Tx decoded from its bytes.
+func writeTxRecord(w io.Writer, record *types.TxRecord) error {
+	tx, err := record.DecodeTx()
+	if err != nil {
+		return fmt.Errorf("Error decoding tx %X: %v", record.Hash, err)
+	}
+	var txJSON bytes.Buffer
+	if err := json.Indent(&txJSON, wire.JSONBytes(tx), "", "  "); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Hash:   %X\n", record.Hash)
+	fmt.Fprintf(w, "Height: %d\n", record.Height)
+	fmt.Fprintf(w, "Type:   %s (0x%02X)\n", reflect.TypeOf(tx).Elem().Name(), record.Type)
+	fmt.Fprintf(w, "Signer: %X\n", record.Signer)
+	fmt.Fprintf(w, "Code:   %v\n", record.Code)
+	if len(record.Log) > 0 {
+		fmt.Fprintf(w, "Log:    %s\n", record.Log)
+	}
+	_, err = fmt.Fprintf(w, "Tx:\n%s\n", txJSON.Bytes())
+	return err
+}
+
+// mustAddress returns the address a parameter references: the name of a
+// key of the keystore, otherwise the parameter is a base64 encoded address.
+func mustAddress(param string) []byte {
+	if client.ValidateKeyName(param) == nil {
+		key, err := mustOpenKeystore().Get(param)
+		if err != nil {
+			log.Fatalf("Key %q: %v", param, err)
+		}
+		return key.Address()
+	}
+	return client.Decode(param)
+}
+
+func mustReadTxFile(path string) (string, types.Tx) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chainID, tx, err := client.DecodeTxFile(data)
+	if err != nil {
+		log.Fatalf("Error decoding %s: %v", path, err)
+	}
+	return chainID, tx
+}
+
+// mustWriteTxFile writes a Tx file to path, or to standard output if path is empty.
+func mustWriteTxFile(path string, chainID string, tx types.Tx) {
+	data, err := client.EncodeTxFile(chainID, tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(path) == 0 {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = ioutil.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// writeMissingSignatures prints the addresses of a transfer's signers who
+// haven't signed it yet, and tells if there are any.
+func writeMissingSignatures(tx types.Tx) bool {
+	transferTx, ok := tx.(*types.TransferTx)
+	if !ok {
+		return false
+	}
+	missing := transferTx.MissingSignatures()
+	if len(missing) == 0 {
+		fmt.Fprintln(os.Stderr, "The transfer is fully signed")
+		return false
+	}
+	fmt.Fprintln(os.Stderr, "Missing signatures of:")
+	for _, addr := range missing {
+		fmt.Fprintln(os.Stderr, "", client.Encode(addr))
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+var (
+	flagUserAddress string
+	flagCanCreate   bool
+)
+
+func init() {
+	userCreateCmd.Flags().StringVar(&flagUserName, "name", "", "Name of the new user")
+	userCreateCmd.Flags().StringVar(&flagPubKey, "pub-key", "", "Public key of the new user, base64 encoded, or the name of a key of the keystore")
+	userCreateCmd.Flags().BoolVar(&flagCanCreate, "can-create", false, "Allow the new user to create users and legal entities")
+	userShowCmd.Flags().StringVar(&flagUserAddress, "address", "", "Address of the user, base64 encoded, or the name of a key of the keystore")
+	userListCmd.Flags().StringVar(&flagEntityID, "entity-id", "", "Only list the users of this legal entity")
+	userCmd.AddCommand(userCreateCmd, userShowCmd, userListCmd)
+	RootCmd.AddCommand(userCmd)
+}
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "Create or query the ledger's users",
+}
+
+var userCreateCmd = &cobra.Command{
+	Use:   "create [chainID serverAddress privateKey name publicKey]",
+	Short: "Create a user of the signer's legal entity, with the signer's permissions",
+	Long: `Create a user of the signer's legal entity, with the signer's permissions.
+
+The new user can only create users and legal entities if --can-create is set.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		chainID, serverAddress, privateKeyParam := flagChainID, flagNode, flagKey
+		name, pubKeyParam := flagUserName, flagPubKey
+
+		if len(args) == 5 {
+			//ledgerctl user create test_chain_id 127.0.0.1:46657 admin trader bob --can-create
+			chainID, serverAddress, privateKeyParam = args[0], args[1], args[2]
+			name, pubKeyParam = args[3], args[4]
+		}
+		signer := mustConnectSigner(chainID, serverAddress, privateKeyParam)
+		name = param(name, "name", "username")
+		pubKey := mustPubKey(param(pubKeyParam, "pub-key", "public Key"))
+
+		client.CreateUser(signer, name, pubKey, flagCanCreate)
+		writeUser(client.GetUser(pubKey.Address()))
+	},
+}
+
+var userShowCmd = &cobra.Command{
+	Use:   "show [serverAddress address]",
+	Short: "Show a user by address",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress, addressParam := flagNode, flagUserAddress
+
+		if len(args) == 2 {
+			//ledgerctl user show 127.0.0.1:46657 bob
+			serverAddress, addressParam = args[0], args[1]
+		}
+		mustConnect(serverAddress)
+		addr := mustAddress(param(addressParam, "address", "address"))
+
+		writeUser(client.GetUser(addr))
+	},
+}
+
+var userListCmd = &cobra.Command{
+	Use:   "list [serverAddress]",
+	Short: "List the users",
+	Run: func(cmd *cobra.Command, args []string) {
+		serverAddress := flagNode
+		if len(args) == 1 {
+			//ledgerctl user list 127.0.0.1:46657 --entity-id b40cbf4e-5923-4ccd-beec-e22a9117b91b
+			serverAddress = args[0]
+		}
+		mustConnect(serverAddress)
+
+		params := url.Values{}
+		if len(flagEntityID) > 0 {
+			params.Set("entity_id", flagEntityID)
+		}
+		users := []userOutput{}
+		for {
+			page := client.ListUsers(params)
+			for _, u := range page.Users {
+				users = append(users, newUserOutput(u))
+			}
+			if len(page.Next) == 0 {
+				break
+			}
+			params.Set("cursor", page.Next)
+		}
+		writeOutput(users, func(w io.Writer) error {
+			return writeUsersTable(w, users)
+		})
+	},
+}
+
+// mustPubKey returns the public key of a key of the keystore, or else
+// decodes a base64 encoded public key.
+func mustPubKey(param string) crypto.PubKey {
+	if client.ValidateKeyName(param) == nil {
+		key, err := mustOpenKeystore().Get(param)
+		if err != nil {
+			log.Fatalf("Key %q: %v", param, err)
+		}
+		return key.PubKey
+	}
+	pubKey, err := crypto.PubKeyFromBytes(client.Decode(param))
+	if err != nil {
+		log.Fatalf("Invalid public key: %v", err)
+	}
+	return pubKey
+}
+
+// userOutput describes a user, with its address and public key base64
+// encoded like the keystore's.
+type userOutput struct {
+	Address     string     `json:"address"`
+	PubKey      string     `json:"pub_key"`
+	Name        string     `json:"name"`
+	EntityID    string     `json:"entity_id"`
+	Permissions types.Perm `json:"permissions"`
+	Nonce       uint64     `json:"nonce"`
+}
+
+func newUserOutput(u *types.User) userOutput {
+	return userOutput{
+		Address:     client.Encode(u.PubKey.Address()),
+		PubKey:      client.Encode(u.PubKey.Bytes()),
+		Name:        u.Name,
+		EntityID:    u.EntityID,
+		Permissions: u.Permissions,
+		Nonce:       u.Nonce,
+	}
+}
+
+// writeUser prints the user queried by client.GetUser.
+func writeUser(returned types.UsersReturned) {
+	if len(returned.Users) != 1 {
+		log.Fatalf("Expected one user, got %d", len(returned.Users))
+	}
+	users := []userOutput{newUserOutput(returned.Users[0])}
+	writeOutput(users[0], func(w io.Writer) error {
+		return writeUsersTable(w, users)
+	})
+}
+
+func writeUsersTable(w io.Writer, users []userOutput) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tNAME\tENTITY\tPERMISSIONS\tNONCE")
+	for _, u := range users {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\n", u.Address, u.Name, u.EntityID, u.Permissions, u.Nonce)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+)
+
+func init() {
+	RootCmd.AddCommand(&cobra.Command{
+		Use:   "verify [serverAddress]",
+		Short: "Verify that the sum of all wallet balances equals the supply of every currency",
+		Run: func(cmd *cobra.Command, args []string) {
+			serverAddress := flagNode
+			if len(args) == 1 {
+				//ledgerctl verify 127.0.0.1:46657
+				serverAddress = args[0]
+			}
+			serverAddress = param(serverAddress, "node", "serverAddress")
+
+			client.StartClient(serverAddress)
+			report := client.GetSupply()
+
+			writeOutput(report, func(w io.Writer) error {
+				balances := make(map[string]types.Balance, len(report.Balances))
+				for _, b := range report.Balances {
+					balances[b.Currency] = b
+				}
+				tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+				fmt.Fprintln(tw, "CURRENCY\tSUPPLY\tBALANCES")
+				for _, s := range report.Supply {
+					fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Currency, displayAmount(s), displayAmount(balances[s.Currency]))
+				}
+				return tw.Flush()
+			})
+
+			if len(report.Violations) > 0 {
+				for _, v := range report.Violations {
+					fmt.Fprintln(os.Stderr, "invariant violated: "+v)
+				}
+				os.Exit(1)
+			}
+			if flagOutput != outputJSON {
+				fmt.Println("OK: money is conserved")
+			}
+		},
+	})
+}
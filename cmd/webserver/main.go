@@ -1,28 +1,37 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
+	"net/url"
+	"os"
 
 	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/rest"
 	"github.com/tendermint/clearchain/types"
-	"github.com/tendermint/go-crypto"
 	"github.com/gorilla/mux"
 )
 
 var serverAddress = "127.0.0.1:46657"
 var chainID = "test_chain_id"
-var privateKeyInBase64 = "ATRXWwlJ6bvNRcNRT/EMmymjZvAGsLZp5a95t9HL5NRhhDh4uTLuSQikLSS//AOeuN+s1DQMgzQjEGgglAR/r6s="
+var keyFile string
+var signerSocket string
 
-var privateKey crypto.PrivKey
+// signer signs the webserver's transactions, nil if neither a key
+// file nor a remote signer is configured
+var signer client.Signer
 
 const (
 	ServerAddressKey = "serverAddress"
 	ChainIDKey       = "chainID"
-	PrivateKey       = "privateKey"
+	KeyFileKey       = "keyFile"
+	SignerSocketKey  = "signerSocket"
 	Help             = "help"
+
+	// PassphraseEnv is the environment variable holding the key file's passphrase
+	PassphraseEnv = "WEBSERVER_PASSPHRASE"
 )
 
 
@@ -35,7 +44,7 @@ func (s *WebServer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
         rw.Header().Set("Access-Control-Allow-Origin", origin)
         rw.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
         rw.Header().Set("Access-Control-Allow-Headers",
-            "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+            "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, "+rest.DateHeader+", "+rest.NonceHeader)
     }
     // Stop here if its Preflighted OPTIONS request
     if req.Method == "OPTIONS" {
@@ -51,13 +60,14 @@ func main() {
 	client.SetChainID(chainID)
 	client.StartClient(serverAddress)
 
-	startWebserver()
+	startWebserver(client.New(serverAddress, client.WithChainID(chainID), client.WithSigner(signer)))
 }
 
 func handleCommandLine() {
 	flag.String(ServerAddressKey, "", "TMSP address to Tendermint server")
 	flag.String(ChainIDKey, "", "Blockchain ID")
-	flag.String(PrivateKey, "", "Base64 encoded privateKey for message signing")
+	flag.String(KeyFileKey, "", "Encrypted key file for message signing, its passphrase is read from "+PassphraseEnv)
+	flag.String(SignerSocketKey, "", "Unix socket of a remote signer for message signing")
 	flag.Bool(Help, false, "Prints command line argument usage")
 
 	flag.Parse()
@@ -68,9 +78,14 @@ func handleCommandLine() {
 	flag.Visit(flagHandler)
 
 	var err error
-	privateKey, err = crypto.PrivKeyFromBytes(client.Decode(privateKeyInBase64))
+	switch {
+	case len(keyFile) != 0:
+		signer, err = client.NewKeyFileSigner(keyFile, []byte(os.Getenv(PassphraseEnv)))
+	case len(signerSocket) != 0:
+		signer, err = client.NewRemoteSigner(signerSocket)
+	}
 	if err != nil {
-		panic(fmt.Sprintf("Error during building privateKey from %v, %v", privateKeyInBase64, err))
+		panic(fmt.Sprintf("Error during building the signer: %v", err))
 	}
 }
 
@@ -84,8 +99,11 @@ func flagHandler(currentFlag *flag.Flag) {
 	case ChainIDKey:
 		chainID = currentFlag.Value.String()
 		return
-	case PrivateKey:
-		privateKeyInBase64 = currentFlag.Value.String()
+	case KeyFileKey:
+		keyFile = currentFlag.Value.String()
+		return
+	case SignerSocketKey:
+		signerSocket = currentFlag.Value.String()
 		return
 	case Help:
 		flag.Usage()
@@ -96,10 +114,21 @@ func flagHandler(currentFlag *flag.Flag) {
 }
 
 
-func startWebserver() {
-	
+// startWebserver serves the view and the REST API of the ledger, whose
+// Txs are signed by its client's signer. Both only answer the requests
+// signed by users of the ledger. Dashboards may subscribe to the changes
+// of accounts at /events instead of polling the view. Its balance events
+// hold the balances of the accounts when they're sent, which may be those
+// of a later block than the event's height.
+func startWebserver(ledger rest.Ledger) {
+	// Stops following the ledger's blocks once the webserver stops
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	r := mux.NewRouter()
-    r.HandleFunc("/view/", viewHandler)
+	api := rest.New(ctx, ledger)
+    r.Handle("/view/", api.Handle(viewHandler(api, ledger)))
+	api.AddRoutes(r)
 	
 	http.Handle("/", &WebServer{r})
 
@@ -110,31 +139,52 @@ func defaultHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Unimplemented request for %s!", r.URL.Path[1:])
 }
 
-func viewHandler(w http.ResponseWriter, r *http.Request) {
-	//TODO: Security issue: No autentication, authorization is there to limit access to this code.
-
-	accountsRequested := client.GetAllAccounts().Accounts
-	accounts := make([]*types.Account, len(accountsRequested))
-	for index, account := range accountsRequested {
-		var accountsRes []*types.Account = client.GetAccount(account).Account
-		accounts[index] = accountsRes[0]
+// viewHandler lists the accounts and legal entities the caller may read,
+// as GET /accounts and GET /entities do.
+func viewHandler(api *rest.Server, ledger rest.Ledger) func(r *http.Request) (interface{}, error) {
+	return func(r *http.Request) (interface{}, error) {
+		accounts := []*types.Account{}
+		balances := map[string][]types.Balance{}
+		params := url.Values{"decimal": {"true"}}
+		if err := api.FilterByEntity(r, params, "entity_id"); err != nil {
+			return nil, err
+		}
+		for {
+			page, err := ledger.ListAccounts(r.Context(), params)
+			if err != nil {
+				return nil, err
+			}
+			accounts = append(accounts, page.Account...)
+			for id, b := range page.Balances {
+				balances[id] = b
+			}
+			if len(page.Next) == 0 {
+				break
+			}
+			params.Set("cursor", page.Next)
+		}
+
+		legalEntities := []*types.LegalEntity{}
+		params = url.Values{}
+		if err := api.FilterByEntity(r, params, "parent_id"); err != nil {
+			return nil, err
+		}
+		for {
+			page, err := ledger.ListLegalEntities(r.Context(), params)
+			if err != nil {
+				return nil, err
+			}
+			legalEntities = append(legalEntities, page.LegalEntities...)
+			if len(page.Next) == 0 {
+				break
+			}
+			params.Set("cursor", page.Next)
+		}
+
+		return struct {
+			LegalEntities []*types.LegalEntity       `json:"legalEntities"`
+			Account       []*types.Account           `json:"accounts"`
+			Balances      map[string][]types.Balance `json:"balances"` // Wallets' balances with decimal amounts, by account ID
+		}{legalEntities, accounts, balances}, nil
 	}
-
-	legalEntityIds := client.GetAllLegalEntities().Ids
-	legalEntities := make([]*types.LegalEntity, len(legalEntityIds))
-	for index, legalEntityID := range legalEntityIds {
-		legalEntitiesRes := client.GetLegalEntity(legalEntityID).LegalEntities
-		legalEntities[index] = legalEntitiesRes[0]
-	}
-	
-	jsonBytes, err := json.Marshal(struct {
-		LegalEntities []*types.LegalEntity `json:"legalEntities"`
-		Account       []*types.Account     `json:"accounts"`
-	}{legalEntities, accounts})
-
-	if err != nil {
-		panic(fmt.Sprintf("JSON marshalling error for message: %v, %v", accounts, err))
-	}
-
-	w.Write(jsonBytes)
 }
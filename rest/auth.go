@@ -0,0 +1,408 @@
+package rest
+
+import (
+	"bytes"
+	"container/heap"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/satori/go.uuid"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// Requests are authenticated by the signature of a ledger user, verified
+// against the user's public key on the ledger: the webserver holds no keys
+// or secrets of its callers.
+const (
+	// AuthScheme is the scheme of the Authorization header of a signed
+	// request, whose credentials are the hex encoded address of the user and
+	// the base64 encoded signature of SignBytes: Ledger <address>:<signature>
+	AuthScheme = "Ledger"
+
+	// DateHeader holds the time a request was signed at, in the format of
+	// http.TimeFormat. It isn't the Date header, which browsers don't let
+	// scripts set.
+	DateHeader = "X-Ledger-Date"
+
+	// NonceHeader holds a value unique to a request, e.g. a random UUID,
+	// which must be set on the requests other than GETs. As signatures are
+	// deterministic and dates have a resolution of a second, it tells two
+	// identical requests signed in the same second apart from a replay.
+	NonceHeader = "X-Ledger-Nonce"
+
+	// MaxClockSkew bounds the difference between the time a request was
+	// signed at and the webserver's time.
+	MaxClockSkew = 5 * time.Minute
+
+	// MaxBodyBytes bounds the size of the body of a request, which is read
+	// before the request is authenticated. It's far above that of a Tx.
+	MaxBodyBytes = 64 << 10
+)
+
+// SignBytes returns the bytes signed to authenticate a request: its method,
+// its request URI, e.g. /accounts?limit=10, its date, its nonce, empty if
+// none, and the hex encoded SHA-256 hash of its body, one per line.
+func SignBytes(method string, uri string, date string, nonce string, body []byte) []byte {
+	hash := sha256.Sum256(body)
+	return []byte(fmt.Sprintf("%s\n%s\n%s\n%s\n%X", method, uri, date, nonce, hash))
+}
+
+// SignRequest signs a request with the key of a ledger user, setting a
+// random nonce. body must be the request's body, nil if none.
+func SignRequest(r *http.Request, body []byte, signer client.Signer) error {
+	date := time.Now().UTC().Format(http.TimeFormat)
+	nonce := uuid.NewV4().String()
+	sig, err := signer.Sign(SignBytes(r.Method, r.URL.RequestURI(), date, nonce, body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set(DateHeader, date)
+	r.Header.Set(NonceHeader, nonce)
+	r.Header.Set("Authorization", fmt.Sprintf("%s %X:%s", AuthScheme, signer.Address(),
+		base64.StdEncoding.EncodeToString(sig.Bytes())))
+	return nil
+}
+
+// caller is the authenticated user of a request.
+type caller struct {
+	address []byte
+	user    *types.User
+}
+
+type callerKey struct{}
+
+// callerOf returns the authenticated user of a request.
+func callerOf(r *http.Request) *caller {
+	c, _ := r.Context().Value(callerKey{}).(*caller)
+	return c
+}
+
+// Authenticated only lets the requests signed by an enabled ledger user
+// through to h.
+func (s *Server) Authenticated(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := s.authenticate(w, r)
+		if err != nil {
+			writeError(w, toError(err))
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), callerKey{}, c)))
+	})
+}
+
+// authenticate verifies the signature of a request, then returns its
+// user, which must be enabled. The body of the request, of at most
+// MaxBodyBytes, is read and replaced by a copy.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) (*caller, error) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, AuthScheme+" ") {
+		return nil, unauthorized("Missing %s authorization", AuthScheme)
+	}
+	credentials := strings.SplitN(strings.TrimPrefix(auth, AuthScheme+" "), ":", 2)
+	if len(credentials) != 2 {
+		return nil, unauthorized("Invalid authorization, expected %s <address>:<signature>", AuthScheme)
+	}
+	address, err := hex.DecodeString(credentials[0])
+	if err != nil {
+		return nil, unauthorized("Invalid address: %q", credentials[0])
+	}
+	sigBytes, err := base64.StdEncoding.DecodeString(credentials[1])
+	if err != nil {
+		return nil, unauthorized("Invalid signature: %v", err)
+	}
+	sig, err := crypto.SignatureFromBytes(sigBytes)
+	if err != nil {
+		return nil, unauthorized("Invalid signature: %v", err)
+	}
+
+	date := r.Header.Get(DateHeader)
+	signedAt, err := http.ParseTime(date)
+	if err != nil {
+		return nil, unauthorized("Missing or invalid %s", DateHeader)
+	}
+	if skew := s.now().Sub(signedAt); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return nil, unauthorized("Request signed at %s, more than %v away from the webserver's time", date, MaxClockSkew)
+	}
+	nonce := r.Header.Get(NonceHeader)
+	if len(nonce) == 0 && r.Method != http.MethodGet {
+		return nil, unauthorized("Missing %s", NonceHeader)
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	if err != nil && len(body) == MaxBodyBytes {
+		return nil, tooLarge("The body exceeds %d bytes", MaxBodyBytes)
+	} else if err != nil {
+		return nil, badRequest("Couldn't read the body: %v", err)
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	user, err := s.getUser(r.Context(), address)
+	if client.IsNotFound(err) {
+		return nil, unauthorized("Unknown user: %X", address)
+	} else if err != nil {
+		return nil, err
+	}
+	if !user.VerifySignature(SignBytes(r.Method, r.URL.RequestURI(), date, nonce, body), sig) {
+		return nil, unauthorized("Invalid signature")
+	}
+	// GETs are safe to replay, and may be signed twice in the same second
+	if r.Method != http.MethodGet && !s.signatures.add(sigBytes, signedAt, s.now()) {
+		return nil, unauthorized("Replayed request")
+	}
+	if user.Permissions == types.PermNone {
+		return nil, forbidden("User %X is disabled", address)
+	}
+	return &caller{address: address, user: user}, nil
+}
+
+// authorize checks that the caller may have a Tx sent by the ledger's
+// signer. As the ledger would if the caller signed the Tx, the caller and
+// its legal entity must both be allowed to send it. The signer must be a
+// user of the caller's legal entity, so that the Tx acts on that entity,
+// and the caller must have all of the signer's permissions, so that it
+// can't act with more rights than its own, e.g. by creating a user.
+func (s *Server) authorize(ctx context.Context, c *caller, txType byte) error {
+	if !c.user.CanExecTx(txType) {
+		return forbidden("User %X isn't allowed to send Txs of type %d", c.address, txType)
+	}
+	returned, err := s.ledger.GetLegalEntity(ctx, c.user.EntityID)
+	if err != nil {
+		return err
+	}
+	if len(returned.LegalEntities) != 1 || !returned.LegalEntities[0].CanExecTx(txType) {
+		return forbidden("Legal entity %s isn't allowed to send Txs of type %d", c.user.EntityID, txType)
+	}
+
+	signer := s.ledger.Signer()
+	if signer == nil {
+		return client.ErrNoSigner
+	}
+	signerUser, err := s.getUser(ctx, signer.Address())
+	if err != nil {
+		return err
+	}
+	if signerUser.EntityID != c.user.EntityID {
+		return forbidden("The webserver only sends Txs for legal entity %s", signerUser.EntityID)
+	}
+	if signerUser.Permissions.Clear(c.user.Permissions) != types.PermNone {
+		return forbidden("User %X doesn't have all the permissions of the webserver's signer, "+
+			"its Txs must be signed by itself and sent to POST /txs", c.address)
+	}
+	return nil
+}
+
+// readScope is the set of legal entities whose objects a caller may read:
+// its own legal entity and the latter's descendants, or all of them if
+// it's the clearing house. Its methods check that the caller may read an
+// object, they are called by the handlers of the read routes.
+type readScope struct {
+	ledger   Ledger
+	caller   *caller
+	all      bool              // Whether the caller's legal entity is the clearing house
+	entities map[string]bool   // Whether the legal entities looked up are in the scope
+	accounts map[string]string // Legal entities of the accounts looked up
+}
+
+func (s *Server) readScope(ctx context.Context, c *caller) (*readScope, error) {
+	returned, err := s.ledger.GetLegalEntity(ctx, c.user.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if len(returned.LegalEntities) != 1 {
+		return nil, fmt.Errorf("Expected one legal entity, got %d", len(returned.LegalEntities))
+	}
+	return &readScope{
+		ledger:   s.ledger,
+		caller:   c,
+		all:      returned.LegalEntities[0].Type == types.EntityTypeCHByte,
+		entities: map[string]bool{c.user.EntityID: true},
+		accounts: map[string]string{},
+	}, nil
+}
+
+// hasEntity returns whether the legal entity is the caller's or one of
+// its descendants, walking up the legal entity's ancestors.
+func (rs *readScope) hasEntity(ctx context.Context, id string) (bool, error) {
+	if rs.all {
+		return true, nil
+	}
+	walked := []string{}
+	in := false
+	for len(id) > 0 {
+		if known, ok := rs.entities[id]; ok {
+			in = known
+			break
+		}
+		returned, err := rs.ledger.GetLegalEntity(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if len(returned.LegalEntities) != 1 {
+			return false, fmt.Errorf("Expected one legal entity, got %d", len(returned.LegalEntities))
+		}
+		// Out of the scope until the walk ends, so that it ends on cycles
+		rs.entities[id] = false
+		walked = append(walked, id)
+		id = returned.LegalEntities[0].EntityID
+	}
+	for _, e := range walked {
+		rs.entities[e] = in
+	}
+	return in, nil
+}
+
+// checkEntity returns an error unless the caller may read the objects of
+// the legal entity.
+func (rs *readScope) checkEntity(ctx context.Context, id string) error {
+	in, err := rs.hasEntity(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !in {
+		return rs.forbidden()
+	}
+	return nil
+}
+
+// hasTransfer returns whether the sender's or the recipient's account of
+// the transfer is in the scope.
+func (rs *readScope) hasTransfer(ctx context.Context, t *types.Transfer) (bool, error) {
+	for _, id := range []string{t.SenderID, t.RecipientID} {
+		if in, err := rs.hasAccount(ctx, id); err != nil || in {
+			return in, err
+		}
+	}
+	return false, nil
+}
+
+// hasAccount returns whether the account's legal entity is in the scope.
+func (rs *readScope) hasAccount(ctx context.Context, id string) (bool, error) {
+	entityID, ok := rs.accounts[id]
+	if !ok {
+		returned, err := rs.ledger.GetAccount(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		if len(returned.Account) != 1 {
+			return false, fmt.Errorf("Expected one account, got %d", len(returned.Account))
+		}
+		entityID = returned.Account[0].EntityID
+		rs.accounts[id] = entityID
+	}
+	return rs.hasEntity(ctx, entityID)
+}
+
+// checkAccount returns an error unless the caller may read the account.
+func (rs *readScope) checkAccount(ctx context.Context, id string) error {
+	in, err := rs.hasAccount(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !in {
+		return rs.forbidden()
+	}
+	return nil
+}
+
+// checkUser returns an error unless the caller may read the user.
+func (rs *readScope) checkUser(ctx context.Context, addr []byte) error {
+	if rs.all || bytes.Equal(addr, rs.caller.address) {
+		return nil
+	}
+	returned, err := rs.ledger.GetUser(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if len(returned.Users) != 1 {
+		return fmt.Errorf("Expected one user, got %d", len(returned.Users))
+	}
+	return rs.checkEntity(ctx, returned.Users[0].EntityID)
+}
+
+func (rs *readScope) forbidden() error {
+	return forbidden("User %X may only read the objects of legal entity %s and its descendants",
+		rs.caller.address, rs.caller.user.EntityID)
+}
+
+// filterEntity returns the legal entity a list is filtered by, the
+// caller's if id is empty and the caller may not read all the objects.
+func (rs *readScope) filterEntity(ctx context.Context, id string) (string, error) {
+	if len(id) == 0 {
+		if rs.all {
+			return "", nil
+		}
+		return rs.caller.user.EntityID, nil
+	}
+	return id, rs.checkEntity(ctx, id)
+}
+
+func (s *Server) getUser(ctx context.Context, addr []byte) (*types.User, error) {
+	returned, err := s.ledger.GetUser(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(returned.Users) != 1 {
+		return nil, fmt.Errorf("Expected one user, got %d", len(returned.Users))
+	}
+	return returned.Users[0], nil
+}
+
+// signatureCache remembers the signatures of requests until they are too
+// old to be accepted, so that requests can't be replayed.
+type signatureCache struct {
+	mu     sync.Mutex
+	seen   map[string]bool
+	expiry signatureHeap // Seen signatures, the oldest signed first
+}
+
+// add returns false if sig was already seen, otherwise remembers it and
+// forgets the expired signatures.
+func (c *signatureCache) add(sig []byte, signedAt time.Time, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.seen == nil {
+		c.seen = map[string]bool{}
+	}
+	for len(c.expiry) > 0 && now.Sub(c.expiry[0].signedAt) > MaxClockSkew {
+		delete(c.seen, heap.Pop(&c.expiry).(seenSignature).sig)
+	}
+	if c.seen[string(sig)] {
+		return false
+	}
+	c.seen[string(sig)] = true
+	heap.Push(&c.expiry, seenSignature{sig: string(sig), signedAt: signedAt})
+	return true
+}
+
+type seenSignature struct {
+	sig      string
+	signedAt time.Time
+}
+
+// signatureHeap implements heap.Interface, ordered by signing time.
+type signatureHeap []seenSignature
+
+func (h signatureHeap) Len() int           { return len(h) }
+func (h signatureHeap) Less(i, j int) bool { return h[i].signedAt.Before(h[j].signedAt) }
+func (h signatureHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *signatureHeap) Push(x interface{}) {
+	*h = append(*h, x.(seenSignature))
+}
+
+func (h *signatureHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
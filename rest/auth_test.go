@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+func TestServer_authentication(t *testing.T) {
+	newSigner := func() client.Signer { return client.NewKeySigner(crypto.GenPrivKeyEd25519()) }
+	trader, stranger, disabled, readOnly, foreign, clerk := newSigner(), newSigner(), newSigner(), newSigner(), newSigner(), newSigner()
+	txFile, err := client.EncodeTxFile("test_chain_id", &types.CreateAccountTx{Address: trader.Address(), Nonce: 1, AccountID: "x"})
+	if err != nil {
+		t.Fatalf("EncodeTxFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		signer      client.Signer
+		tamper      func(r *http.Request) // Applied to the signed request
+		skew        time.Duration         // Of the webserver's clock
+		wantStatus  int
+		wantMessage string // Prefix of the error message
+	}{
+		{"signed", "GET", "/accounts/a", "", trader, nil, 0, http.StatusOK, ""},
+		{"unsigned", "GET", "/accounts/a", "", nil, nil, 0, http.StatusUnauthorized, "Missing Ledger authorization"},
+		{"spec", "GET", SpecPath, "", nil, nil, 0, http.StatusOK, ""},
+		{"invalidAuthorization", "GET", "/accounts/a", "", trader,
+			func(r *http.Request) { r.Header.Set("Authorization", "Ledger xyz") }, 0, http.StatusUnauthorized, "Invalid authorization"},
+		{"unknownUser", "GET", "/accounts/a", "", stranger, nil, 0, http.StatusUnauthorized, "Unknown user"},
+		{"tamperedBody", "POST", "/accounts", `{"account_id": "a"}`, trader,
+			func(r *http.Request) { r.Body = ioutil.NopCloser(strings.NewReader(`{"account_id": "b"}`)) },
+			0, http.StatusUnauthorized, "Invalid signature"},
+		{"tamperedDate", "GET", "/accounts/a", "", trader,
+			func(r *http.Request) {
+				r.Header.Set(DateHeader, time.Now().UTC().Add(time.Minute).Format(http.TimeFormat))
+			},
+			0, http.StatusUnauthorized, "Invalid signature"},
+		{"tamperedNonce", "POST", "/accounts", `{"account_id": "a"}`, trader,
+			func(r *http.Request) { r.Header.Set(NonceHeader, "x") }, 0, http.StatusUnauthorized, "Invalid signature"},
+		{"missingNonce", "POST", "/accounts", `{"account_id": "a"}`, trader,
+			func(r *http.Request) { r.Header.Del(NonceHeader) }, 0, http.StatusUnauthorized, "Missing " + NonceHeader},
+		{"clockSkew", "GET", "/accounts/a", "", trader, nil, 10 * time.Minute, http.StatusUnauthorized, "Request signed at"},
+		{"disabledUser", "GET", "/accounts/a", "", disabled, nil, 0, http.StatusForbidden, "User"},
+		{"readOnlyRead", "GET", "/accounts/a", "", readOnly, nil, 0, http.StatusOK, ""},
+		{"readOnlyWrite", "POST", "/accounts", "", readOnly, nil, 0, http.StatusForbidden, "User"},
+		{"lesserPermissionsWrite", "POST", "/users", "", clerk, nil, 0, http.StatusForbidden, "User"},
+		{"foreignRead", "GET", "/accounts/a", "", foreign, nil, 0, http.StatusForbidden, "User"},
+		{"foreignWrite", "POST", "/accounts", "", foreign, nil, 0, http.StatusForbidden,
+			"The webserver only sends Txs for legal entity e"},
+		{"broadcastOwnTx", "POST", "/txs", string(txFile), trader, nil, 0, http.StatusOK, ""},
+		{"broadcastOthersTx", "POST", "/txs", string(txFile), foreign, nil, 0, http.StatusForbidden, "The Tx is signed by user"},
+		{"largeBody", "POST", "/txs", strings.Repeat(" ", MaxBodyBytes+1), trader, nil, 0, http.StatusRequestEntityTooLarge, "The body exceeds"},
+	}
+	for _, tt := range tests {
+		ledger := newFakeLedger(trader)
+		ledger.addUser(disabled, "e", types.PermNone)
+		ledger.addUser(readOnly, "e", types.PermTransferTx)
+		ledger.addUser(clerk, "e", types.NewPermByTxType(types.TxTypeCreateAccount, types.TxTypeCreateUser))
+		ledger.entities["f"] = &types.LegalEntity{ID: "f", Permissions: allPerms}
+		ledger.addUser(foreign, "f", allPerms)
+		s := New(context.Background(), ledger)
+		s.now = func() time.Time { return time.Now().Add(tt.skew) }
+
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		if tt.signer != nil {
+			if err := SignRequest(req, []byte(tt.body), tt.signer); err != nil {
+				t.Fatalf("%q. SignRequest() error = %v", tt.name, err)
+			}
+		}
+		if tt.tamper != nil {
+			tt.tamper(req)
+		}
+		r := mux.NewRouter()
+		s.AddRoutes(r)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus || (len(tt.wantMessage) > 0 && !strings.Contains(rec.Body.String(), `"message":"`+tt.wantMessage)) {
+			t.Errorf("%q. response = %d %s, want %d %q", tt.name, rec.Code, rec.Body, tt.wantStatus, tt.wantMessage)
+		}
+		if tt.wantStatus == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") != AuthScheme {
+			t.Errorf("%q. WWW-Authenticate = %q, want %q", tt.name, rec.Header().Get("WWW-Authenticate"), AuthScheme)
+		}
+	}
+}
+
+func TestServer_replay(t *testing.T) {
+	trader := client.NewKeySigner(crypto.GenPrivKeyEd25519())
+	r := mux.NewRouter()
+	New(context.Background(), newFakeLedger(trader)).AddRoutes(r)
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int // Of the replayed request
+	}{
+		{"GET", "/accounts/a", http.StatusOK},
+		{"POST", "/accounts", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		signed := httptest.NewRequest(tt.method, tt.path, nil)
+		if err := SignRequest(signed, nil, trader); err != nil {
+			t.Fatalf("SignRequest() error = %v", err)
+		}
+		statuses := []int{}
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header = signed.Header
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			statuses = append(statuses, rec.Code)
+		}
+		if statuses[1] != tt.wantStatus {
+			t.Errorf("%s %s replayed = %v, want %d", tt.method, tt.path, statuses, tt.wantStatus)
+		}
+	}
+
+	// Identical requests signed in the same second differ by their nonce
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/accounts", nil)
+		if err := SignRequest(req, nil, trader); err != nil {
+			t.Fatalf("SignRequest() error = %v", err)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code == http.StatusUnauthorized {
+			t.Errorf("identical POST #%d = %d %s, want it accepted", i+1, rec.Code, rec.Body)
+		}
+	}
+}
+
+func Test_signatureCache_add(t *testing.T) {
+	start := time.Now()
+	var c signatureCache
+	tests := []struct {
+		name     string
+		sig      string
+		signedAt time.Duration // From start
+		now      time.Duration // From start
+		want     bool
+		wantSeen int // Signatures remembered afterwards
+	}{
+		{"first", "a", 0, 0, true, 1},
+		{"replay", "a", 0, time.Second, false, 1},
+		{"signedEarlier", "b", -time.Minute, time.Second, true, 2},
+		{"signedLater", "c", time.Minute, time.Second, true, 3},
+		{"expireEarliest", "d", MaxClockSkew, MaxClockSkew + time.Second, true, 2},
+		{"expireAll", "a", 2*MaxClockSkew + 2*time.Minute, 2*MaxClockSkew + 2*time.Minute, true, 1},
+	}
+	for _, tt := range tests {
+		if got := c.add([]byte(tt.sig), start.Add(tt.signedAt), start.Add(tt.now)); got != tt.want {
+			t.Errorf("%q. signatureCache.add() = %v, want %v", tt.name, got, tt.want)
+		}
+		if len(c.seen) != tt.wantSeen || len(c.expiry) != tt.wantSeen {
+			t.Errorf("%q. signatureCache remembers %d, %d signatures, want %d", tt.name, len(c.seen), len(c.expiry), tt.wantSeen)
+		}
+	}
+}
+
+func TestServer_readScope(t *testing.T) {
+	newSigner := func() client.Signer { return client.NewKeySigner(crypto.GenPrivKeyEd25519()) }
+	trader, foreign, clearer := newSigner(), newSigner(), newSigner()
+	ledger := newFakeLedger(trader)
+	ledger.entities["d"] = &types.LegalEntity{ID: "d", EntityID: "e", Type: types.EntityTypeICMByte, Permissions: allPerms}
+	ledger.entities["f"] = &types.LegalEntity{ID: "f", EntityID: "ch", Type: types.EntityTypeGCMByte, Permissions: allPerms}
+	ledger.accounts["b"] = &types.Account{ID: "b", EntityID: "d"}
+	ledger.accounts["c"] = &types.Account{ID: "c", EntityID: "f"}
+	ledger.addUser(foreign, "f", allPerms)
+	ledger.addUser(clearer, "ch", allPerms)
+	ledger.transfers = []*types.Transfer{
+		{ID: "t1", SenderID: "a", RecipientID: "c", Amount: 10, Currency: "EUR"},
+		{ID: "t2", SenderID: "c", RecipientID: "c", Amount: 20, Currency: "EUR"},
+		{ID: "t3", SenderID: "c", RecipientID: "b", Amount: 30, Currency: "EUR"},
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		signer        client.Signer
+		wantStatus    int
+		wantParams    url.Values // Of the ledger's list query, nil if not checked
+		wantTransfers int        // Number of transfers listed by GET /transfers
+	}{
+		{"ownAccount", "/accounts/a", trader, http.StatusOK, nil, 0},
+		{"descendantAccount", "/accounts/b", trader, http.StatusOK, nil, 0},
+		{"othersAccount", "/accounts/c", trader, http.StatusForbidden, nil, 0},
+		{"ancestorsAccount", "/accounts/a", foreign, http.StatusForbidden, nil, 0},
+		{"clearingHouseAccount", "/accounts/c", clearer, http.StatusOK, nil, 0},
+		{"listOwnAccounts", "/accounts", trader, http.StatusOK, url.Values{"entity_id": {"e"}, "decimal": {"true"}}, 0},
+		{"listDescendantAccounts", "/accounts?entity=d", trader, http.StatusOK, url.Values{"entity_id": {"d"}, "decimal": {"true"}}, 0},
+		{"listOthersAccounts", "/accounts?entity=f", trader, http.StatusForbidden, nil, 0},
+		{"listAllAccounts", "/accounts", clearer, http.StatusOK, url.Values{"decimal": {"true"}}, 0},
+		{"descendantBalances", "/entities/d/balances", trader, http.StatusOK, nil, 0},
+		{"ancestorBalances", "/entities/ch/balances", trader, http.StatusForbidden, nil, 0},
+		{"othersUser", fmt.Sprintf("/users/%X", foreign.Address()), trader, http.StatusForbidden, nil, 0},
+		{"ownTransfers", "/transfers?limit=3", trader, http.StatusOK, nil, 2},
+		{"allTransfers", "/transfers?limit=3", clearer, http.StatusOK, nil, 3},
+		{"othersEvents", "/events?accounts=a,c", trader, http.StatusForbidden, nil, 0},
+	}
+	for _, tt := range tests {
+		ledger.params = nil
+		rec := serve(t, New(context.Background(), ledger), "GET", tt.path, "", tt.signer)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%q. status = %d, want %d, body %s", tt.name, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		if tt.wantParams != nil && !reflect.DeepEqual(ledger.params, tt.wantParams) {
+			t.Errorf("%q. params = %v, want %v", tt.name, ledger.params, tt.wantParams)
+		}
+		if tt.wantTransfers > 0 {
+			var returned types.TransfersReturned
+			if err := json.Unmarshal(rec.Body.Bytes(), &returned); err != nil || len(returned.Transfers) != tt.wantTransfers {
+				t.Errorf("%q. body = %s, want %d transfers", tt.name, rec.Body, tt.wantTransfers)
+			}
+		}
+	}
+}
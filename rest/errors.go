@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/client"
+)
+
+// ErrorResponse is the body of every response with an error status.
+type ErrorResponse struct {
+	Code    string `json:"code"`    // ABCI result code of a ledger error, e.g. BaseUnknownAddress, otherwise the status text
+	Message string `json:"message"` // Log of a ledger error, otherwise a description of the error
+}
+
+// Error is an error with the HTTP status it's answered with.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// badRequest returns an Error of a request the webserver can't handle.
+func badRequest(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: http.StatusText(http.StatusBadRequest), Message: fmt.Sprintf(format, args...)}
+}
+
+// unauthorized returns an Error of a request which isn't signed by a user
+// of the ledger.
+func unauthorized(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusUnauthorized, Code: http.StatusText(http.StatusUnauthorized), Message: fmt.Sprintf(format, args...)}
+}
+
+// forbidden returns an Error of a request its user isn't allowed to make.
+func forbidden(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusForbidden, Code: http.StatusText(http.StatusForbidden), Message: fmt.Sprintf(format, args...)}
+}
+
+// tooLarge returns an Error of a request whose body is too large.
+func tooLarge(format string, args ...interface{}) *Error {
+	return &Error{Status: http.StatusRequestEntityTooLarge, Code: http.StatusText(http.StatusRequestEntityTooLarge), Message: fmt.Sprintf(format, args...)}
+}
+
+// codeStatuses maps the ABCI result codes the ledger answers with to HTTP
+// statuses. Other codes are internal errors.
+var codeStatuses = map[abci.CodeType]int{
+	abci.CodeType_OK:                    http.StatusOK,
+	abci.CodeType_UnknownRequest:        http.StatusBadRequest,
+	abci.CodeType_BaseEncodingError:     http.StatusBadRequest,
+	abci.CodeType_BaseInvalidInput:      http.StatusBadRequest,
+	abci.CodeType_BaseInvalidOutput:     http.StatusBadRequest,
+	abci.CodeType_BaseInvalidPubKey:     http.StatusBadRequest,
+	abci.CodeType_Unauthorized:          http.StatusForbidden,
+	abci.CodeType_BaseInvalidSignature:  http.StatusForbidden,
+	abci.CodeType_BaseUnknownAddress:    http.StatusNotFound,
+	abci.CodeType_BaseDuplicateAddress:  http.StatusConflict,
+	abci.CodeType_BaseInvalidSequence:   http.StatusConflict,
+	abci.CodeType_BaseInsufficientFunds: http.StatusUnprocessableEntity,
+}
+
+// toError maps an error of the ledger's client to an Error: the ledger's
+// result codes to their statuses, failures to reach the ledger to 502 Bad
+// Gateway or 504 Gateway Timeout.
+func toError(err error) *Error {
+	switch e := err.(type) {
+	case *Error:
+		return e
+	case *client.Error:
+		status, ok := codeStatuses[e.Code]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		return &Error{Status: status, Code: e.Code.String(), Message: e.Log}
+	}
+	status := http.StatusBadGateway
+	switch err {
+	case client.ErrNoSigner:
+		status = http.StatusServiceUnavailable
+	case context.DeadlineExceeded:
+		status = http.StatusGatewayTimeout
+	}
+	return &Error{Status: status, Code: http.StatusText(status), Message: err.Error()}
+}
@@ -0,0 +1,44 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/client"
+)
+
+func Test_toError(t *testing.T) {
+	invalid := badRequest("Invalid address: %q", "xyz")
+	tests := []struct {
+		name string
+		err  error
+		want *Error
+	}{
+		{"notFound", &client.Error{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown user"},
+			&Error{Status: http.StatusNotFound, Code: "BaseUnknownAddress", Message: "Unknown user"}},
+		{"invalidInput", &client.Error{Code: abci.CodeType_BaseInvalidInput, Log: "Invalid cursor"},
+			&Error{Status: http.StatusBadRequest, Code: "BaseInvalidInput", Message: "Invalid cursor"}},
+		{"unauthorized", &client.Error{Code: abci.CodeType_Unauthorized, Log: "Not allowed"},
+			&Error{Status: http.StatusForbidden, Code: "Unauthorized", Message: "Not allowed"}},
+		{"invalidSequence", &client.Error{Code: abci.CodeType_BaseInvalidSequence, Log: "Invalid nonce"},
+			&Error{Status: http.StatusConflict, Code: "BaseInvalidSequence", Message: "Invalid nonce"}},
+		{"internalError", &client.Error{Code: abci.CodeType_InternalError, Log: "Oops"},
+			&Error{Status: http.StatusInternalServerError, Code: "InternalError", Message: "Oops"}},
+		{"noSigner", client.ErrNoSigner,
+			&Error{Status: http.StatusServiceUnavailable, Code: "Service Unavailable", Message: client.ErrNoSigner.Error()}},
+		{"timeout", context.DeadlineExceeded,
+			&Error{Status: http.StatusGatewayTimeout, Code: "Gateway Timeout", Message: context.DeadlineExceeded.Error()}},
+		{"unreachable", errors.New("connection refused"),
+			&Error{Status: http.StatusBadGateway, Code: "Bad Gateway", Message: "connection refused"}},
+		{"badRequest", invalid, invalid},
+	}
+	for _, tt := range tests {
+		if got := toError(tt.err); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. toError() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
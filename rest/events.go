@@ -0,0 +1,470 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-logger"
+)
+
+var log = logger.New("module", "rest")
+
+// DefaultEventRetryInterval is how long the webserver waits before
+// subscribing again to the ledger's new blocks, once the subscription
+// failed.
+const DefaultEventRetryInterval = 5 * time.Second
+
+// MaxEventAccounts bounds the number of accounts of a stream of events.
+const MaxEventAccounts = 100
+
+// eventBuffer is the number of blocks a stream of events may lag behind
+// before it's ended, its client resuming from its last event.
+const eventBuffer = 16
+
+// Types of the events of GET /events
+const (
+	EventTypeTransfer = "transfer"
+	EventTypeBalance  = "balance"
+	EventTypeBlock    = "block"
+)
+
+// Event is the data of a server-sent event of GET /events, whose event
+// type is the same as Type. The events of a block are sent in order:
+// first the transfers from or to the stream's accounts, then the balances
+// of the accounts which changed, and last the block event, whose event ID
+// is the height of the block.
+type Event struct {
+	Type      string          `json:"type" doc:"transfer, balance or block"`
+	Height    uint64          `json:"height" doc:"Height of the block"`
+	Transfer  *types.Transfer `json:"transfer,omitempty" doc:"The transfer of a transfer event"`
+	AccountID string          `json:"account_id,omitempty" doc:"ID of the account of a balance event"`
+	Balances  []types.Balance `json:"balances,omitempty" doc:"Balances of the account's wallets of a balance event, those the ledger holds when the event is sent, which may be of a later block than height"`
+}
+
+// streamEvents streams the events of the accounts as server-sent events,
+// starting with their balances. A stream resuming from a height, given by
+// either from_height or Last-Event-ID, first catches up with the transfers
+// executed after it. The following blocks are those the server's eventHub
+// publishes.
+func streamEvents(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, errors.New("Streaming is unsupported by the connection")
+	}
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	ids := []string{}
+	for _, id := range strings.Split(query.Get("accounts"), ",") {
+		if len(id) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, badRequest("Missing accounts")
+	}
+	if len(ids) > MaxEventAccounts {
+		return nil, badRequest("Too many accounts, at most %d", MaxEventAccounts)
+	}
+	rs, err := s.readScope(ctx, callerOf(r))
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if err := rs.checkAccount(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Subscribed first, so that no block committed after height is missed
+	blocks, unsubscribe := s.subscribeEvents()
+	defer unsubscribe()
+	height, err := s.ledger.Height(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from := height
+	resume := query.Get("from_height")
+	if id := r.Header.Get("Last-Event-ID"); len(id) > 0 {
+		resume = id
+	}
+	if len(resume) > 0 {
+		if from, err = strconv.ParseUint(resume, 10, 64); err != nil || from > height {
+			return nil, badRequest("Invalid height %q, expected at most %d", resume, height)
+		}
+	}
+	st, err := newEventStream(ctx, s.ledger, ids, from)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	// Once the status is sent, the stream just ends on errors: its client
+	// reconnects from its last event ID
+	if err := st.catchUp(ctx, w, height); err != nil {
+		return nil, nil
+	}
+	flusher.Flush()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, nil
+		case b, ok := <-blocks:
+			if !ok {
+				return nil, nil
+			}
+			if b.height <= st.height {
+				continue
+			}
+			// The hub started following the ledger after the stream's
+			// height: the blocks in between are read from the history
+			if b.height > st.height+1 {
+				err = st.catchUp(ctx, w, b.height)
+			} else {
+				err = st.sendBlock(ctx, w, b)
+			}
+			if err != nil {
+				return nil, nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// blockEvents is what the streams of events need of a committed block.
+type blockEvents struct {
+	height    uint64
+	transfers []*types.Transfer // Executed by the block
+	touched   map[string]bool   // IDs of the accounts whose balances the block's Txs may change
+}
+
+// touchedAccounts returns the IDs of the accounts whose balances Txs may
+// change: those of the transfers, issues and redemptions.
+func touchedAccounts(txs []types.Tx) map[string]bool {
+	touched := map[string]bool{}
+	for _, tx := range txs {
+		switch tx := tx.(type) {
+		case *types.TransferTx:
+			touched[tx.Sender.AccountID] = true
+			touched[tx.Recipient.AccountID] = true
+		case *types.IssueTx:
+			touched[tx.AccountID] = true
+		case *types.RedeemTx:
+			touched[tx.AccountID] = true
+		}
+	}
+	return touched
+}
+
+// eventHub publishes the blocks committed by the ledger to the streams of
+// events. A single subscription to the ledger's new blocks serves all the
+// streams: its blockFollower starts with the first stream and stops when
+// the last one ends, or else with the server.
+type eventHub struct {
+	mu       sync.Mutex
+	streams  map[chan blockEvents]bool
+	follower *blockFollower // Publishing to the streams, nil if none
+}
+
+// subscribeEvents returns a channel receiving the blocks published from
+// now on, which is closed if the stream lags too far behind, and the
+// function ending the subscription.
+func (s *Server) subscribeEvents() (<-chan blockEvents, func()) {
+	h := &s.events
+	blocks := make(chan blockEvents, eventBuffer)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.streams == nil {
+		h.streams = map[chan blockEvents]bool{}
+	}
+	h.streams[blocks] = true
+	if h.follower == nil {
+		ctx, cancel := context.WithCancel(s.ctx)
+		h.follower = &blockFollower{ledger: s.ledger, hub: h, retryInterval: s.retryInterval, stop: cancel}
+		go h.follower.run(ctx)
+	}
+	return blocks, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(blocks)
+	}
+}
+
+// publish sends a block of the follower f to every stream, without waiting
+// for any. The blocks of a stopped follower are dropped, a later one
+// publishing them again.
+func (h *eventHub) publish(f *blockFollower, b blockEvents) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.follower != f {
+		return
+	}
+	for blocks := range h.streams {
+		select {
+		case blocks <- b:
+		default:
+			h.remove(blocks)
+		}
+	}
+}
+
+// remove ends a stream's subscription, and stops the follower once no
+// stream is left.
+func (h *eventHub) remove(blocks chan blockEvents) {
+	if h.streams[blocks] {
+		delete(h.streams, blocks)
+		close(blocks)
+	}
+	if len(h.streams) == 0 && h.follower != nil {
+		h.follower.stop()
+		h.follower = nil
+	}
+}
+
+// blockFollower reads the blocks committed by the ledger, from the height
+// it started at, and publishes them to an eventHub.
+type blockFollower struct {
+	ledger        Ledger
+	hub           *eventHub
+	retryInterval time.Duration
+	stop          context.CancelFunc // Cancels the context of run
+	started       bool
+	height        uint64 // Of the last block published
+	transferPos   int    // Position in the transfer history of the next transfer
+}
+
+// run follows the ledger until ctx is done, subscribing again to its new
+// blocks whenever the subscription fails.
+func (f *blockFollower) run(ctx context.Context) {
+	for {
+		err := f.follow(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn(fmt.Sprintf("Following the ledger's blocks failed, retrying in %v: %v", f.retryInterval, err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(f.retryInterval):
+		}
+	}
+}
+
+// follow subscribes to the ledger's new blocks, and publishes them until
+// the subscription ends, starting with those committed since the last
+// block published.
+func (f *blockFollower) follow(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	notify, err := f.ledger.NotifyBlocks(ctx)
+	if err != nil {
+		return err
+	}
+	if !f.started {
+		if f.height, err = f.ledger.Height(ctx); err != nil {
+			return err
+		}
+		if f.transferPos, err = transferPosition(ctx, f.ledger, f.height); err != nil {
+			return err
+		}
+		f.started = true
+	}
+	for {
+		if err := f.catchUp(ctx); err != nil {
+			return err
+		}
+		if _, ok := <-notify; !ok {
+			return errors.New("The subscription to new blocks ended")
+		}
+	}
+}
+
+// catchUp publishes the blocks committed after the last one published.
+func (f *blockFollower) catchUp(ctx context.Context) error {
+	height, err := f.ledger.Height(ctx)
+	if err != nil {
+		return err
+	}
+	for f.height < height {
+		block, err := f.ledger.GetBlock(ctx, f.height+1)
+		if err != nil {
+			return err
+		}
+		transfers, pos, err := readTransfers(ctx, f.ledger, f.transferPos, block.Height)
+		if err != nil {
+			return err
+		}
+		f.hub.publish(f, blockEvents{height: block.Height, transfers: transfers, touched: touchedAccounts(block.Txs)})
+		f.height, f.transferPos = block.Height, pos
+	}
+	return nil
+}
+
+// eventStream finds the events of its accounts in the blocks committed
+// after its height.
+type eventStream struct {
+	ledger      Ledger
+	ids         []string                   // IDs of the accounts, sorted
+	accounts    map[string]bool            // IDs of the accounts
+	height      uint64                     // Height of the last block sent
+	transferPos int                        // Position in the transfer history of the next transfer
+	balances    map[string][]types.Balance // Last sent, by account ID
+}
+
+func newEventStream(ctx context.Context, ledger Ledger, ids []string, height uint64) (*eventStream, error) {
+	st := &eventStream{ledger: ledger, accounts: map[string]bool{}, height: height, balances: map[string][]types.Balance{}}
+	for _, id := range ids {
+		if !st.accounts[id] {
+			st.accounts[id] = true
+			st.ids = append(st.ids, id)
+		}
+	}
+	sort.Strings(st.ids)
+	var err error
+	st.transferPos, err = transferPosition(ctx, ledger, height)
+	return st, err
+}
+
+// catchUp writes the events of the blocks committed after the stream's
+// height, up to height, which are read from the transfer history. The
+// balances of all the stream's accounts are queried.
+func (st *eventStream) catchUp(ctx context.Context, w io.Writer, height uint64) error {
+	transfers, pos, err := readTransfers(ctx, st.ledger, st.transferPos, height)
+	if err != nil {
+		return err
+	}
+	if err := st.send(ctx, w, height, transfers, st.ids); err != nil {
+		return err
+	}
+	st.transferPos = pos
+	return nil
+}
+
+// sendBlock writes the events of the block following the stream's height.
+// Only the balances of the accounts the block touched are queried.
+func (st *eventStream) sendBlock(ctx context.Context, w io.Writer, b blockEvents) error {
+	ids := []string{}
+	for _, id := range st.ids {
+		if b.touched[id] {
+			ids = append(ids, id)
+		}
+	}
+	if err := st.send(ctx, w, b.height, b.transfers, ids); err != nil {
+		return err
+	}
+	st.transferPos += len(b.transfers)
+	return nil
+}
+
+// send writes the events of the transfers from or to the stream's
+// accounts, then those of the balances of the accounts ids which changed
+// since they were last sent, and last the block event of height. The
+// balances are those the ledger holds when they're queried, which may be
+// of a later height.
+func (st *eventStream) send(ctx context.Context, w io.Writer, height uint64, transfers []*types.Transfer, ids []string) error {
+	events := []Event{}
+	for _, t := range transfers {
+		if st.accounts[t.SenderID] || st.accounts[t.RecipientID] {
+			events = append(events, Event{Type: EventTypeTransfer, Height: t.Height, Transfer: t})
+		}
+	}
+
+	balances := map[string][]types.Balance{}
+	for _, id := range ids {
+		returned, err := st.ledger.GetAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+		balances[id] = returned.Balances[id]
+		if balances[id] == nil {
+			balances[id] = []types.Balance{}
+		}
+		if previous, ok := st.balances[id]; !ok || !reflect.DeepEqual(previous, balances[id]) {
+			events = append(events, Event{Type: EventTypeBalance, Height: height, AccountID: id, Balances: balances[id]})
+		}
+	}
+
+	events = append(events, Event{Type: EventTypeBlock, Height: height})
+	for i, e := range events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if i == len(events)-1 {
+			_, err = fmt.Fprintf(w, "id: %d\n", height)
+		}
+		if err == nil {
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	st.height = height
+	for id, b := range balances {
+		st.balances[id] = b
+	}
+	return nil
+}
+
+// readTransfers returns the transfers of the history executed up to
+// height, starting from position pos, and the position following them.
+func readTransfers(ctx context.Context, ledger Ledger, pos int, height uint64) ([]*types.Transfer, int, error) {
+	transfers := []*types.Transfer{}
+	for {
+		returned, err := ledger.ListTransfers(ctx, url.Values{"cursor": {strconv.Itoa(pos)}})
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, t := range returned.Transfers {
+			if t.Height > height {
+				return transfers, pos, nil
+			}
+			transfers = append(transfers, t)
+			pos++
+		}
+		if len(returned.Next) == 0 {
+			return transfers, pos, nil
+		}
+	}
+}
+
+// transferPosition returns the position in the transfer history of the
+// first transfer executed after height, or its length if there's none. As
+// the history is ordered by height, the position is found by an
+// exponential search, querying a single transfer at a time.
+func transferPosition(ctx context.Context, ledger Ledger, height uint64) (int, error) {
+	var err error
+	after := func(pos int) bool {
+		var returned types.TransfersReturned
+		returned, err = ledger.ListTransfers(ctx, url.Values{"cursor": {strconv.Itoa(pos)}, "limit": {"1"}})
+		return err != nil || len(returned.Transfers) == 0 || returned.Transfers[0].Height > height
+	}
+	// The position is in [lo, hi-1], and the transfer at hi-1 is after height
+	lo, hi := 0, 1
+	for !after(hi - 1) {
+		lo, hi = hi, hi*2
+	}
+	for lo < hi-1 && err == nil {
+		if mid := (lo + hi - 1) / 2; after(mid) {
+			hi = mid + 1
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, err
+}
@@ -0,0 +1,298 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// eventText returns a server-sent event, with its height as ID if id.
+func eventText(e Event, id bool) string {
+	data, _ := json.Marshal(e)
+	text := fmt.Sprintf("event: %s\ndata: %s\n\n", e.Type, data)
+	if id {
+		text = fmt.Sprintf("id: %d\n", e.Height) + text
+	}
+	return text
+}
+
+func Test_transferPosition(t *testing.T) {
+	ledger := &fakeLedger{}
+	for _, h := range []uint64{1, 1, 2, 4, 4, 4, 7} {
+		ledger.transfers = append(ledger.transfers, &types.Transfer{Height: h})
+	}
+	tests := []struct {
+		height uint64
+		want   int
+	}{
+		{0, 0}, {1, 2}, {2, 3}, {3, 3}, {4, 6}, {6, 6}, {7, 7}, {9, 7},
+	}
+	for _, tt := range tests {
+		if got, err := transferPosition(context.Background(), ledger, tt.height); err != nil || got != tt.want {
+			t.Errorf("transferPosition(%d) = %d, %v, want %d", tt.height, got, err, tt.want)
+		}
+	}
+	if got, err := transferPosition(context.Background(), &fakeLedger{}, 3); err != nil || got != 0 {
+		t.Errorf("transferPosition() of an empty history = %d, %v, want 0", got, err)
+	}
+}
+
+func Test_eventStream_catchUp(t *testing.T) {
+	t1 := &types.Transfer{ID: "t1", Height: 2, SenderID: "a", RecipientID: "b", Amount: 100, Currency: "EUR"}
+	t2 := &types.Transfer{ID: "t2", Height: 3, SenderID: "c", RecipientID: "d", Amount: 100, Currency: "EUR"}
+	t3 := &types.Transfer{ID: "t3", Height: 5, SenderID: "b", RecipientID: "a", Amount: 50, Currency: "EUR"}
+	ledger := &fakeLedger{
+		accounts:  map[string]*types.Account{"a": {ID: "a"}},
+		balances:  map[string][]types.Balance{"a": {{Currency: "EUR", Amount: 900}}},
+		transfers: []*types.Transfer{t1, t2, t3},
+	}
+	st, err := newEventStream(context.Background(), ledger, []string{"a", "a"}, 1)
+	if err != nil {
+		t.Fatalf("newEventStream() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		height   uint64
+		balances []types.Balance // Of a, at height
+		want     []Event
+	}{
+		{"catchUp", 3, []types.Balance{{Currency: "EUR", Amount: 900}}, []Event{
+			{Type: EventTypeTransfer, Height: 2, Transfer: t1},
+			{Type: EventTypeBalance, Height: 3, AccountID: "a", Balances: []types.Balance{{Currency: "EUR", Amount: 900}}},
+		}},
+		{"unchangedBalance", 5, []types.Balance{{Currency: "EUR", Amount: 900}}, []Event{
+			{Type: EventTypeTransfer, Height: 5, Transfer: t3},
+		}},
+		{"changedBalance", 6, []types.Balance{{Currency: "EUR", Amount: 950}}, []Event{
+			{Type: EventTypeBalance, Height: 6, AccountID: "a", Balances: []types.Balance{{Currency: "EUR", Amount: 950}}},
+		}},
+	}
+	for _, tt := range tests {
+		ledger.balances["a"] = tt.balances
+		want := ""
+		for _, e := range tt.want {
+			want += eventText(e, false)
+		}
+		want += eventText(Event{Type: EventTypeBlock, Height: tt.height}, true)
+
+		var buf bytes.Buffer
+		if err := st.catchUp(context.Background(), &buf, tt.height); err != nil || buf.String() != want {
+			t.Errorf("%q. eventStream.catchUp() = %q, %v, want %q", tt.name, buf.String(), err, want)
+		}
+	}
+}
+
+func Test_eventStream_sendBlock(t *testing.T) {
+	ledger := &fakeLedger{
+		accounts: map[string]*types.Account{"a": {ID: "a"}, "b": {ID: "b"}},
+		balances: map[string][]types.Balance{"a": {{Currency: "EUR", Amount: 900}}, "b": {{Currency: "EUR", Amount: 500}}},
+	}
+	st, err := newEventStream(context.Background(), ledger, []string{"a", "b"}, 5)
+	if err == nil {
+		err = st.catchUp(context.Background(), ioutil.Discard, 5)
+	}
+	if err != nil {
+		t.Fatalf("eventStream.catchUp() error = %v", err)
+	}
+	t1 := &types.Transfer{ID: "t1", Height: 6, SenderID: "a", RecipientID: "c", Amount: 100, Currency: "EUR"}
+
+	tests := []struct {
+		name     string
+		block    blockEvents
+		balances map[string][]types.Balance // At the block's height
+		want     []Event
+	}{
+		// The balance of b changed, but only the accounts touched by the block are queried
+		{"touched", blockEvents{height: 6, transfers: []*types.Transfer{t1}, touched: map[string]bool{"a": true, "c": true}},
+			map[string][]types.Balance{"a": {{Currency: "EUR", Amount: 800}}, "b": {{Currency: "EUR", Amount: 600}}}, []Event{
+				{Type: EventTypeTransfer, Height: 6, Transfer: t1},
+				{Type: EventTypeBalance, Height: 6, AccountID: "a", Balances: []types.Balance{{Currency: "EUR", Amount: 800}}},
+			}},
+		{"untouched", blockEvents{height: 7, touched: map[string]bool{"c": true}},
+			map[string][]types.Balance{"a": {{Currency: "EUR", Amount: 800}}, "b": {{Currency: "EUR", Amount: 600}}}, nil},
+		{"touchedLater", blockEvents{height: 8, touched: map[string]bool{"b": true}},
+			map[string][]types.Balance{"a": {{Currency: "EUR", Amount: 800}}, "b": {{Currency: "EUR", Amount: 600}}}, []Event{
+				{Type: EventTypeBalance, Height: 8, AccountID: "b", Balances: []types.Balance{{Currency: "EUR", Amount: 600}}},
+			}},
+	}
+	for _, tt := range tests {
+		ledger.balances = tt.balances
+		want := ""
+		for _, e := range tt.want {
+			want += eventText(e, false)
+		}
+		want += eventText(Event{Type: EventTypeBlock, Height: tt.block.height}, true)
+
+		var buf bytes.Buffer
+		if err := st.sendBlock(context.Background(), &buf, tt.block); err != nil || buf.String() != want {
+			t.Errorf("%q. eventStream.sendBlock() = %q, %v, want %q", tt.name, buf.String(), err, want)
+		}
+	}
+}
+
+func Test_blockFollower_catchUp(t *testing.T) {
+	t1 := &types.Transfer{ID: "t1", Height: 2, SenderID: "a", RecipientID: "b", Amount: 100, Currency: "EUR"}
+	t2 := &types.Transfer{ID: "t2", Height: 4, SenderID: "b", RecipientID: "a", Amount: 50, Currency: "EUR"}
+	t3 := &types.Transfer{ID: "t3", Height: 4, SenderID: "a", RecipientID: "c", Amount: 50, Currency: "EUR"}
+	transferTx := func(senderID, recipientID string) *types.TransferTx {
+		return &types.TransferTx{Sender: types.TxTransferSender{AccountID: senderID}, Recipient: types.TxTransferRecipient{AccountID: recipientID}}
+	}
+	ledger := &fakeLedger{
+		height:    4,
+		transfers: []*types.Transfer{t1, t2, t3},
+		blocks: map[uint64]*client.Block{
+			2: {Height: 2, Txs: []types.Tx{transferTx("a", "b")}},
+			3: {Height: 3, Txs: []types.Tx{&types.IssueTx{AccountID: "d"}, &types.CreateAccountTx{AccountID: "e"}}},
+			4: {Height: 4, Txs: []types.Tx{transferTx("b", "a"), transferTx("a", "c"), &types.RedeemTx{AccountID: "f"}}},
+		},
+	}
+	blocks := make(chan blockEvents, eventBuffer)
+	f := &blockFollower{ledger: ledger, hub: &eventHub{streams: map[chan blockEvents]bool{blocks: true}}, started: true, height: 1}
+	f.hub.follower = f
+	if err := f.catchUp(context.Background()); err != nil {
+		t.Fatalf("blockFollower.catchUp() error = %v", err)
+	}
+
+	want := []blockEvents{
+		{height: 2, transfers: []*types.Transfer{t1}, touched: map[string]bool{"a": true, "b": true}},
+		{height: 3, transfers: []*types.Transfer{}, touched: map[string]bool{"d": true}},
+		{height: 4, transfers: []*types.Transfer{t2, t3}, touched: map[string]bool{"a": true, "b": true, "c": true, "f": true}},
+	}
+	for _, w := range want {
+		select {
+		case got := <-blocks:
+			if !reflect.DeepEqual(got, w) {
+				t.Errorf("blockFollower.catchUp() published %+v, want %+v", got, w)
+			}
+		default:
+			t.Fatalf("blockFollower.catchUp() didn't publish block %d", w.height)
+		}
+	}
+	if f.height != 4 || f.transferPos != 3 {
+		t.Errorf("blockFollower at height %d, transfer %d, want 4, 3", f.height, f.transferPos)
+	}
+}
+
+func Test_eventHub_publish(t *testing.T) {
+	fast, slow := make(chan blockEvents, eventBuffer), make(chan blockEvents, eventBuffer)
+	f := &blockFollower{}
+	h := &eventHub{streams: map[chan blockEvents]bool{fast: true, slow: true}, follower: f}
+	for height := uint64(1); height <= eventBuffer+1; height++ {
+		h.publish(f, blockEvents{height: height})
+		<-fast
+	}
+	for range slow {
+	}
+	if len(h.streams) != 1 || !h.streams[fast] {
+		t.Errorf("eventHub streams = %v, want only the fast one", h.streams)
+	}
+
+	h.publish(&blockFollower{}, blockEvents{height: eventBuffer + 2})
+	select {
+	case b := <-fast:
+		t.Errorf("eventHub published block %d of a stopped follower", b.height)
+	default:
+	}
+}
+
+func TestServer_subscribeEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ledger := newFakeLedger(client.NewKeySigner(crypto.GenPrivKeyEd25519()))
+	s := New(ctx, ledger)
+
+	_, end1 := s.subscribeEvents()
+	_, end2 := s.subscribeEvents()
+	first := s.events.follower
+	if first == nil {
+		t.Fatalf("subscribeEvents() didn't start a follower")
+	}
+	end1()
+	if s.events.follower != first {
+		t.Errorf("eventHub follower = %p, want %p while a stream is left", s.events.follower, first)
+	}
+	end2()
+	if s.events.follower != nil {
+		t.Errorf("eventHub follower = %p, want it stopped with the last stream", s.events.follower)
+	}
+
+	_, end3 := s.subscribeEvents()
+	defer end3()
+	if s.events.follower == nil || s.events.follower == first {
+		t.Errorf("subscribeEvents() didn't start a new follower after the last stream ended")
+	}
+}
+
+func TestServer_events(t *testing.T) {
+	trader := client.NewKeySigner(crypto.GenPrivKeyEd25519())
+	eur := []types.Balance{{Currency: "EUR", Amount: 10050, Display: "100.50"}}
+	t1 := &types.Transfer{ID: "t1", Height: 2, SenderID: "a", RecipientID: "b", Amount: 100, Currency: "EUR"}
+	t2 := &types.Transfer{ID: "t2", Height: 5, SenderID: "b", RecipientID: "a", Amount: 50, Currency: "EUR"}
+	balance := Event{Type: EventTypeBalance, Height: 7, AccountID: "a", Balances: eur}
+	block := Event{Type: EventTypeBlock, Height: 7}
+
+	tests := []struct {
+		name        string
+		path        string
+		lastEventID string
+		wantStatus  int
+		want        []Event // The last one has an ID
+	}{
+		{"snapshot", "/events?accounts=a", "", http.StatusOK, []Event{balance, block}},
+		{"resume", "/events?accounts=a&from_height=1", "", http.StatusOK,
+			[]Event{{Type: EventTypeTransfer, Height: 2, Transfer: t1}, {Type: EventTypeTransfer, Height: 5, Transfer: t2}, balance, block}},
+		{"lastEventID", "/events?accounts=a&from_height=1", "2", http.StatusOK,
+			[]Event{{Type: EventTypeTransfer, Height: 5, Transfer: t2}, balance, block}},
+		{"missingAccounts", "/events?accounts=", "", http.StatusBadRequest, nil},
+		{"unknownAccount", "/events?accounts=a,x", "", http.StatusNotFound, nil},
+		{"futureHeight", "/events?accounts=a&from_height=8", "", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		ledger := newFakeLedger(trader)
+		ledger.height = 7
+		ledger.transfers = []*types.Transfer{t1, t2}
+		r := mux.NewRouter()
+		New(context.Background(), ledger).AddRoutes(r)
+
+		// The stream ends after its first events, as the request is canceled
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if len(tt.lastEventID) > 0 {
+			req.Header.Set("Last-Event-ID", tt.lastEventID)
+		}
+		if err := SignRequest(req, nil, trader); err != nil {
+			t.Fatalf("SignRequest() error = %v", err)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req.WithContext(ctx))
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%q. status = %d, want %d, body %s", tt.name, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		if tt.want == nil {
+			continue
+		}
+		want := ""
+		for i, e := range tt.want {
+			want += eventText(e, i == len(tt.want)-1)
+		}
+		if rec.Body.String() != want || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/event-stream") {
+			t.Errorf("%q. response = %s %q, want %q", tt.name, rec.Header().Get("Content-Type"), rec.Body, want)
+		}
+	}
+}
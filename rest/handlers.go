@@ -0,0 +1,695 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/satori/go.uuid"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// Receipt describes an executed Tx.
+type Receipt struct {
+	ClientID string `json:"client_id" doc:"ID the Tx's result can be queried by at /tx_results/{client_id}"`
+	Hash     string `json:"hash" doc:"Hex encoded hash of the Tx, see /txs/{hash}"`
+	Height   uint64 `json:"height" doc:"Height of the block the Tx was executed in"`
+	Log      string `json:"log,omitempty"`
+}
+
+func newReceipt(r *client.TxReceipt) Receipt {
+	return Receipt{ClientID: r.ClientID, Hash: fmt.Sprintf("%X", r.Hash), Height: r.Height, Log: r.Log}
+}
+
+// Account is an account with the balances of its wallets.
+type Account struct {
+	ID       string          `json:"id"`
+	EntityID string          `json:"entity_id" doc:"ID of the legal entity owning the account"`
+	Balances []types.Balance `json:"balances" doc:"Balances of the account's wallets, in minor units and as decimal amounts"`
+}
+
+// AccountList is a page of accounts.
+type AccountList struct {
+	Accounts []Account `json:"accounts"`
+	Next     string    `json:"next,omitempty" doc:"Cursor of the next page, empty on the last one"`
+}
+
+func newAccountList(returned types.AccountsReturned) AccountList {
+	list := AccountList{Accounts: make([]Account, len(returned.Account)), Next: returned.Next}
+	for i, acc := range returned.Account {
+		list.Accounts[i] = Account{ID: acc.ID, EntityID: acc.EntityID, Balances: returned.Balances[acc.ID]}
+		if list.Accounts[i].Balances == nil {
+			list.Accounts[i].Balances = []types.Balance{}
+		}
+	}
+	return list
+}
+
+// User is a user, whose address and public key are respectively hex and
+// base64 encoded.
+type User struct {
+	Address     string     `json:"address" doc:"Hex encoded address of the user's public key"`
+	PubKey      string     `json:"pub_key" doc:"Base64 encoded public key of the user"`
+	Name        string     `json:"name"`
+	EntityID    string     `json:"entity_id" doc:"ID of the user's legal entity"`
+	Permissions types.Perm `json:"permissions" doc:"Set of the Txs the user can send"`
+	Nonce       uint64     `json:"nonce" doc:"Nonce of the user's last Tx"`
+}
+
+// UserList is a page of users.
+type UserList struct {
+	Users []User `json:"users"`
+	Next  string `json:"next,omitempty" doc:"Cursor of the next page, empty on the last one"`
+}
+
+func newUser(u *types.User) User {
+	return User{
+		Address:     fmt.Sprintf("%X", u.PubKey.Address()),
+		PubKey:      base64.StdEncoding.EncodeToString(u.PubKey.Bytes()),
+		Name:        u.Name,
+		EntityID:    u.EntityID,
+		Permissions: u.Permissions,
+		Nonce:       u.Nonce,
+	}
+}
+
+// CreateAccountRequest is the body of POST /accounts.
+type CreateAccountRequest struct {
+	AccountID string `json:"account_id,omitempty" doc:"ID of the new account, a new UUID if empty"`
+}
+
+// CreateEntityRequest is the body of POST /entities.
+type CreateEntityRequest struct {
+	EntityID string `json:"entity_id,omitempty" doc:"ID of the new legal entity, a new UUID if empty"`
+	Type     string `json:"type" doc:"Type of the legal entity: ch, gcm, icm or custodian"`
+	Name     string `json:"name,omitempty"`
+	ParentID string `json:"parent_id" doc:"ID of the parent legal entity"`
+}
+
+// CreateUserRequest is the body of POST /users.
+type CreateUserRequest struct {
+	Name      string `json:"name"`
+	PubKey    string `json:"pub_key" doc:"Base64 encoded public key of the new user"`
+	CanCreate bool   `json:"can_create,omitempty" doc:"Whether the new user can create users and legal entities"`
+}
+
+// TransferRequest is the body of POST /transfers.
+type TransferRequest struct {
+	SenderID    string `json:"sender_id" doc:"ID of the sender's account"`
+	RecipientID string `json:"recipient_id" doc:"ID of the recipient's account"`
+	Amount      string `json:"amount" doc:"Decimal amount, e.g. 100.00"`
+	Currency    string `json:"currency"`
+	Reference   string `json:"reference,omitempty" doc:"Client's reference of the transfer, e.g. a trade ID"`
+	Memo        string `json:"memo,omitempty" doc:"Free text for the recipient"`
+}
+
+// MoneyRequest is the body of POST /accounts/{id}/issue and /redeem.
+type MoneyRequest struct {
+	Amount   string `json:"amount" doc:"Decimal amount, e.g. 100.00"`
+	Currency string `json:"currency"`
+}
+
+// TxFile is the body of POST /txs, a Tx signed offline as written by
+// 'ledgerctl tx sign'.
+type TxFile struct {
+	ChainID string      `json:"chain_id"`
+	Tx      interface{} `json:"tx" doc:"The Tx in go-wire's JSON encoding"`
+}
+
+var routes = []route{
+	{
+		name: "listAccounts", method: http.MethodGet, path: "/accounts",
+		summary: "List the accounts with the balances of their wallets",
+		params: append([]param{
+			queryParam("entity", "string", "Only list the accounts of this legal entity, by default the user's unless it's of the clearing house"),
+			queryParam("currency", "string", "Only list the accounts holding a wallet of this currency"),
+			queryParam("non_zero", "boolean", "Only list the accounts with a non-zero balance"),
+		}, pageParams...),
+		response: AccountList{}, status: http.StatusOK,
+		handle: listAccounts,
+	},
+	{
+		name: "createAccount", method: http.MethodPost, path: "/accounts",
+		summary: "Create an account owned by the authenticated user's legal entity",
+		body:    CreateAccountRequest{}, response: Receipt{}, status: http.StatusCreated,
+		txType: types.TxTypeCreateAccount,
+		handle: createAccount,
+	},
+	{
+		name: "getAccount", method: http.MethodGet, path: "/accounts/{id}",
+		summary: "Get an account with the balances of its wallets",
+		params: []param{
+			pathParam("id", "ID of the account"),
+			queryParam("height", "integer", "Get the account as it was at this block height"),
+		},
+		response: Account{}, status: http.StatusOK,
+		handle: getAccount,
+	},
+	{
+		name: "issueMoney", method: http.MethodPost, path: "/accounts/{id}/issue",
+		summary: "Credit an account with newly created money, only by the clearing house",
+		params:  []param{pathParam("id", "ID of the account")},
+		body:    MoneyRequest{}, response: Receipt{}, status: http.StatusOK,
+		txType: types.TxTypeIssue,
+		handle: issueMoney,
+	},
+	{
+		name: "redeemMoney", method: http.MethodPost, path: "/accounts/{id}/redeem",
+		summary: "Debit an account and destroy the money, only by the clearing house",
+		params:  []param{pathParam("id", "ID of the account")},
+		body:    MoneyRequest{}, response: Receipt{}, status: http.StatusOK,
+		txType: types.TxTypeRedeem,
+		handle: redeemMoney,
+	},
+	{
+		name: "listEntities", method: http.MethodGet, path: "/entities",
+		summary: "List the legal entities",
+		params: append([]param{
+			queryParam("type", "string", "Only list the legal entities of this type: ch, gcm, icm or custodian"),
+			queryParam("parent", "string", "Only list the children of this legal entity, by default the user's unless it's of the clearing house"),
+		}, pageParams...),
+		response: types.LegalEntitiesReturned{}, status: http.StatusOK,
+		handle: listEntities,
+	},
+	{
+		name: "createEntity", method: http.MethodPost, path: "/entities",
+		summary: "Create a legal entity",
+		body:    CreateEntityRequest{}, response: Receipt{}, status: http.StatusCreated,
+		txType: types.TxTypeCreateLegalEntity,
+		handle: createEntity,
+	},
+	{
+		name: "getEntity", method: http.MethodGet, path: "/entities/{id}",
+		summary:  "Get a legal entity",
+		params:   []param{pathParam("id", "ID of the legal entity")},
+		response: types.LegalEntity{}, status: http.StatusOK,
+		handle: getEntity,
+	},
+	{
+		name: "getEntityBalances", method: http.MethodGet, path: "/entities/{id}/balances",
+		summary: "Get the balances of a legal entity's accounts by currency",
+		params: []param{
+			pathParam("id", "ID of the legal entity"),
+			queryParam("rollup", "boolean", "Include the balances of the legal entity's descendants"),
+		},
+		response: types.BalanceReport{}, status: http.StatusOK,
+		handle: getEntityBalances,
+	},
+	{
+		name: "listUsers", method: http.MethodGet, path: "/users",
+		summary: "List the users",
+		params: append([]param{
+			queryParam("entity", "string", "Only list the users of this legal entity, by default the user's unless it's of the clearing house"),
+		}, pageParams...),
+		response: UserList{}, status: http.StatusOK,
+		handle: listUsers,
+	},
+	{
+		name: "createUser", method: http.MethodPost, path: "/users",
+		summary: "Create a user of the signer's legal entity, with the signer's permissions",
+		body:    CreateUserRequest{}, response: Receipt{}, status: http.StatusCreated,
+		txType: types.TxTypeCreateUser,
+		handle: createUser,
+	},
+	{
+		name: "getUser", method: http.MethodGet, path: "/users/{address}",
+		summary:  "Get a user by address",
+		params:   []param{pathParam("address", "Hex encoded address of the user")},
+		response: User{}, status: http.StatusOK,
+		handle: getUser,
+	},
+	{
+		name: "listTransfers", method: http.MethodGet, path: "/transfers",
+		summary: "List the transfer history from or to the accounts the user may read, oldest first",
+		params: append([]param{
+			queryParam("reference", "string", "Only list the transfers carrying this reference"),
+		}, pageParams...),
+		response: types.TransfersReturned{}, status: http.StatusOK,
+		handle: listTransfers,
+	},
+	{
+		name: "createTransfer", method: http.MethodPost, path: "/transfers",
+		summary: "Transfer money from an account to another, without counter signers",
+		body:    TransferRequest{}, response: Receipt{}, status: http.StatusCreated,
+		txType: types.TxTypeTransfer,
+		handle: createTransfer,
+	},
+	{
+		name: "getTransfer", method: http.MethodGet, path: "/transfers/{id}",
+		summary:  "Get a transfer of the history",
+		params:   []param{pathParam("id", "ID of the transfer")},
+		response: types.Transfer{}, status: http.StatusOK,
+		handle: getTransfer,
+	},
+	{
+		name: "listCurrencies", method: http.MethodGet, path: "/currencies",
+		summary: "List the currencies and instruments of the registry",
+		params: append([]param{
+			queryParam("retired", "boolean", "Only list the retired currencies, or the others if false"),
+		}, pageParams...),
+		response: types.CurrenciesReturned{}, status: http.StatusOK,
+		handle: listCurrencies,
+	},
+	{
+		name: "getCurrency", method: http.MethodGet, path: "/currencies/{symbol}",
+		summary:  "Get a currency of the registry",
+		params:   []param{pathParam("symbol", "Symbol of the currency, e.g. EUR")},
+		response: types.CurrencyEntry{}, status: http.StatusOK,
+		handle: getCurrency,
+	},
+	{
+		name: "getSupply", method: http.MethodGet, path: "/supply",
+		summary:  "Get the total supply of every currency alongside the sum of all wallet balances",
+		response: types.SupplyReport{}, status: http.StatusOK,
+		handle: getSupply,
+	},
+	{
+		name: "streamEvents", method: http.MethodGet, path: "/events",
+		summary: "Stream the transfers from or to accounts and the changes of their balances as server-sent events. " +
+			"A balance event holds the balances when it's sent, which may be those of a later block than its height",
+		params: []param{
+			queryParam("accounts", "string", "Comma separated IDs of the accounts"),
+			queryParam("from_height", "integer", "Resume from this height, sending the transfers executed after it first"),
+			{name: "Last-Event-ID", in: "header", typ: "integer", description: "Resume from this height, overrides from_height"},
+		},
+		response: Event{}, status: http.StatusOK, stream: true,
+		handle: streamEvents,
+	},
+	{
+		name: "broadcastTx", method: http.MethodPost, path: "/txs",
+		summary: "Broadcast a Tx signed offline by the authenticated user, e.g. a counter-signed transfer",
+		body:    TxFile{}, response: Receipt{}, status: http.StatusOK,
+		handle: broadcastTx,
+	},
+	{
+		name: "getTx", method: http.MethodGet, path: "/txs/{hash}",
+		summary:  "Get a delivered Tx by hash",
+		params:   []param{pathParam("hash", "Hex encoded hash of the Tx")},
+		response: types.TxRecord{}, status: http.StatusOK,
+		handle: getTx,
+	},
+	{
+		name: "getTxResult", method: http.MethodGet, path: "/tx_results/{client_id}",
+		summary:  "Get the result of the executed Tx carrying a client ID",
+		params:   []param{pathParam("client_id", "Client ID of the Tx")},
+		response: types.TxResult{}, status: http.StatusOK,
+		handle: getTxResult,
+	},
+}
+
+func listAccounts(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	params := copyParams(r.URL.Query(), map[string]string{
+		"entity": "entity_id", "currency": "currency", "non_zero": "non_zero", "cursor": "cursor", "limit": "limit",
+	})
+	if err := s.FilterByEntity(r, params, "entity_id"); err != nil {
+		return nil, err
+	}
+	params.Set("decimal", "true")
+	returned, err := s.ledger.ListAccounts(r.Context(), params)
+	if err != nil {
+		return nil, err
+	}
+	return newAccountList(returned), nil
+}
+
+func createAccount(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var req CreateAccountRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	if len(req.AccountID) == 0 {
+		req.AccountID = uuid.NewV4().String()
+	}
+	receipt, err := s.ledger.CreateAccount(r.Context(), req.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	w.Header().Set("Location", "/accounts/"+req.AccountID)
+	return newReceipt(receipt), nil
+}
+
+func getAccount(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	id := mux.Vars(r)["id"]
+	var returned types.AccountsReturned
+	var err error
+	if height := r.URL.Query().Get("height"); len(height) > 0 {
+		h, _ := strconv.ParseUint(height, 10, 64)
+		returned, err = s.ledger.GetAccountAtHeight(r.Context(), id, h)
+	} else {
+		returned, err = s.ledger.GetAccount(r.Context(), id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	list := newAccountList(returned)
+	if len(list.Accounts) != 1 {
+		return nil, fmt.Errorf("Expected one account, got %d", len(list.Accounts))
+	}
+	if err := s.checkEntity(r, list.Accounts[0].EntityID); err != nil {
+		return nil, err
+	}
+	return list.Accounts[0], nil
+}
+
+func issueMoney(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	id := mux.Vars(r)["id"]
+	var req MoneyRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	amount, err := s.parseAmount(r, req.Amount, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.ledger.IssueMoney(r.Context(), id, amount, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return newReceipt(receipt), nil
+}
+
+func redeemMoney(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	id := mux.Vars(r)["id"]
+	var req MoneyRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	amount, err := s.parseAmount(r, req.Amount, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.ledger.RedeemMoney(r.Context(), id, amount, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	return newReceipt(receipt), nil
+}
+
+func listEntities(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	params := copyParams(r.URL.Query(), map[string]string{
+		"type": "type", "parent": "parent_id", "cursor": "cursor", "limit": "limit",
+	})
+	if err := s.FilterByEntity(r, params, "parent_id"); err != nil {
+		return nil, err
+	}
+	return s.ledger.ListLegalEntities(r.Context(), params)
+}
+
+func createEntity(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var req CreateEntityRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	entityType, ok := types.ParseEntityType(req.Type)
+	if !ok {
+		return nil, badRequest("Invalid type %q, expected ch, gcm, icm or custodian", req.Type)
+	}
+	if len(req.EntityID) == 0 {
+		req.EntityID = uuid.NewV4().String()
+	}
+	receipt, err := s.ledger.CreateLegalEntity(r.Context(), req.EntityID, entityType, req.Name, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	w.Header().Set("Location", "/entities/"+req.EntityID)
+	return newReceipt(receipt), nil
+}
+
+func getEntity(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	if err := s.checkEntity(r, mux.Vars(r)["id"]); err != nil {
+		return nil, err
+	}
+	returned, err := s.ledger.GetLegalEntity(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		return nil, err
+	}
+	if len(returned.LegalEntities) != 1 {
+		return nil, fmt.Errorf("Expected one legal entity, got %d", len(returned.LegalEntities))
+	}
+	return returned.LegalEntities[0], nil
+}
+
+func getEntityBalances(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	rollup, _ := strconv.ParseBool(r.URL.Query().Get("rollup"))
+	if err := s.checkEntity(r, mux.Vars(r)["id"]); err != nil {
+		return nil, err
+	}
+	return s.ledger.GetLegalEntityBalances(r.Context(), mux.Vars(r)["id"], rollup)
+}
+
+func listUsers(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	params := copyParams(r.URL.Query(), map[string]string{
+		"entity": "entity_id", "cursor": "cursor", "limit": "limit",
+	})
+	if err := s.FilterByEntity(r, params, "entity_id"); err != nil {
+		return nil, err
+	}
+	returned, err := s.ledger.ListUsers(r.Context(), params)
+	if err != nil {
+		return nil, err
+	}
+	list := UserList{Users: make([]User, len(returned.Users)), Next: returned.Next}
+	for i, u := range returned.Users {
+		list.Users[i] = newUser(u)
+	}
+	return list, nil
+}
+
+func createUser(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var req CreateUserRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(req.PubKey)
+	if err != nil {
+		return nil, badRequest("Invalid pub_key: %v", err)
+	}
+	pubKey, err := crypto.PubKeyFromBytes(data)
+	if err != nil {
+		return nil, badRequest("Invalid pub_key: %v", err)
+	}
+	receipt, err := s.ledger.CreateUser(r.Context(), req.Name, pubKey, req.CanCreate)
+	if err != nil {
+		return nil, err
+	}
+	w.Header().Set("Location", fmt.Sprintf("/users/%X", pubKey.Address()))
+	return newReceipt(receipt), nil
+}
+
+func getUser(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	addr, err := hex.DecodeString(mux.Vars(r)["address"])
+	if err != nil {
+		return nil, badRequest("Invalid address: %v", err)
+	}
+	returned, err := s.ledger.GetUser(r.Context(), addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(returned.Users) != 1 {
+		return nil, fmt.Errorf("Expected one user, got %d", len(returned.Users))
+	}
+	if err := s.checkEntity(r, returned.Users[0].EntityID); err != nil {
+		return nil, err
+	}
+	return newUser(returned.Users[0]), nil
+}
+
+func listTransfers(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	params := copyParams(r.URL.Query(), map[string]string{
+		"reference": "reference", "cursor": "cursor", "limit": "limit",
+	})
+	rs, err := s.readScope(r.Context(), callerOf(r))
+	if err != nil {
+		return nil, err
+	}
+	returned, err := s.ledger.ListTransfers(r.Context(), params)
+	if err != nil || rs.all {
+		return returned, err
+	}
+	// The pages of the other callers than the clearing house's may be
+	// shorter than the limit, or even empty
+	transfers := []*types.Transfer{}
+	for _, t := range returned.Transfers {
+		if in, err := rs.hasTransfer(r.Context(), t); err != nil {
+			return nil, err
+		} else if in {
+			transfers = append(transfers, t)
+		}
+	}
+	returned.Transfers = transfers
+	return returned, nil
+}
+
+func createTransfer(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	var req TransferRequest
+	if err := decodeBody(r, &req); err != nil {
+		return nil, err
+	}
+	amount, err := s.parseAmount(r, req.Amount, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.ledger.TransferMoney(r.Context(), client.Transfer{
+		SenderID:    req.SenderID,
+		RecipientID: req.RecipientID,
+		Amount:      amount,
+		Currency:    req.Currency,
+		Reference:   req.Reference,
+		Memo:        req.Memo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newReceipt(receipt), nil
+}
+
+func getTransfer(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	returned, err := s.ledger.GetTransfer(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		return nil, err
+	}
+	if len(returned.Transfers) != 1 {
+		return nil, fmt.Errorf("Expected one transfer, got %d", len(returned.Transfers))
+	}
+	rs, err := s.readScope(r.Context(), callerOf(r))
+	if err != nil {
+		return nil, err
+	}
+	if in, err := rs.hasTransfer(r.Context(), returned.Transfers[0]); err != nil {
+		return nil, err
+	} else if !in {
+		return nil, rs.forbidden()
+	}
+	return returned.Transfers[0], nil
+}
+
+func listCurrencies(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	params := copyParams(r.URL.Query(), map[string]string{
+		"retired": "retired", "cursor": "cursor", "limit": "limit",
+	})
+	return s.ledger.ListCurrencies(r.Context(), params)
+}
+
+func getCurrency(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return s.ledger.GetCurrency(r.Context(), mux.Vars(r)["symbol"])
+}
+
+func getSupply(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	return s.ledger.GetSupply(r.Context())
+}
+
+func broadcastTx(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, badRequest("Error reading the body: %v", err)
+	}
+	chainID, tx, err := client.DecodeTxFile(data)
+	if err != nil {
+		return nil, badRequest("Invalid Tx: %v", err)
+	}
+	if chainID != s.ledger.ChainID() {
+		return nil, badRequest("The Tx is signed for chain %q, not %q", chainID, s.ledger.ChainID())
+	}
+	noncedTx, ok := tx.(types.NoncedTx)
+	if !ok {
+		return nil, badRequest("Unsupported Tx type %T", tx)
+	}
+	if c := callerOf(r); !bytes.Equal(noncedTx.Signer(), c.address) {
+		return nil, forbidden("The Tx is signed by user %X, not by %X", noncedTx.Signer(), c.address)
+	}
+	receipt, err := s.ledger.Broadcast(r.Context(), noncedTx)
+	if err != nil {
+		return nil, err
+	}
+	return newReceipt(receipt), nil
+}
+
+func getTx(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	hash, err := hex.DecodeString(mux.Vars(r)["hash"])
+	if err != nil {
+		return nil, badRequest("Invalid hash: %v", err)
+	}
+	record, err := s.ledger.GetTx(r.Context(), hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUser(r, record.Signer); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func getTxResult(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error) {
+	result, err := s.ledger.GetTxResult(r.Context(), mux.Vars(r)["client_id"])
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUser(r, result.Signer); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// parseAmount converts a decimal amount of a request to the currency's
+// minor units. An unknown currency is a bad request rather than a missing
+// resource.
+func (s *Server) parseAmount(r *http.Request, amount string, currency string) (int64, error) {
+	entry, err := s.ledger.GetCurrency(r.Context(), currency)
+	if client.IsNotFound(err) {
+		return 0, badRequest("Unknown currency %q", currency)
+	}
+	if err != nil {
+		return 0, err
+	}
+	a, err := entry.ParseAmount(amount)
+	if err != nil {
+		return 0, badRequest("Invalid amount %q: %v", amount, err)
+	}
+	return int64(a), nil
+}
+
+// checkEntity returns an error unless the caller may read the objects of
+// the legal entity, see readScope.
+func (s *Server) checkEntity(r *http.Request, entityID string) error {
+	rs, err := s.readScope(r.Context(), callerOf(r))
+	if err != nil {
+		return err
+	}
+	return rs.checkEntity(r.Context(), entityID)
+}
+
+// checkUser returns an error unless the caller may read the objects of
+// the user, e.g. its Txs, see readScope.
+func (s *Server) checkUser(r *http.Request, addr []byte) error {
+	rs, err := s.readScope(r.Context(), callerOf(r))
+	if err != nil {
+		return err
+	}
+	return rs.checkUser(r.Context(), addr)
+}
+
+// FilterByEntity checks the legal entity of the key parameter a list query
+// is filtered by, which defaults to the caller's unless it may read all
+// the objects.
+func (s *Server) FilterByEntity(r *http.Request, params url.Values, key string) error {
+	rs, err := s.readScope(r.Context(), callerOf(r))
+	if err != nil {
+		return err
+	}
+	entityID, err := rs.filterEntity(r.Context(), params.Get(key))
+	if err != nil {
+		return err
+	}
+	if len(entityID) > 0 {
+		params.Set(key, entityID)
+	}
+	return nil
+}
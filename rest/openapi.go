@@ -0,0 +1,249 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Version is the version of the API in its spec.
+const Version = "1.0.0"
+
+// Spec returns the OpenAPI 3 spec of the API, generated from its routes
+// and the types of their request and response bodies. The fields of the
+// bodies are described by their doc tags, and are required unless their
+// json tags have omitempty. Every route requires the ledgerSignature
+// security scheme, see SignRequest.
+func Spec() map[string]interface{} {
+	g := &schemaGenerator{schemas: map[string]interface{}{}, types: map[string]reflect.Type{}}
+	errorResponse := map[string]interface{}{
+		"description": "Error, whose code is the ledger's ABCI result code if it answered with one",
+		"content":     jsonContent(g.schema(reflect.TypeOf(ErrorResponse{}))),
+	}
+
+	paths := map[string]interface{}{}
+	for _, rt := range routes {
+		content := jsonContent(g.schema(reflect.TypeOf(rt.response)))
+		if rt.stream {
+			content = map[string]interface{}{"text/event-stream": content["application/json"]}
+		}
+		op := map[string]interface{}{
+			"operationId": rt.name,
+			"summary":     rt.summary,
+			"responses": map[string]interface{}{
+				strconv.Itoa(rt.status): map[string]interface{}{
+					"description": http.StatusText(rt.status),
+					"content":     content,
+				},
+				"default": errorResponse,
+			},
+		}
+		if len(rt.params) > 0 {
+			params := make([]interface{}, len(rt.params))
+			for i, p := range rt.params {
+				params[i] = map[string]interface{}{
+					"name":        p.name,
+					"in":          p.in,
+					"required":    p.in == "path",
+					"description": p.description,
+					"schema":      map[string]interface{}{"type": p.typ},
+				}
+			}
+			op["parameters"] = params
+		}
+		if rt.body != nil {
+			op["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  jsonContent(g.schema(reflect.TypeOf(rt.body))),
+			}
+		}
+		path, ok := paths[rt.path].(map[string]interface{})
+		if !ok {
+			path = map[string]interface{}{}
+			paths[rt.path] = path
+		}
+		path[strings.ToLower(rt.method)] = op
+	}
+
+	securityScheme := map[string]interface{}{
+		"type": "apiKey",
+		"in":   "header",
+		"name": "Authorization",
+		"description": fmt.Sprintf("%s <address>:<signature>, the hex encoded address of a ledger user and the base64 "+
+			"encoded signature by the user's key of the request's method, request URI, %s header, %s header, empty if "+
+			"none, and hex encoded SHA-256 hash of its body, one per line. The %s header is the time the request is "+
+			"signed at, in RFC 1123 format, which must be within %v of the webserver's time. The %s header is a value "+
+			"unique to the request, e.g. a random UUID, required by the requests other than GETs: the webserver rejects "+
+			"their signatures once seen, so that they can't be replayed.",
+			AuthScheme, DateHeader, NonceHeader, DateHeader, MaxClockSkew, NonceHeader),
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.0",
+		"info": map[string]interface{}{
+			"title":   "Clearchain ledger API",
+			"version": Version,
+			"description": "Users read the objects of their legal entity and its descendants, accounts, balances, " +
+				"transfers, users and Txs, while those of the clearing house read all of them.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas":         g.schemas,
+			"securitySchemes": map[string]interface{}{"ledgerSignature": securityScheme},
+		},
+		"security": []interface{}{map[string]interface{}{"ledgerSignature": []string{}}},
+	}
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+}
+
+// schemaGenerator generates the JSON schemas of Go types, as encoded by
+// encoding/json. Named structs are components of the spec, referenced by
+// their name, or by their package and name if another package has a
+// struct of the same name.
+type schemaGenerator struct {
+	schemas map[string]interface{}
+	types   map[string]reflect.Type
+}
+
+var byteSliceType = reflect.TypeOf([]byte(nil))
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == byteSliceType {
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return g.object(t)
+		}
+		name := g.name(t)
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = nil // Registered first for recursive types
+			g.schemas[name] = g.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{} // Any value, e.g. of an interface
+}
+
+func (g *schemaGenerator) name(t reflect.Type) string {
+	name := t.Name()
+	if other, ok := g.types[name]; ok && other != t {
+		name = t.String() // e.g. types.Account
+	}
+	g.types[name] = t
+	return name
+}
+
+func (g *schemaGenerator) object(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, f := range jsonFields(t) {
+		schema := g.schema(f.typ)
+		if len(f.doc) > 0 {
+			if _, isRef := schema["$ref"]; isRef {
+				schema = map[string]interface{}{"allOf": []interface{}{schema}, "description": f.doc}
+			} else {
+				schema["description"] = f.doc
+			}
+		}
+		properties[f.name] = schema
+		if f.required {
+			required = append(required, f.name)
+		}
+	}
+	object := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		object["required"] = required
+	}
+	return object
+}
+
+// jsonField is a field of a struct encoded by encoding/json.
+type jsonField struct {
+	name     string
+	index    []int
+	typ      reflect.Type
+	doc      string
+	required bool
+}
+
+// jsonFields returns the fields of a struct encoded by encoding/json,
+// including those of its embedded structs.
+func jsonFields(t reflect.Type) []jsonField {
+	fields := []jsonField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (len(f.PkgPath) > 0 && !f.Anonymous) {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i:]
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && len(name) == 0 && ft.Kind() == reflect.Struct {
+			for _, ef := range jsonFields(ft) {
+				ef.index = append([]int{i}, ef.index...)
+				fields = append(fields, ef)
+			}
+			continue
+		}
+		if len(f.PkgPath) > 0 {
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{
+			name:     name,
+			index:    []int{i},
+			typ:      f.Type,
+			doc:      f.Tag.Get("doc"),
+			required: !strings.Contains(opts, ",omitempty"),
+		})
+	}
+	return fields
+}
+
+// missingFields returns the required string fields of a request body
+// which are empty, as documented by the spec.
+func missingFields(v interface{}) []string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	missing := []string{}
+	for _, f := range jsonFields(rv.Type()) {
+		if f.required && f.typ.Kind() == reflect.String && rv.FieldByIndex(f.index).Len() == 0 {
+			missing = append(missing, f.name)
+		}
+	}
+	return missing
+}
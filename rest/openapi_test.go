@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSpec(t *testing.T) {
+	spec := Spec()
+	if _, err := json.Marshal(spec); err != nil {
+		t.Fatalf("json.Marshal(Spec()) error = %v", err)
+	}
+
+	paths := spec["paths"].(map[string]interface{})
+	for _, rt := range routes {
+		path, ok := paths[rt.path].(map[string]interface{})
+		if !ok {
+			t.Errorf("Spec() has no path %s", rt.path)
+			continue
+		}
+		op, ok := path[strings.ToLower(rt.method)].(map[string]interface{})
+		if !ok || op["operationId"] != rt.name {
+			t.Errorf("Spec() has no operation %s %s", rt.method, rt.path)
+		}
+		if rt.body != nil && op["requestBody"] == nil {
+			t.Errorf("Spec() operation %s has no request body", rt.name)
+		}
+	}
+
+	schemas := spec["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	transfer, ok := schemas["TransferRequest"].(map[string]interface{})
+	if !ok {
+		t.Fatal("Spec() has no TransferRequest schema")
+	}
+	wantRequired := []string{"sender_id", "recipient_id", "amount", "currency"}
+	if !reflect.DeepEqual(transfer["required"], wantRequired) {
+		t.Errorf("TransferRequest required = %v, want %v", transfer["required"], wantRequired)
+	}
+	for _, name := range []string{"Account", "AccountList", "Balance", "ErrorResponse", "LegalEntity", "Receipt", "User"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("Spec() has no %s schema", name)
+		}
+	}
+}
+
+func Test_missingFields(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want []string
+	}{
+		{"complete", &MoneyRequest{Amount: "1", Currency: "EUR"}, []string{}},
+		{"missing", &MoneyRequest{Amount: "1"}, []string{"currency"}},
+		{"optional", &CreateAccountRequest{}, []string{}},
+		{"notStruct", "EUR", nil},
+	}
+	for _, tt := range tests {
+		if got := missingFields(tt.v); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. missingFields() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Package rest serves a REST API of the ledger over HTTP, whose OpenAPI
+// spec is generated from its routes.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// SpecPath is the path the OpenAPI spec is served at.
+const SpecPath = "/openapi.json"
+
+// Ledger sends the Txs and queries of the API, it's implemented by
+// *client.Client.
+type Ledger interface {
+	ChainID() string
+	Signer() client.Signer
+	Height(ctx context.Context) (uint64, error)
+	GetBlock(ctx context.Context, height uint64) (*client.Block, error)
+	NotifyBlocks(ctx context.Context) (<-chan struct{}, error)
+
+	CreateAccount(ctx context.Context, accountID string) (*client.TxReceipt, error)
+	CreateLegalEntity(ctx context.Context, entityID string, entityType byte, name string, parentID string) (*client.TxReceipt, error)
+	CreateUser(ctx context.Context, name string, pubKey crypto.PubKey, canCreate bool) (*client.TxReceipt, error)
+	TransferMoney(ctx context.Context, t client.Transfer, counterSigners ...client.Signer) (*client.TxReceipt, error)
+	IssueMoney(ctx context.Context, accountID string, amount int64, currency string) (*client.TxReceipt, error)
+	RedeemMoney(ctx context.Context, accountID string, amount int64, currency string) (*client.TxReceipt, error)
+	Broadcast(ctx context.Context, tx types.NoncedTx) (*client.TxReceipt, error)
+
+	GetAccount(ctx context.Context, id string) (types.AccountsReturned, error)
+	GetAccountAtHeight(ctx context.Context, id string, height uint64) (types.AccountsReturned, error)
+	ListAccounts(ctx context.Context, params url.Values) (types.AccountsReturned, error)
+	GetLegalEntity(ctx context.Context, id string) (types.LegalEntitiesReturned, error)
+	ListLegalEntities(ctx context.Context, params url.Values) (types.LegalEntitiesReturned, error)
+	GetLegalEntityBalances(ctx context.Context, id string, rollup bool) (types.BalanceReport, error)
+	GetUser(ctx context.Context, addr []byte) (types.UsersReturned, error)
+	ListUsers(ctx context.Context, params url.Values) (types.UsersReturned, error)
+	GetTransfer(ctx context.Context, id string) (types.TransfersReturned, error)
+	ListTransfers(ctx context.Context, params url.Values) (types.TransfersReturned, error)
+	GetCurrency(ctx context.Context, symbol string) (*types.CurrencyEntry, error)
+	ListCurrencies(ctx context.Context, params url.Values) (types.CurrenciesReturned, error)
+	GetSupply(ctx context.Context) (types.SupplyReport, error)
+	GetTx(ctx context.Context, hash []byte) (*types.TxRecord, error)
+	GetTxResult(ctx context.Context, clientID string) (*types.TxResult, error)
+}
+
+// Server handles the requests of the API by sending Txs and queries to
+// the ledger. Every request must be signed by a user of the ledger, see
+// SignRequest.
+//
+// The Txs of the other write requests than POST /txs are signed by the
+// ledger's signer, which the ledger records as their actor, e.g. a user
+// created by POST /users gets the signer's permissions. Hence the
+// webserver only sends them for the callers whose permissions cover the
+// signer's, see authorize: the others must sign their Txs themselves.
+//
+// Callers only read the objects of their legal entity and its descendants,
+// unless their legal entity is the clearing house, see readScope.
+type Server struct {
+	ctx           context.Context // Of the server's lifetime
+	ledger        Ledger
+	now           func() time.Time
+	signatures    signatureCache
+	events        eventHub
+	retryInterval time.Duration // Of the subscription to the ledger's new blocks
+}
+
+// New creates a Server of the ledger, whose background work, e.g. following
+// the ledger's blocks for GET /events, stops once ctx is done. The Txs are
+// signed by the ledger's client, which must hence have a signer unless the
+// API is read-only. The signer only sends the Txs of the users of its own
+// legal entity, and the other users send theirs signed offline, see POST
+// /txs.
+func New(ctx context.Context, ledger Ledger) *Server {
+	return &Server{ctx: ctx, ledger: ledger, now: time.Now, retryInterval: DefaultEventRetryInterval}
+}
+
+// AddRoutes registers the API's routes and its OpenAPI spec on r. The spec
+// is the only resource which doesn't need authentication.
+func (s *Server) AddRoutes(r *mux.Router) {
+	for _, rt := range routes {
+		r.Handle(rt.path, s.handler(rt)).Methods(rt.method)
+	}
+	r.HandleFunc(SpecPath, func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, Spec())
+	}).Methods(http.MethodGet)
+}
+
+// Handle answers the requests authenticated by s with the JSON response of
+// handle, or else with an ErrorResponse, as the API's routes do.
+func (s *Server) Handle(handle func(r *http.Request) (interface{}, error)) http.Handler {
+	return s.Authenticated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v, err := handle(r)
+		if err != nil {
+			writeError(w, toError(err))
+			return
+		}
+		writeJSON(w, http.StatusOK, v)
+	}))
+}
+
+// handler authenticates the request, checks that its user is allowed to
+// send the route's Tx and checks its parameters. It then answers with the
+// JSON response of the route's handle function, or else with an
+// ErrorResponse.
+func (s *Server) handler(rt route) http.Handler {
+	return s.Authenticated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rt.txType != 0 {
+			if err := s.authorize(r.Context(), callerOf(r), rt.txType); err != nil {
+				writeError(w, toError(err))
+				return
+			}
+		}
+		if err := rt.checkParams(r.URL.Query()); err != nil {
+			writeError(w, err)
+			return
+		}
+		v, err := rt.handle(s, w, r)
+		if err != nil {
+			writeError(w, toError(err))
+			return
+		}
+		if !rt.stream {
+			writeJSON(w, rt.status, v)
+		}
+	}))
+}
+
+// param is a parameter of a route, either in its path or its query string.
+type param struct {
+	name        string
+	in          string // path, query or header
+	typ         string // string, integer or boolean
+	description string
+}
+
+func pathParam(name string, description string) param {
+	return param{name: name, in: "path", typ: "string", description: description}
+}
+
+func queryParam(name string, typ string, description string) param {
+	return param{name: name, in: "query", typ: typ, description: description}
+}
+
+// Parameters of the paginated lists
+var pageParams = []param{
+	queryParam("cursor", "string", "Cursor of the page, the previous page's next"),
+	queryParam("limit", "integer", "Maximum number of items of the page"),
+}
+
+// route is an operation of the API, documented by the spec.
+type route struct {
+	name     string // operationId of the spec
+	method   string
+	path     string // gorilla/mux template, e.g. /accounts/{id}
+	summary  string
+	params   []param
+	body     interface{} // Zero value of the request body, nil if none
+	response interface{} // Zero value of the response
+	status   int         // Status of a successful response
+	txType   byte        // Type of the Tx the ledger's signer sends, 0 if none
+	stream   bool        // Whether handle streams the responses as server-sent events itself
+	handle   func(s *Server, w http.ResponseWriter, r *http.Request) (interface{}, error)
+}
+
+// checkParams rejects unknown query parameters and values of the wrong type.
+func (rt route) checkParams(query url.Values) *Error {
+	for name, values := range query {
+		p, ok := rt.param(name, "query")
+		if !ok {
+			return badRequest("Unknown parameter %q", name)
+		}
+		for _, v := range values {
+			var err error
+			switch p.typ {
+			case "integer":
+				_, err = strconv.ParseUint(v, 10, 64)
+			case "boolean":
+				_, err = strconv.ParseBool(v)
+			}
+			if err != nil {
+				return badRequest("Invalid %s %q, expected %s", name, v, p.typ)
+			}
+		}
+	}
+	return nil
+}
+
+func (rt route) param(name string, in string) (param, bool) {
+	for _, p := range rt.params {
+		if p.name == name && p.in == in {
+			return p, true
+		}
+	}
+	return param{}, false
+}
+
+// copyParams copies the query parameters of a request to the parameters
+// of a ledger's query, renamed by names.
+func copyParams(query url.Values, names map[string]string) url.Values {
+	params := url.Values{}
+	for name, ledgerName := range names {
+		if v := query.Get(name); len(v) > 0 {
+			params.Set(ledgerName, v)
+		}
+	}
+	return params
+}
+
+// decodeBody decodes a JSON request body into v, then checks that its
+// required fields are set. An empty body is an empty object.
+func decodeBody(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		return badRequest("Invalid JSON body: %v", err)
+	}
+	if missing := missingFields(v); len(missing) > 0 {
+		return badRequest("Missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(ErrorResponse{Code: http.StatusText(status), Message: fmt.Sprintf("Couldn't make the response: %v", err)})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+	w.Write([]byte("\n"))
+}
+
+func writeError(w http.ResponseWriter, err *Error) {
+	if err.Status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", AuthScheme)
+	}
+	writeJSON(w, err.Status, ErrorResponse{Code: err.Code, Message: err.Message})
+}
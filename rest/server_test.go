@@ -0,0 +1,361 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/client"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-crypto"
+)
+
+// fakeLedger answers queries of its accounts, users, legal entities,
+// transfers, blocks and the EUR currency, and records the Txs it's asked
+// to send. It can't notify new blocks.
+type fakeLedger struct {
+	height    uint64
+	accounts  map[string]*types.Account
+	balances  map[string][]types.Balance // By account ID, 100.50 EUR if not set
+	transfers []*types.Transfer
+	blocks    map[uint64]*client.Block
+	users     map[string]*types.User // By address
+	entities  map[string]*types.LegalEntity
+	signer    client.Signer
+	params    url.Values // Parameters of the last list query
+	txs       []string
+	err       error // Error of the Txs
+}
+
+var allPerms = types.NewPermByTxType(types.TxTypeTransfer, types.TxTypeCreateAccount, types.TxTypeCreateLegalEntity,
+	types.TxTypeCreateUser, types.TxTypeIssue, types.TxTypeRedeem)
+
+// newFakeLedger returns a fakeLedger of account a of legal entity e, a
+// child of the clearing house ch, whose signer and trader are users of e
+// allowed to send any Tx.
+func newFakeLedger(trader client.Signer) *fakeLedger {
+	f := &fakeLedger{
+		accounts: map[string]*types.Account{"a": {ID: "a", EntityID: "e"}},
+		users:    map[string]*types.User{},
+		entities: map[string]*types.LegalEntity{
+			"ch": {ID: "ch", Type: types.EntityTypeCHByte, Permissions: allPerms},
+			"e":  {ID: "e", EntityID: "ch", Type: types.EntityTypeGCMByte, Permissions: allPerms},
+		},
+		signer: client.NewKeySigner(crypto.GenPrivKeyEd25519()),
+	}
+	f.addUser(f.signer, "e", allPerms)
+	f.addUser(trader, "e", allPerms)
+	return f
+}
+
+func (f *fakeLedger) addUser(signer client.Signer, entityID string, perms types.Perm) {
+	f.users[string(signer.Address())] = &types.User{PubKey: signer.PubKey(), Name: "u", EntityID: entityID, Permissions: perms}
+}
+
+var fakeReceipt = &client.TxReceipt{ClientID: "c", Hash: []byte{0x5F, 0x2B}, Height: 7}
+
+func (f *fakeLedger) send(format string, args ...interface{}) (*client.TxReceipt, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.txs = append(f.txs, fmt.Sprintf(format, args...))
+	return fakeReceipt, nil
+}
+
+func (f *fakeLedger) ChainID() string { return "test_chain_id" }
+
+func (f *fakeLedger) Signer() client.Signer { return f.signer }
+
+func (f *fakeLedger) Height(ctx context.Context) (uint64, error) { return f.height, nil }
+
+func (f *fakeLedger) GetBlock(ctx context.Context, height uint64) (*client.Block, error) {
+	if b, ok := f.blocks[height]; ok {
+		return b, nil
+	}
+	return nil, &client.Error{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown block"}
+}
+
+func (f *fakeLedger) NotifyBlocks(ctx context.Context) (<-chan struct{}, error) {
+	return nil, errors.New("No websocket")
+}
+
+func (f *fakeLedger) CreateAccount(ctx context.Context, accountID string) (*client.TxReceipt, error) {
+	return f.send("CreateAccount %s", accountID)
+}
+
+func (f *fakeLedger) CreateLegalEntity(ctx context.Context, entityID string, entityType byte, name string, parentID string) (*client.TxReceipt, error) {
+	return f.send("CreateLegalEntity %s %d %s %s", entityID, entityType, name, parentID)
+}
+
+func (f *fakeLedger) CreateUser(ctx context.Context, name string, pubKey crypto.PubKey, canCreate bool) (*client.TxReceipt, error) {
+	return f.send("CreateUser %s %v", name, canCreate)
+}
+
+func (f *fakeLedger) TransferMoney(ctx context.Context, t client.Transfer, counterSigners ...client.Signer) (*client.TxReceipt, error) {
+	return f.send("TransferMoney %s %s %d %s %s", t.SenderID, t.RecipientID, t.Amount, t.Currency, t.Reference)
+}
+
+func (f *fakeLedger) IssueMoney(ctx context.Context, accountID string, amount int64, currency string) (*client.TxReceipt, error) {
+	return f.send("IssueMoney %s %d %s", accountID, amount, currency)
+}
+
+func (f *fakeLedger) RedeemMoney(ctx context.Context, accountID string, amount int64, currency string) (*client.TxReceipt, error) {
+	return f.send("RedeemMoney %s %d %s", accountID, amount, currency)
+}
+
+func (f *fakeLedger) Broadcast(ctx context.Context, tx types.NoncedTx) (*client.TxReceipt, error) {
+	return f.send("Broadcast %s", tx.GetClientID())
+}
+
+func (f *fakeLedger) GetAccount(ctx context.Context, id string) (types.AccountsReturned, error) {
+	acc, ok := f.accounts[id]
+	if !ok {
+		return types.AccountsReturned{}, &client.Error{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown account"}
+	}
+	balances, ok := f.balances[id]
+	if !ok {
+		balances = []types.Balance{{Currency: "EUR", Amount: 10050, Display: "100.50"}}
+	}
+	return types.AccountsReturned{Account: []*types.Account{acc}, Balances: map[string][]types.Balance{id: balances}}, nil
+}
+
+func (f *fakeLedger) GetAccountAtHeight(ctx context.Context, id string, height uint64) (types.AccountsReturned, error) {
+	return f.GetAccount(ctx, id)
+}
+
+func (f *fakeLedger) ListAccounts(ctx context.Context, params url.Values) (types.AccountsReturned, error) {
+	f.params = params
+	return types.AccountsReturned{Account: []*types.Account{}}, nil
+}
+
+func (f *fakeLedger) GetLegalEntity(ctx context.Context, id string) (types.LegalEntitiesReturned, error) {
+	entity, ok := f.entities[id]
+	if !ok {
+		return types.LegalEntitiesReturned{}, &client.Error{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown legal entity"}
+	}
+	return types.LegalEntitiesReturned{LegalEntities: []*types.LegalEntity{entity}}, nil
+}
+
+func (f *fakeLedger) ListLegalEntities(ctx context.Context, params url.Values) (types.LegalEntitiesReturned, error) {
+	f.params = params
+	return types.LegalEntitiesReturned{LegalEntities: []*types.LegalEntity{}}, nil
+}
+
+func (f *fakeLedger) GetLegalEntityBalances(ctx context.Context, id string, rollup bool) (types.BalanceReport, error) {
+	return types.BalanceReport{EntityID: id, Rollup: rollup}, nil
+}
+
+func (f *fakeLedger) GetUser(ctx context.Context, addr []byte) (types.UsersReturned, error) {
+	user, ok := f.users[string(addr)]
+	if !ok {
+		return types.UsersReturned{}, &client.Error{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown user"}
+	}
+	return types.UsersReturned{Users: []*types.User{user}}, nil
+}
+
+func (f *fakeLedger) ListUsers(ctx context.Context, params url.Values) (types.UsersReturned, error) {
+	f.params = params
+	return types.UsersReturned{}, nil
+}
+
+func (f *fakeLedger) GetTransfer(ctx context.Context, id string) (types.TransfersReturned, error) {
+	return types.TransfersReturned{}, nil
+}
+
+// ListTransfers returns pages of 2 transfers by default, like state.Index.Range.
+func (f *fakeLedger) ListTransfers(ctx context.Context, params url.Values) (types.TransfersReturned, error) {
+	f.params = params
+	cursor, _ := strconv.Atoi(params.Get("cursor"))
+	limit := 2
+	if l, err := strconv.Atoi(params.Get("limit")); err == nil {
+		limit = l
+	}
+	returned := types.TransfersReturned{Transfers: []*types.Transfer{}}
+	for pos := cursor; pos < len(f.transfers) && len(returned.Transfers) < limit; pos++ {
+		returned.Transfers = append(returned.Transfers, f.transfers[pos])
+	}
+	if next := cursor + len(returned.Transfers); next < len(f.transfers) {
+		returned.Next = strconv.Itoa(next)
+	}
+	return returned, nil
+}
+
+func (f *fakeLedger) GetCurrency(ctx context.Context, symbol string) (*types.CurrencyEntry, error) {
+	if symbol != "EUR" {
+		return nil, &client.Error{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown currency"}
+	}
+	return &types.CurrencyEntry{Symbol: "EUR", DecimalPlaces: 2, MinimumUnit: 1}, nil
+}
+
+func (f *fakeLedger) ListCurrencies(ctx context.Context, params url.Values) (types.CurrenciesReturned, error) {
+	f.params = params
+	return types.CurrenciesReturned{}, nil
+}
+
+func (f *fakeLedger) GetSupply(ctx context.Context) (types.SupplyReport, error) {
+	return types.SupplyReport{}, nil
+}
+
+func (f *fakeLedger) GetTx(ctx context.Context, hash []byte) (*types.TxRecord, error) {
+	return &types.TxRecord{Hash: hash}, nil
+}
+
+func (f *fakeLedger) GetTxResult(ctx context.Context, clientID string) (*types.TxResult, error) {
+	return &types.TxResult{ClientID: clientID}, nil
+}
+
+// serve sends a request signed by signer, unless it's nil, to a Server of
+// the ledger.
+func serve(t *testing.T, s *Server, method string, path string, body string, signer client.Signer) *httptest.ResponseRecorder {
+	r := mux.NewRouter()
+	s.AddRoutes(r)
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if signer != nil {
+		if err := SignRequest(req, []byte(body), signer); err != nil {
+			t.Fatalf("SignRequest() error = %v", err)
+		}
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestServer(t *testing.T) {
+	trader := client.NewKeySigner(crypto.GenPrivKeyEd25519())
+	eur := []types.Balance{{Currency: "EUR", Amount: 10050, Display: "100.50"}}
+	pubKey := crypto.GenPrivKeyEd25519().PubKey()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		txErr      error
+		wantStatus int
+		want       interface{} // Response body, nil if not checked
+		wantTxs    []string
+		wantParams url.Values
+	}{
+		{"getAccount", "GET", "/accounts/a", "", nil, http.StatusOK,
+			Account{ID: "a", EntityID: "e", Balances: eur}, nil, nil},
+		{"getUnknownAccount", "GET", "/accounts/b", "", nil, http.StatusNotFound,
+			ErrorResponse{Code: "BaseUnknownAddress", Message: "Unknown account"}, nil, nil},
+		{"getAccountInvalidHeight", "GET", "/accounts/a?height=-1", "", nil, http.StatusBadRequest, nil, nil, nil},
+		{"listAccounts", "GET", "/accounts?entity=e&non_zero=true&limit=10", "", nil, http.StatusOK,
+			AccountList{Accounts: []Account{}}, nil,
+			url.Values{"entity_id": {"e"}, "non_zero": {"true"}, "limit": {"10"}, "decimal": {"true"}}},
+		{"listAccountsUnknownParam", "GET", "/accounts?entity_id=e", "", nil, http.StatusBadRequest,
+			ErrorResponse{Code: "Bad Request", Message: `Unknown parameter "entity_id"`}, nil, nil},
+		{"listEntities", "GET", "/entities?type=icm&parent=e", "", nil, http.StatusOK, nil, nil,
+			url.Values{"type": {"icm"}, "parent_id": {"e"}}},
+		{"listUsers", "GET", "/users?entity=e", "", nil, http.StatusOK, UserList{Users: []User{}}, nil,
+			url.Values{"entity_id": {"e"}}},
+		{"getUserInvalidAddress", "GET", "/users/xyz", "", nil, http.StatusBadRequest, nil, nil, nil},
+		{"createAccount", "POST", "/accounts", `{"account_id": "a"}`, nil, http.StatusCreated,
+			Receipt{ClientID: "c", Hash: "5F2B", Height: 7}, []string{"CreateAccount a"}, nil},
+		{"createAccountWithoutSigner", "POST", "/accounts", `{"account_id": "a"}`, client.ErrNoSigner,
+			http.StatusServiceUnavailable, nil, nil, nil},
+		{"createEntity", "POST", "/entities", `{"entity_id": "g", "type": "gcm", "name": "GCM", "parent_id": "ch"}`, nil,
+			http.StatusCreated, nil, []string{"CreateLegalEntity g 2 GCM ch"}, nil},
+		{"createEntityInvalidType", "POST", "/entities", `{"type": "bank", "parent_id": "ch"}`, nil,
+			http.StatusBadRequest, nil, nil, nil},
+		{"createUser", "POST", "/users", `{"name": "trader", "pub_key": "` + client.Encode(pubKey.Bytes()) + `"}`, nil,
+			http.StatusCreated, nil, []string{"CreateUser trader false"}, nil},
+		{"createTransfer", "POST", "/transfers",
+			`{"sender_id": "a", "recipient_id": "b", "amount": "100.50", "currency": "EUR", "reference": "trade-1"}`, nil,
+			http.StatusCreated, nil, []string{"TransferMoney a b 10050 EUR trade-1"}, nil},
+		{"createTransferMissingFields", "POST", "/transfers", `{"recipient_id": "b", "currency": "EUR"}`, nil,
+			http.StatusBadRequest, ErrorResponse{Code: "Bad Request", Message: "Missing sender_id, amount"}, nil, nil},
+		{"createTransferUnknownCurrency", "POST", "/transfers",
+			`{"sender_id": "a", "recipient_id": "b", "amount": "1", "currency": "XXX"}`, nil, http.StatusBadRequest, nil, nil, nil},
+		{"createTransferInvalidAmount", "POST", "/transfers",
+			`{"sender_id": "a", "recipient_id": "b", "amount": "1.005", "currency": "EUR"}`, nil, http.StatusBadRequest, nil, nil, nil},
+		{"createTransferRejected", "POST", "/transfers",
+			`{"sender_id": "a", "recipient_id": "b", "amount": "1", "currency": "EUR"}`,
+			&client.Error{Code: abci.CodeType_BaseInsufficientFunds, Log: "Insufficient funds"},
+			http.StatusUnprocessableEntity, ErrorResponse{Code: "BaseInsufficientFunds", Message: "Insufficient funds"}, nil, nil},
+		{"issueMoney", "POST", "/accounts/a/issue", `{"amount": "5", "currency": "EUR"}`, nil,
+			http.StatusOK, nil, []string{"IssueMoney a 500 EUR"}, nil},
+		{"broadcastWrongChain", "POST", "/txs", `{"chain_id": "other", "tx": null}`, nil, http.StatusBadRequest, nil, nil, nil},
+		{"invalidJSON", "POST", "/accounts", `{"account_id":`, nil, http.StatusBadRequest, nil, nil, nil},
+	}
+	for _, tt := range tests {
+		ledger := newFakeLedger(trader)
+		ledger.err = tt.txErr
+		rec := serve(t, New(context.Background(), ledger), tt.method, tt.path, tt.body, trader)
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%q. status = %d, want %d, body %s", tt.name, rec.Code, tt.wantStatus, rec.Body)
+			continue
+		}
+		if tt.want != nil {
+			got := reflect.New(reflect.TypeOf(tt.want))
+			if err := json.Unmarshal(rec.Body.Bytes(), got.Interface()); err != nil || !reflect.DeepEqual(got.Elem().Interface(), tt.want) {
+				t.Errorf("%q. body = %s, want %v", tt.name, rec.Body, tt.want)
+			}
+		}
+		if tt.wantTxs != nil && !reflect.DeepEqual(ledger.txs, tt.wantTxs) {
+			t.Errorf("%q. txs = %v, want %v", tt.name, ledger.txs, tt.wantTxs)
+		}
+		if tt.wantParams != nil && !reflect.DeepEqual(ledger.params, tt.wantParams) {
+			t.Errorf("%q. params = %v, want %v", tt.name, ledger.params, tt.wantParams)
+		}
+	}
+}
+
+func TestServer_createLocation(t *testing.T) {
+	trader := client.NewKeySigner(crypto.GenPrivKeyEd25519())
+	rec := serve(t, New(context.Background(), newFakeLedger(trader)), "POST", "/accounts", "", trader)
+
+	if rec.Code != http.StatusCreated || !strings.HasPrefix(rec.Header().Get("Location"), "/accounts/") {
+		t.Errorf("POST /accounts without body = %d, Location %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
+
+func TestServer_Handle(t *testing.T) {
+	trader := client.NewKeySigner(crypto.GenPrivKeyEd25519())
+	s := New(context.Background(), newFakeLedger(trader))
+
+	tests := []struct {
+		name       string
+		signer     client.Signer
+		err        error
+		wantStatus int
+		want       string
+	}{
+		{"ok", trader, nil, http.StatusOK, `{"id":"a"}` + "\n"},
+		{"unsigned", nil, nil, http.StatusUnauthorized, ""},
+		{"ledgerError", trader, &client.Error{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown account"},
+			http.StatusNotFound, `{"code":"BaseUnknownAddress","message":"Unknown account"}` + "\n"},
+	}
+	for _, tt := range tests {
+		h := s.Handle(func(r *http.Request) (interface{}, error) {
+			if tt.err != nil {
+				return nil, tt.err
+			}
+			return map[string]string{"id": "a"}, nil
+		})
+		req := httptest.NewRequest("GET", "/view/", nil)
+		if tt.signer != nil {
+			if err := SignRequest(req, nil, tt.signer); err != nil {
+				t.Fatalf("SignRequest() error = %v", err)
+			}
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != tt.wantStatus || (len(tt.want) > 0 && rec.Body.String() != tt.want) {
+			t.Errorf("%q. response = %d %s, want %d %s", tt.name, rec.Code, rec.Body, tt.wantStatus, tt.want)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	basecoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+// CurrencyKey generates a data store's unique key for a registry's currency
+func CurrencyKey(symbol string) []byte {
+	return append([]byte("base/c/"), symbol...)
+}
+
+// CurrencyIndexKey generates the key prefix of the currency registry's index
+func CurrencyIndexKey() string {
+	return "base/i/c"
+}
+
+// GetCurrency retrieves a registry's currency from the given store
+func GetCurrency(store basecoin.KVStore, symbol string) *types.CurrencyEntry {
+	data := store.Get(CurrencyKey(symbol))
+	if len(data) == 0 {
+		return nil
+	}
+	var currency *types.CurrencyEntry
+	err := wire.ReadBinaryBytes(data, &currency)
+	if err != nil {
+		panic(common.Fmt("Error reading currency %X error: %v",
+			data, err.Error()))
+	}
+	return currency
+}
+
+// SetCurrency stores a registry's currency to the given store
+func SetCurrency(store basecoin.KVStore, symbol string, currency *types.CurrencyEntry) {
+	store.Set(CurrencyKey(symbol), wire.BinaryBytes(currency))
+	NewIndex(store, CurrencyIndexKey()).Add(symbol)
+}
+
+// GetCurrency retrieves a currency from the registry
+func (s *State) GetCurrency(symbol string) *types.CurrencyEntry {
+	return GetCurrency(s.store, symbol)
+}
+
+// SetCurrency adds or modifies a currency of the registry
+func (s *State) SetCurrency(symbol string, currency *types.CurrencyEntry) {
+	SetCurrency(s.store, symbol, currency)
+}
+
+// CurrencyIndex returns the index of the registry's currencies
+func (s *State) CurrencyIndex() *Index {
+	return NewIndex(s.store, CurrencyIndexKey())
+}
+
+// SetCurrencies registers the given currencies, in order.
+func SetCurrencies(state *State, currencies []*types.CurrencyEntry) {
+	for _, c := range currencies {
+		state.SetCurrency(c.Symbol, c)
+	}
+}
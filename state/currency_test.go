@@ -0,0 +1,77 @@
+package state
+
+import (
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+)
+
+func TestExecTx_currencyRegistry(t *testing.T) {
+	// Set up fixtures
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID(chainID)
+	ch := testutil.RandCH()
+	chUser := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0]
+	gcm := testutil.RandGCM(nil)
+	gcm.Permissions = gcm.Permissions.Add(types.PermCHOnly)
+	gcmUser := testutil.RandUsersWithLegalEntity(1, gcm, gcm.Permissions)[0]
+	account := testutil.RandAccount(gcm)
+	account.Wallets = nil
+	for _, e := range []*types.LegalEntity{ch, gcm} {
+		s.SetLegalEntity(e.ID, e)
+	}
+	for _, u := range []*types.PrivUser{chUser, gcmUser} {
+		s.SetUser(u.User.PubKey.Address(), &u.User)
+	}
+	s.SetAccount(account.ID, account)
+
+	signed := func(u *types.PrivUser, tx types.SignedTx) types.Tx {
+		tx.SignTx(u.PrivKey, chainID)
+		return tx
+	}
+	addr := chUser.User.PubKey.Address()
+	wheat := types.CurrencyEntry{Symbol: "WHEAT-BU", Name: "Wheat bushel", DecimalPlaces: 3, MinimumUnit: 250}
+	wheatUpdated := wheat
+	wheatUpdated.MinimumUnit = 500
+	wheatRescaled := wheat
+	wheatRescaled.DecimalPlaces = 2
+	issue := func(amount int64, nonce uint64) types.Tx {
+		return signed(chUser, &types.IssueTx{Address: addr, Nonce: nonce, AccountID: account.ID, Amount: amount, Currency: "WHEAT-BU"})
+	}
+
+	tests := []struct {
+		name string
+		tx   types.Tx
+		want abci.CodeType
+	}{
+		{"issueUnregistered", issue(1000, 1), abci.CodeType_BaseInvalidInput},
+		{"addByNonCH", signed(gcmUser, &types.AddCurrencyTx{Address: gcmUser.User.PubKey.Address(), Nonce: 1, Currency: wheat}), abci.CodeType_Unauthorized},
+		{"add", signed(chUser, &types.AddCurrencyTx{Address: addr, Nonce: 1, Currency: wheat}), abci.CodeType_OK},
+		{"addDuplicate", signed(chUser, &types.AddCurrencyTx{Address: addr, Nonce: 2, Currency: wheat}), abci.CodeType_BaseInvalidInput},
+		{"issueInvalidAmount", issue(100, 2), abci.CodeType_BaseInvalidInput},
+		{"issue", issue(1000, 2), abci.CodeType_OK},
+		{"updateDecimalPlaces", signed(chUser, &types.UpdateCurrencyTx{Address: addr, Nonce: 3, Currency: wheatRescaled}), abci.CodeType_BaseInvalidInput},
+		{"update", signed(chUser, &types.UpdateCurrencyTx{Address: addr, Nonce: 3, Currency: wheatUpdated}), abci.CodeType_OK},
+		{"issueInvalidUpdatedAmount", issue(250, 4), abci.CodeType_BaseInvalidInput},
+		{"retire", signed(chUser, &types.RetireCurrencyTx{Address: addr, Nonce: 4, Symbol: "WHEAT-BU"}), abci.CodeType_OK},
+		{"retireAgain", signed(chUser, &types.RetireCurrencyTx{Address: addr, Nonce: 5, Symbol: "WHEAT-BU"}), abci.CodeType_BaseInvalidInput},
+		{"issueRetired", issue(500, 5), abci.CodeType_BaseInvalidInput},
+		{"redeemRetired", signed(chUser, &types.RedeemTx{Address: addr, Nonce: 5, AccountID: account.ID, Amount: 1000, Currency: "WHEAT-BU"}), abci.CodeType_OK},
+		{"updateRetired", signed(chUser, &types.UpdateCurrencyTx{Address: addr, Nonce: 6, Currency: wheatUpdated}), abci.CodeType_BaseInvalidInput},
+	}
+	for _, tt := range tests {
+		if got := ExecTx(s, nil, tt.tx, false, nil); got.Code != tt.want {
+			t.Errorf("%q. ExecTx() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := s.GetCurrency("WHEAT-BU"); got == nil || !got.Retired || got.MinimumUnit != 500 {
+		t.Errorf("State.GetCurrency() = %v, want retired with minimum unit 500", got)
+	}
+	if got := s.CurrencyIndex().Len(); got != 1 {
+		t.Errorf("State.CurrencyIndex().Len() = %v, want 1", got)
+	}
+}
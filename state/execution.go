@@ -1,20 +1,25 @@
 package state
 
 import (
+	"bytes"
 	"encoding/json"
-	
+
 	abci "github.com/tendermint/abci/types"
 	bctypes "github.com/tendermint/basecoin/types"
 	"github.com/tendermint/clearchain/types"
 	"github.com/tendermint/go-common"
+	crypto "github.com/tendermint/go-crypto"
 	"github.com/tendermint/go-events"
 )
 
 func transfer(state *State, tx *types.TransferTx, isCheckTx bool) abci.Result {
 	// // Validate basic structure
-	if res := tx.ValidateBasic(); res.IsErr() {
+	if res := tx.ValidateBasic(state); res.IsErr() {
 		return res.PrependLog("in ValidateBasic()")
 	}
+	if res := validateCurrencyNotRetired(state, tx.Sender.Currency); res.IsErr() {
+		return res
+	}
 
 	// Retrieve Committer's data
 	user := state.GetUser(tx.Committer.Address)
@@ -46,11 +51,6 @@ func transfer(state *State, tx *types.TransferTx, isCheckTx bool) abci.Result {
 		return abci.ErrUnauthorized.AppendLog("Recipient's account does not belong to any LegalEntity")
 	}
 
-	// Validate sender's Account
-	if res := validateWalletSequence(senderAccount, tx.Sender); res.IsErr() {
-		return res.PrependLog("in validateWalletSequence()")
-	}
-
 	// Generate byte-to-byte signature
 	signBytes := tx.SignBytes(state.GetChainID())
 
@@ -70,9 +70,18 @@ func transfer(state *State, tx *types.TransferTx, isCheckTx bool) abci.Result {
 		return res.PrependLog("in validateCounterSigners()")
 	}
 
-	// Apply changes
-	applyChangesToInput(state, tx.Sender, senderAccount, isCheckTx)
-	applyChangesToOutput(state, tx.Sender, tx.Recipient, recipientAccount, isCheckTx)
+	// Apply changes, both balances are checked for overflow before storing either account
+	if res := applyChanges(senderAccount, tx.Sender.Currency, tx.Sender.Amount, false); res.IsErr() {
+		return res
+	}
+	if res := applyChanges(recipientAccount, tx.Sender.Currency, tx.Sender.Amount, true); res.IsErr() {
+		return res
+	}
+	if !isCheckTx {
+		state.SetAccount(senderAccount.ID, senderAccount)
+		state.SetAccount(recipientAccount.ID, recipientAccount)
+		state.SetTransfer(types.NewTransfer(tx, state.BlockHeight()))
+	}
 
 	return abci.OK
 
@@ -200,6 +209,161 @@ func createUser(state *State, tx *types.CreateUserTx, isCheckTx bool) abci.Resul
 		return abci.ErrBaseDuplicateAddress.AppendLog(common.Fmt("User already exists: %q", tx.PubKey.Address()))
 	}
 	makeNewUser(state, creator, tx, isCheckTx)
+	if !isCheckTx {
+		return SetUserInIndex(state, tx.PubKey.Address(), creator.EntityID)
+	}
+
+	return abci.OK
+}
+
+func issue(state *State, tx *types.IssueTx, isCheckTx bool) abci.Result {
+	// // Validate basic structure
+	if res := tx.ValidateBasic(state); res.IsErr() {
+		return res.PrependLog("in ValidateBasic()")
+	}
+	if res := validateCurrencyNotRetired(state, tx.Currency); res.IsErr() {
+		return res
+	}
+
+	// Validate the clearing house user's permissions and signature
+	if res := validateCHCommitter(state, tx.Address, tx, tx.Signature); res.IsErr() {
+		return res
+	}
+
+	// Get the account
+	account := state.GetAccount(tx.AccountID)
+	if account == nil {
+		return abci.ErrBaseUnknownAddress.AppendLog("Account is unknown")
+	}
+	in := types.TxTransferSender{AccountID: tx.AccountID, Amount: tx.Amount, Currency: tx.Currency}
+
+	// Credit the account and increase the supply, unless either overflows
+	supply, err := types.Amount(state.GetSupply(tx.Currency)).Add(types.Amount(tx.Amount))
+	if err != nil {
+		return abci.ErrBaseInvalidOutput.AppendLog(common.Fmt("Supply of %s would overflow: %v", tx.Currency, err))
+	}
+	if res := applyChangesToOutput(state, in, types.TxTransferRecipient{AccountID: tx.AccountID}, account, isCheckTx); res.IsErr() {
+		return res
+	}
+	if !isCheckTx {
+		state.SetSupply(tx.Currency, int64(supply))
+	}
+
+	return abci.OK
+}
+
+func redeem(state *State, tx *types.RedeemTx, isCheckTx bool) abci.Result {
+	// // Validate basic structure, retired currencies can still be redeemed
+	if res := tx.ValidateBasic(state); res.IsErr() {
+		return res.PrependLog("in ValidateBasic()")
+	}
+
+	// Validate the clearing house user's permissions and signature
+	if res := validateCHCommitter(state, tx.Address, tx, tx.Signature); res.IsErr() {
+		return res
+	}
+
+	// Get the account and validate its wallet's balance
+	account := state.GetAccount(tx.AccountID)
+	if account == nil {
+		return abci.ErrBaseUnknownAddress.AppendLog("Account is unknown")
+	}
+	in := types.TxTransferSender{AccountID: tx.AccountID, Amount: tx.Amount, Currency: tx.Currency}
+	if wal := account.GetWallet(tx.Currency); wal == nil || wal.Balance < tx.Amount {
+		return abci.ErrBaseInsufficientFunds.AppendLog(common.Fmt("Insufficient funds to redeem %d %s", tx.Amount, tx.Currency))
+	}
+
+	// Debit the account and decrease the supply, unless either overflows
+	supply, err := types.Amount(state.GetSupply(tx.Currency)).Sub(types.Amount(tx.Amount))
+	if err != nil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Supply of %s would overflow: %v", tx.Currency, err))
+	}
+	if res := applyChangesToInput(state, in, account, isCheckTx); res.IsErr() {
+		return res
+	}
+	if !isCheckTx {
+		state.SetSupply(tx.Currency, int64(supply))
+	}
+
+	return abci.OK
+}
+
+func addCurrency(state *State, tx *types.AddCurrencyTx, isCheckTx bool) abci.Result {
+	// // Validate basic structure
+	if res := tx.ValidateBasic(); res.IsErr() {
+		return res.PrependLog("in ValidateBasic()")
+	}
+
+	// Validate the clearing house user's permissions and signature
+	if res := validateCHCommitter(state, tx.Address, tx, tx.Signature); res.IsErr() {
+		return res
+	}
+
+	// Register the new currency
+	if c := state.GetCurrency(tx.Currency.Symbol); c != nil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Currency already exists: %q", tx.Currency.Symbol))
+	}
+	if !isCheckTx {
+		currency := tx.Currency
+		state.SetCurrency(currency.Symbol, &currency)
+	}
+
+	return abci.OK
+}
+
+func updateCurrency(state *State, tx *types.UpdateCurrencyTx, isCheckTx bool) abci.Result {
+	// // Validate basic structure
+	if res := tx.ValidateBasic(); res.IsErr() {
+		return res.PrependLog("in ValidateBasic()")
+	}
+
+	// Validate the clearing house user's permissions and signature
+	if res := validateCHCommitter(state, tx.Address, tx, tx.Signature); res.IsErr() {
+		return res
+	}
+
+	// Update the currency, existing amounts must keep their value
+	current := state.GetCurrency(tx.Currency.Symbol)
+	if current == nil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Unsupported currency: %q", tx.Currency.Symbol))
+	}
+	if current.Retired {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Currency is retired: %q", tx.Currency.Symbol))
+	}
+	if current.DecimalPlaces != tx.Currency.DecimalPlaces {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Decimal places of %q can't change", tx.Currency.Symbol))
+	}
+	if !isCheckTx {
+		currency := tx.Currency
+		state.SetCurrency(currency.Symbol, &currency)
+	}
+
+	return abci.OK
+}
+
+func retireCurrency(state *State, tx *types.RetireCurrencyTx, isCheckTx bool) abci.Result {
+	// // Validate basic structure
+	if res := tx.ValidateBasic(); res.IsErr() {
+		return res.PrependLog("in ValidateBasic()")
+	}
+
+	// Validate the clearing house user's permissions and signature
+	if res := validateCHCommitter(state, tx.Address, tx, tx.Signature); res.IsErr() {
+		return res
+	}
+
+	// Retire the currency
+	currency := state.GetCurrency(tx.Symbol)
+	if currency == nil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Unsupported currency: %q", tx.Symbol))
+	}
+	if currency.Retired {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Currency is already retired: %q", tx.Symbol))
+	}
+	if !isCheckTx {
+		currency.Retired = true
+		state.SetCurrency(currency.Symbol, currency)
+	}
 
 	return abci.OK
 }
@@ -208,7 +372,36 @@ func createUser(state *State, tx *types.CreateUserTx, isCheckTx bool) abci.Resul
 func ExecTx(state *State, pgz *bctypes.Plugins, tx types.Tx,
 	isCheckTx bool, evc events.Fireable) abci.Result {
 
-	// Execute transaction
+	// Every Tx carries its signer's nonce, which protects against replays
+	ntx, ok := tx.(types.NoncedTx)
+	if !ok {
+		return abci.ErrBaseEncodingError.SetLog("Unknown tx type")
+	}
+	// A resubmitted Tx gets the original result, before its nonce is found stale
+	if res := checkDuplicate(state, ntx); res.IsErr() {
+		return res
+	}
+	if res := validateNonce(state, ntx); res.IsErr() {
+		return res.PrependLog("in validateNonce()")
+	}
+	if res := validateExpiry(state, ntx, isCheckTx); res.IsErr() {
+		return res.PrependLog("in validateExpiry()")
+	}
+
+	res := execTx(state, tx, isCheckTx)
+	if res.IsOK() {
+		// CheckTx runs against a cache of the state, where the nonce advances
+		// so that a signer may have several Txs pending in the mempool
+		incrementNonce(state, ntx)
+		if len(ntx.GetClientID()) > 0 && !isCheckTx {
+			state.SetTxResult(types.NewTxResult(ntx, state.BlockHeight(), res))
+		}
+	}
+	return res
+}
+
+// execTx hands a Tx over to the function executing its type.
+func execTx(state *State, tx types.Tx, isCheckTx bool) abci.Result {
 	switch tx := tx.(type) {
 	case *types.TransferTx:
 		return transfer(state, tx, isCheckTx)
@@ -222,103 +415,98 @@ func ExecTx(state *State, pgz *bctypes.Plugins, tx types.Tx,
 	case *types.CreateUserTx:
 		return createUser(state, tx, isCheckTx)
 
+	case *types.IssueTx:
+		return issue(state, tx, isCheckTx)
+
+	case *types.RedeemTx:
+		return redeem(state, tx, isCheckTx)
+
+	case *types.AddCurrencyTx:
+		return addCurrency(state, tx, isCheckTx)
+
+	case *types.UpdateCurrencyTx:
+		return updateCurrency(state, tx, isCheckTx)
+
+	case *types.RetireCurrencyTx:
+		return retireCurrency(state, tx, isCheckTx)
+
 	default:
 		return abci.ErrBaseEncodingError.SetLog("Unknown tx type")
 	}
 }
 
- func accountQuery(state *State, accountID string) (res abci.ResponseQuery) {
- 	account := state.GetAccount(accountID)
- 	if account == nil {
- 		res.Code = abci.CodeType_BaseInvalidInput
-		res.Log = common.Fmt("Invalid account_id: %q", accountID)
-		return
- 	}	
- 	
- 	data, err := json.Marshal(types.AccountsReturned{Account: []*types.Account{account}})
- 	if err != nil {
-	 	res.Code = abci.CodeType_InternalError
-		res.Log = common.Fmt("Couldn't make the response: %v", err)
-		return 		
- 	}
- 	
- 	res.Code = abci.CodeType_OK
- 	res.Value = data
- 	return 
- }
-
- func accountIndexQuery(state *State) (res abci.ResponseQuery) {
- 	
- 	// Check that the account index exists
- 	accountIndex := state.GetAccountIndex()
- 	if accountIndex == nil {
- 		res.Code = abci.CodeType_InternalError
-		res.Log = "AccountIndex has not yet been initialized"
-		return 	 		
- 	}
- 	
- 	data, err := json.Marshal(accountIndex)
- 	if err != nil {
- 		res.Code = abci.CodeType_InternalError
-		res.Log = common.Fmt("Couldn't make the response: %v", err)
-		return 	  		
- 	}
- 	
- 	res.Code = abci.CodeType_OK
- 	res.Value = data
- 	return 
- }
-
-// ExecQuery handles queries.
-func ExecQuery(state *State, resource string, object string) abci.ResponseQuery {
-
-	 switch  {
-		 case resource == "account" && len(object) > 0 :
-		 	return accountQuery(state, object)
-	
-		 case resource == "account" && len(object) == 0 :
-		 	return accountIndexQuery(state)
-	
-		 case resource == "legal_entity" && len(object) > 0 :
-		 	return legalEntityQuery(state, object)
-	
-		 case resource == "legal_entity" && len(object) == 0 :
-		 	return legalEntityIndexQueryTx(state)
-	
-		 default:			
-			return  abci.ResponseQuery {
-				Code : abci.CodeType_BaseEncodingError,
-				Log : common.Fmt("Unknown resource and object: %v/%v", resource, object),
-			}		 	
-	 }
-	
+//--------------------------------------------------------------------------------
+
+// validateNonce checks that the Tx's nonce follows its signer's last one,
+// which rejects replayed Txs.
+func validateNonce(state types.UserGetter, tx types.NoncedTx) abci.Result {
+	user := state.GetUser(tx.Signer())
+	if user == nil {
+		return abci.ErrBaseUnknownAddress.AppendLog("Signer's user is unknown")
+	}
+	if tx.GetNonce() != user.Nonce+1 {
+		return abci.ErrBaseInvalidSequence.AppendLog(common.Fmt("Invalid nonce: got: %v, want: %v", tx.GetNonce(), user.Nonce+1))
+	}
+	return abci.OK
 }
 
-//--------------------------------------------------------------------------------
+// checkDuplicate rejects a Tx whose ClientID was already executed, with
+// CodeTypeDuplicateTx and the original TxResult if the signers match.
+// Only executed Txs are recorded, so a Tx that failed can be resubmitted.
+// A Tx without ClientID is only protected by its nonce, see validateClientID.
+func checkDuplicate(state *State, tx types.NoncedTx) abci.Result {
+	clientID := tx.GetClientID()
+	if len(clientID) == 0 {
+		return abci.OK
+	}
+	original := state.GetTxResult(clientID)
+	if original == nil {
+		return abci.OK
+	}
+	if !bytes.Equal(original.Signer, tx.Signer()) {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Client ID already used by another user: %q", clientID))
+	}
+	data, err := json.Marshal(original)
+	if err != nil {
+		return abci.ErrInternalError.AppendLog(common.Fmt("Couldn't encode the original result: %v", err))
+	}
+	return abci.NewResult(types.CodeTypeDuplicateTx, data,
+		common.Fmt("Duplicate Tx: %q was executed at height %v", clientID, original.Height))
+}
 
-func validateWalletSequence(acc *types.Account, in types.TxTransferSender) abci.Result {
-	wal := acc.GetWallet(in.Currency)
-	// Wallet does not exist, Sequence must be 1
-	if wal == nil {
-		if in.Sequence != 1 {
-			return abci.ErrBaseInvalidSequence.AppendLog(common.Fmt("Invalid sequence: got: %v, want: 1", in.Sequence))
-		}
+// validateExpiry rejects a Tx that is executed after its ValidUntilHeight.
+// CheckTx runs between blocks, hence against the height of the next block.
+func validateExpiry(state *State, tx types.NoncedTx, isCheckTx bool) abci.Result {
+	validUntil := tx.GetValidUntilHeight()
+	if validUntil == 0 {
 		return abci.OK
 	}
-	if in.Sequence != wal.Sequence+1 {
-		return abci.ErrBaseInvalidSequence.AppendLog(common.Fmt("Invalid sequence: got: %v, want: %v", in.Sequence, wal.Sequence+1))
+	height := state.BlockHeight()
+	if isCheckTx {
+		height++
+	}
+	if height > validUntil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Tx expired: valid until height %v, current height %v", validUntil, height))
 	}
 	return abci.OK
 }
 
+// incrementNonce records the nonce of a Tx that was executed.
+func incrementNonce(state types.UserGetterSetter, tx types.NoncedTx) {
+	user := state.GetUser(tx.Signer())
+	user.Nonce = tx.GetNonce()
+	state.SetUser(tx.Signer(), user)
+}
+
 func validateCommitter(u *types.User, committerEntity, senderEntity, recipientEntity *types.LegalEntity, signBytes []byte, tx *types.TransferTx) abci.Result {
 	// TODO: apply business rules
 	return abci.OK
 }
 
-// Validate countersignatures
+// Validate countersignatures, which like the committer's sign the whole transfer
 func validateCounterSigners(state *State, entity *types.LegalEntity, tx *types.TransferTx) abci.Result {
 	var users = make(map[string]bool)
+	signBytes := tx.SignBytes(state.GetChainID())
 
 	// Make sure users are not duplicated
 	users[string(tx.Committer.Address)] = true
@@ -341,7 +529,7 @@ func validateCounterSigners(state *State, entity *types.LegalEntity, tx *types.T
 			return res
 		}
 		// Verify the signature
-		if !user.VerifySignature(in.SignBytes(state.GetChainID()), in.Signature) {
+		if !user.VerifySignature(signBytes, in.Signature) {
 			return abci.ErrBaseInvalidSignature.AppendLog(common.Fmt("countersigner's signature doesn't match, user: %s", user))
 		}
 	}
@@ -362,25 +550,65 @@ func validateExecPermissions(u *types.User, e *types.LegalEntity, tx types.Tx) a
 	return abci.OK
 }
 
+// Retired currencies can't be issued or transferred anymore
+func validateCurrencyNotRetired(currencies types.CurrencyGetter, symbol string) abci.Result {
+	if currency := currencies.GetCurrency(symbol); currency != nil && currency.Retired {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Currency is retired: %q", symbol))
+	}
+	return abci.OK
+}
+
+// Only the clearing house's users may change the supply of money
+// or the currency registry
+func validateCHCommitter(state *State, addr []byte, tx types.Tx, sig crypto.Signature) abci.Result {
+	user := state.GetUser(addr)
+	if user == nil {
+		return abci.ErrBaseUnknownAddress.AppendLog("User is unknown")
+	}
+	entity := state.GetLegalEntity(user.EntityID)
+	if entity == nil {
+		return abci.ErrUnauthorized.AppendLog("User's does not belong to any LegalEntity")
+	}
+	if entity.Type != types.EntityTypeCHByte {
+		return abci.ErrUnauthorized.AppendLog(common.Fmt(
+			"Only the clearing house can execute the Tx: %s", entity.String()))
+	}
+	if res := validateExecPermissions(user, entity, tx); res.IsErr() {
+		return res
+	}
+	if !user.VerifySignature(tx.SignBytes(state.GetChainID()), sig) {
+		return abci.ErrBaseInvalidSignature.AppendLog("user's signature doesn't match")
+	}
+	return abci.OK
+}
+
 // Apply changes to inputs
-func applyChangesToInput(state types.AccountSetter, in types.TxTransferSender, account *types.Account, isCheckTx bool) {
-	applyChanges(account, in.Currency, in.Amount, false)
+func applyChangesToInput(state types.AccountSetter, in types.TxTransferSender, account *types.Account, isCheckTx bool) abci.Result {
+	if res := applyChanges(account, in.Currency, in.Amount, false); res.IsErr() {
+		return res
+	}
 
 	if !isCheckTx {
 		state.SetAccount(account.ID, account)
 	}
+	return abci.OK
 }
 
 // Apply changes to outputs
-func applyChangesToOutput(state types.AccountSetter, in types.TxTransferSender, out types.TxTransferRecipient, account *types.Account, isCheckTx bool) {
-	applyChanges(account, in.Currency, in.Amount, true)
+func applyChangesToOutput(state types.AccountSetter, in types.TxTransferSender, out types.TxTransferRecipient, account *types.Account, isCheckTx bool) abci.Result {
+	if res := applyChanges(account, in.Currency, in.Amount, true); res.IsErr() {
+		return res
+	}
 
 	if !isCheckTx {
 		state.SetAccount(account.ID, account)
 	}
+	return abci.OK
 }
 
-func applyChanges(account *types.Account, currency string, amount int64, isBuy bool) {
+// applyChanges credits (isBuy) or debits the account's wallet. The account
+// is left untouched if the balance would overflow.
+func applyChanges(account *types.Account, currency string, amount int64, isBuy bool) abci.Result {
 
 	wal := account.GetWallet(currency)
 
@@ -389,14 +617,23 @@ func applyChanges(account *types.Account, currency string, amount int64, isBuy b
 	}
 
 	if isBuy {
-		wal.Balance += amount
+		balance, err := types.Amount(wal.Balance).Add(types.Amount(amount))
+		if err != nil {
+			return abci.ErrBaseInvalidOutput.AppendLog(common.Fmt("Balance of account %s would overflow: %v", account.ID, err))
+		}
+		wal.Balance = int64(balance)
 	} else {
-		wal.Balance += -amount
+		balance, err := types.Amount(wal.Balance).Sub(types.Amount(amount))
+		if err != nil {
+			return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Balance of account %s would overflow: %v", account.ID, err))
+		}
+		wal.Balance = int64(balance)
 	}
 
 	wal.Sequence++
 
 	account.SetWallet(*wal)
+	return abci.OK
 }
 
 func makeNewUser(state types.UserSetter, creator *types.User, tx *types.CreateUserTx, isCheckTx bool) {
@@ -413,81 +650,35 @@ func makeNewUser(state types.UserSetter, creator *types.User, tx *types.CreateUs
 	}
 }
 
-//Returns existing AccountIndex from store or creates new empty one
-func GetOrMakeAccountIndex(state types.AccountIndexGetter) *types.AccountIndex {
-	if index := state.GetAccountIndex(); index != nil {
-		return index
-	}
-	return types.NewAccountIndex()
-}
-
-//Sets Account in AccountIndex in store
+// SetAccountInIndex adds an Account to the accounts index and
+// to its legal entity's accounts index.
 func SetAccountInIndex(state *State, account types.Account) abci.Result {
-	accountIndex := GetOrMakeAccountIndex(state)
-	if accountIndex.Has(account.ID) {
+	if !state.AccountIndex().Add(account.ID) {
 		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Account already exists in the account index: %q", account.ID))
 	}
-	accountIndex.Add(account.ID)
-	state.SetAccountIndex(accountIndex)
+	state.EntityAccountIndex(account.EntityID).Add(account.ID)
 	return abci.OK
 }
 
-//Sets LegalEntity in LegalEntityIndex in store
+// SetLegalEntityInIndex adds a LegalEntity to the legal entities index
+// and to its parent's children index.
 func SetLegalEntityInIndex(state *State, legalEntity *types.LegalEntity) abci.Result {
-	legalEntities := state.GetLegalEntityIndex()
-
-	if legalEntities == nil {
-		legalEntities = &types.LegalEntityIndex{Ids: []string{}}
-	}
-
-	if legalEntities.Has(legalEntity.ID) {
+	if !state.LegalEntityIndex().Add(legalEntity.ID) {
 		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("LegalEntity already exists in the LegalEntity index: %q", legalEntity.ID))
 	}
-	legalEntities.Add(legalEntity.ID)
-
-	state.SetLegalEntityIndex(legalEntities)
-
+	if len(legalEntity.EntityID) > 0 {
+		state.EntityChildIndex(legalEntity.EntityID).Add(legalEntity.ID)
+	}
 	return abci.OK
 }
 
- func legalEntityQuery(state *State, entityID string)  (res abci.ResponseQuery) {
- 	
- 	legalEntity := state.GetLegalEntity(entityID)
- 	if legalEntity == nil {
- 		res.Code = abci.CodeType_BaseInvalidInput
-		res.Log = common.Fmt("Invalid legalEntity id: %q", entityID)
-		return 			
- 	}
- 	data, err := json.Marshal(types.LegalEntitiesReturned{LegalEntities: []*types.LegalEntity{legalEntity}})
- 	if err != nil {
- 		res.Code = abci.CodeType_InternalError
-		res.Log = common.Fmt("Couldn't make the response: %v", err)
-		return 	  		
- 	}
- 	
- 	res.Code = abci.CodeType_OK
- 	res.Value = data
- 	return 
- }
-
- func legalEntityIndexQueryTx(state *State) (res abci.ResponseQuery) {
- 	
- 	// Check that the account index exists
- 	legalEntities := state.GetLegalEntityIndex()
- 	if legalEntities == nil {
- 		res.Code = abci.CodeType_InternalError
-		res.Log = "LegalEntities has not yet been initialized"
-		return 			
- 	}
- 	
- 	data, err := json.Marshal(legalEntities)
- 	if err != nil {
- 		res.Code = abci.CodeType_InternalError
-		res.Log = common.Fmt("Couldn't make the response: %v", err)
-		return 	  		
- 	}
- 	
- 	res.Code = abci.CodeType_OK
- 	res.Value = data
- 	return 
- }
+// SetUserInIndex adds a User to the users index and
+// to its legal entity's users index.
+func SetUserInIndex(state *State, addr []byte, entityID string) abci.Result {
+	id := UserIndexID(addr)
+	if !state.UserIndex().Add(id) {
+		return abci.ErrBaseDuplicateAddress.AppendLog(common.Fmt("User already exists in the user index: %s", id))
+	}
+	state.EntityUserIndex(entityID).Add(id)
+	return abci.OK
+}
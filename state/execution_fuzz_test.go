@@ -0,0 +1,136 @@
+package state
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+)
+
+// TestExecTx_transferFuzz executes random transfers of extreme amounts in
+// many currencies and checks them against a model using arbitrary precision
+// integers: a transfer must succeed if and only if neither balance would
+// overflow, a rejected transfer must leave the state untouched, and money
+// must be conserved.
+func TestExecTx_transferFuzz(t *testing.T) {
+	const (
+		numAccounts   = 4
+		numCurrencies = 12
+		numTransfers  = 2000
+	)
+	rng := rand.New(rand.NewSource(1))
+
+	// Set up fixtures
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID(chainID)
+	SetCurrencies(s, types.ISOCurrencies())
+	ch := testutil.RandCH()
+	user := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0]
+	s.SetLegalEntity(ch.ID, ch)
+	s.SetUser(user.User.PubKey.Address(), &user.User)
+	accounts := testutil.RandAccounts(numAccounts, ch)
+	for _, acc := range accounts {
+		acc.Wallets = nil
+		s.SetAccount(acc.ID, acc)
+	}
+	currencies := make([]string, numCurrencies)
+	for i, c := range types.ISOCurrencies()[:numCurrencies] {
+		currencies[i] = c.Symbol
+	}
+
+	// The model of every wallet, keyed by account ID and currency
+	balances := make(map[string]map[string]*big.Int)
+	sequences := make(map[string]map[string]int)
+	for _, acc := range accounts {
+		balances[acc.ID] = make(map[string]*big.Int)
+		sequences[acc.ID] = make(map[string]int)
+		for _, c := range currencies {
+			balances[acc.ID][c] = new(big.Int)
+		}
+	}
+	randAmount := func() int64 {
+		switch rng.Intn(6) {
+		case 0:
+			return math.MaxInt64
+		case 1:
+			return math.MaxInt64 - rng.Int63n(3)
+		case 2:
+			return math.MaxInt64/2 + 1 - rng.Int63n(3)
+		case 3:
+			return 1 + rng.Int63n(1000)
+		default:
+			return 1 + rng.Int63n(math.MaxInt64)
+		}
+	}
+	minInt64, maxInt64 := big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)
+	fits := func(x *big.Int) bool { return x.Cmp(minInt64) >= 0 && x.Cmp(maxInt64) <= 0 }
+
+	var succeeded, rejected int
+	var nonce uint64
+	for i := 0; i < numTransfers; i++ {
+		perm := rng.Perm(numAccounts)
+		sender, recipient := accounts[perm[0]], accounts[perm[1]]
+		currency := currencies[rng.Intn(numCurrencies)]
+		amount := randAmount()
+
+		tx := &types.TransferTx{
+			Committer: types.TxTransferCommitter{Address: user.User.PubKey.Address(), Nonce: nonce + 1},
+			Sender: types.TxTransferSender{
+				AccountID: sender.ID,
+				Amount:    amount,
+				Currency:  currency,
+			},
+			Recipient: types.TxTransferRecipient{AccountID: recipient.ID},
+		}
+		tx.SignTx(user.PrivKey, chainID)
+
+		newSenderBalance := new(big.Int).Sub(balances[sender.ID][currency], big.NewInt(amount))
+		newRecipientBalance := new(big.Int).Add(balances[recipient.ID][currency], big.NewInt(amount))
+		wantOK := fits(newSenderBalance) && fits(newRecipientBalance)
+
+		got := ExecTx(s, nil, tx, false, nil)
+		if got.IsOK() != wantOK {
+			t.Fatalf("#%d: ExecTx(%d %s) = %v, want OK %v", i, amount, currency, got, wantOK)
+		}
+		if !wantOK {
+			if got.Code != abci.CodeType_BaseInvalidInput && got.Code != abci.CodeType_BaseInvalidOutput {
+				t.Fatalf("#%d: ExecTx(%d %s) = %v, want an overflow error", i, amount, currency, got)
+			}
+			rejected++
+		} else {
+			balances[sender.ID][currency] = newSenderBalance
+			balances[recipient.ID][currency] = newRecipientBalance
+			sequences[sender.ID][currency]++
+			sequences[recipient.ID][currency]++
+			nonce++
+			succeeded++
+		}
+
+		// The state must match the model, and money must be conserved
+		total := new(big.Int)
+		for _, acc := range accounts {
+			var balance int64
+			var sequence int
+			if wal := s.GetAccount(acc.ID).GetWallet(currency); wal != nil {
+				balance, sequence = wal.Balance, wal.Sequence
+			}
+			if balances[acc.ID][currency].Cmp(big.NewInt(balance)) != 0 || sequence != sequences[acc.ID][currency] {
+				t.Fatalf("#%d: account %s wallet %s = %d (sequence %d), want %v (sequence %d)",
+					i, acc.ID, currency, balance, sequence, balances[acc.ID][currency], sequences[acc.ID][currency])
+			}
+			total.Add(total, balances[acc.ID][currency])
+		}
+		if total.Sign() != 0 {
+			t.Fatalf("#%d: balances of %s sum up to %v, want 0", i, currency, total)
+		}
+	}
+	if succeeded == 0 || rejected == 0 {
+		t.Errorf("%d transfers succeeded and %d were rejected, want both", succeeded, rejected)
+	}
+}
@@ -2,6 +2,8 @@ package state
 
 import (
 	"encoding/json"
+	"math"
+	"net/url"
 	"reflect"
 	"testing"
 
@@ -28,6 +30,7 @@ func TestExecTx(t *testing.T) {
 	chainID := "chain"
 	s := NewState(bscoin.NewMemKVStore())
 	s.chainID = chainID
+	SetCurrencies(s, types.ISOCurrencies())
 	// Create users, legal entities and their respective accounts
 	senderEntity := testutil.RandCH()
 	randUsers := testutil.RandUsersWithLegalEntity(20, senderEntity, senderEntity.Permissions)
@@ -51,12 +54,12 @@ func TestExecTx(t *testing.T) {
 		tx := types.TransferTx{
 			Committer: types.TxTransferCommitter{
 				Address: senderUser.User.PubKey.Address(),
+				Nonce:   1,
 			},
 			Sender: types.TxTransferSender{
 				AccountID: senderAccount.ID,
 				Amount:    amount,
 				Currency:  ccy,
-				Sequence:  1,
 			},
 			Recipient: types.TxTransferRecipient{
 				AccountID: recipientAccount.ID,
@@ -72,18 +75,17 @@ func TestExecTx(t *testing.T) {
 		counterSigners := []types.TxTransferCounterSigner{}
 		for _, u := range counterSignersUsers {
 			cs := types.TxTransferCounterSigner{Address: u.User.PubKey.Address()}
-			cs.SignTx(u.PrivKey, s.GetChainID())
 			counterSigners = append(counterSigners, cs)
 		}
 		tx := types.TransferTx{
 			Committer: types.TxTransferCommitter{
 				Address: senderUser.User.PubKey.Address(),
+				Nonce:   2,
 			},
 			Sender: types.TxTransferSender{
 				AccountID: senderAccount.ID,
 				Amount:    amount,
 				Currency:  ccy,
-				Sequence:  2,
 			},
 			CounterSigners: counterSigners,
 			Recipient: types.TxTransferRecipient{
@@ -91,6 +93,9 @@ func TestExecTx(t *testing.T) {
 			},
 		}
 		tx.SignTx(senderUser.PrivKey, s.GetChainID())
+		for _, u := range counterSignersUsers {
+			tx.SignTx(u.PrivKey, s.GetChainID())
+		}
 
 		return tx
 	}()
@@ -98,6 +103,7 @@ func TestExecTx(t *testing.T) {
 		user := randUsers[0]
 		tx := types.CreateAccountTx{
 			Address:   user.User.PubKey.Address(),
+			Nonce:     3,
 			AccountID: uuid.NewV4().String(),
 		}
 		signBytes := tx.SignBytes(chainID)
@@ -111,6 +117,7 @@ func TestExecTx(t *testing.T) {
 		s.SetUser(user.User.PubKey.Address(), &user.User)
 		tx := types.CreateLegalEntityTx{
 			Address:  user.User.PubKey.Address(),
+			Nonce:    1,
 			EntityID: uuid.NewV4().String(),
 			Type:     types.EntityTypeCustodianByte,
 			Name:     "new Custodian",
@@ -127,6 +134,7 @@ func TestExecTx(t *testing.T) {
 		s.SetUser(user.User.PubKey.Address(), &user.User)
 		tx := types.CreateLegalEntityTx{
 			Address:  user.User.PubKey.Address(),
+			Nonce:    1,
 			EntityID: uuid.NewV4().String(),
 			Type:     types.EntityTypeCustodianByte,
 			Name:     "new Custodian",
@@ -144,6 +152,7 @@ func TestExecTx(t *testing.T) {
 		s.SetUser(user.User.PubKey.Address(), &user.User)
 		tx := types.CreateUserTx{
 			Address:   user.User.PubKey.Address(),
+			Nonce:     1,
 			PubKey:    pubKey,
 			CanCreate: true,
 			Name:      "new user",
@@ -160,6 +169,7 @@ func TestExecTx(t *testing.T) {
 		s.SetUser(user.User.PubKey.Address(), &user.User)
 		tx := types.CreateUserTx{
 			Address:   user.User.PubKey.Address(),
+			Nonce:     1,
 			PubKey:    pubKey,
 			CanCreate: true,
 			Name:      "new user",
@@ -176,6 +186,7 @@ func TestExecTx(t *testing.T) {
 		s.SetUser(user.User.PubKey.Address(), &user.User)
 		tx := types.CreateUserTx{
 			Address:   user.User.PubKey.Address(),
+			Nonce:     1,
 			PubKey:    pubKey,
 			CanCreate: false,
 			Name:      "new user",
@@ -200,6 +211,8 @@ func TestExecTx(t *testing.T) {
 		{"appendTxTransferTxWithoutCounterSigners", args{s, nil, &tx1, false, nil}, abci.OK},
 		{"checkTxTransferTxWithCounterSigners", args{s, nil, &tx2, true, nil}, abci.OK},
 		{"appendTxTransferTxWithCounterSigners", args{s, nil, &tx2, false, nil}, abci.OK},
+		{"checkTxTransferTxReplayed", args{s, nil, &tx1, true, nil}, abci.ErrBaseInvalidSequence},
+		{"appendTxTransferTxReplayed", args{s, nil, &tx1, false, nil}, abci.ErrBaseInvalidSequence},
 		{"checkTxCreateAccountTx", args{s, nil, &tx3, true, nil}, abci.OK},
 		{"appendTxCreateAccountTx", args{s, nil, &tx3, false, nil}, abci.OK},
 		{"checkTxCreateLegalEntityTx", args{s, nil, &tx4, true, nil}, abci.OK},
@@ -215,13 +228,18 @@ func TestExecTx(t *testing.T) {
 	}
 	transferCnt := 0
 	for _, tt := range tests {
-		got := ExecTx(tt.args.state, tt.args.pgz, tt.args.tx, tt.args.isCheckTx, tt.args.evc)
+		// As the app does, CheckTx runs against a cache of the state
+		execState := tt.args.state
+		if tt.args.isCheckTx {
+			execState = execState.CacheWrap()
+		}
+		got := ExecTx(execState, tt.args.pgz, tt.args.tx, tt.args.isCheckTx, tt.args.evc)
 		if got.Code != tt.want.Code {
 			t.Errorf("%q. ExecTx() = %v, want %v", tt.name, got, tt.want)
 		}
 		switch tt.args.tx.(type) {
 		case *types.TransferTx:
-			if !tt.args.isCheckTx {
+			if got.IsOK() && !tt.args.isCheckTx {
 				senderAccount := s.GetAccount(senderAccount.ID)
 				recipientAccount := s.GetAccount(recipientAccount.ID)
 				senderWallet := senderAccount.GetWallet(ccy)
@@ -246,9 +264,12 @@ func TestExecTx(t *testing.T) {
 		case *types.CreateAccountTx:
 			concreteTx := tt.args.tx.(*types.CreateAccountTx)
 			if got.IsOK() && !tt.args.isCheckTx {
-				if index := s.GetAccountIndex(); !index.Has(concreteTx.AccountID) {
+				if index := s.AccountIndex(); !index.Has(concreteTx.AccountID) {
 					t.Errorf("%q. AccountIndex.Has(%s) = false, want true", tt.name, concreteTx.AccountID)
 				}
+				if index := s.EntityAccountIndex(randUsers[0].User.EntityID); !index.Has(concreteTx.AccountID) {
+					t.Errorf("%q. EntityAccountIndex.Has(%s) = false, want true", tt.name, concreteTx.AccountID)
+				}
 				newAccount := s.GetAccount(concreteTx.AccountID)
 				want := types.NewAccount(concreteTx.AccountID, randUsers[0].User.EntityID)
 				if !newAccount.Equal(want) {
@@ -259,8 +280,8 @@ func TestExecTx(t *testing.T) {
 				if ret := s.GetAccount(concreteTx.AccountID); ret != nil {
 					t.Errorf("%q. GetAccount(%q) = %v, want nil", tt.name, concreteTx.AccountID, ret)
 				}
-				if ret := s.GetAccountIndex(); ret != nil {
-					t.Errorf("%q. GetAccountIndex() = %v, want nil", tt.name, ret)
+				if s.AccountIndex().Has(concreteTx.AccountID) {
+					t.Errorf("%q. AccountIndex.Has(%s) = true, want false", tt.name, concreteTx.AccountID)
 				}
 			}
 		case *types.CreateLegalEntityTx:
@@ -312,7 +333,6 @@ func TestExecQuery(t *testing.T) {
 	s.SetLegalEntity(entity.ID, entity)
 	user := testutil.PrivUserWithLegalEntityFromSecret("", entity, entity.Permissions)
 	// Initialize the state
-	accountIndex := types.NewAccountIndex()
 	s.SetUser(user.User.PubKey.Address(), &user.User)
 	accounts := testutil.RandAccounts(10, entity)
 	for _, account := range accounts {
@@ -322,9 +342,8 @@ func TestExecQuery(t *testing.T) {
 			testutil.RandWallet(types.Currencies["USD"], 100000, 99999999),
 		}
 		s.SetAccount(account.ID, account)
-		accountIndex.Add(account.ID)
+		SetAccountInIndex(s, *account)
 	}
-	s.SetAccountIndex(accountIndex)
 
 	accountIDs := make([]string, len(accounts))
 	for i, account := range accounts {
@@ -340,24 +359,31 @@ func TestExecQuery(t *testing.T) {
 		Account []*types.Account `json:"accounts"`
 	}{Account: []*types.Account{accounts[0]}})
 	
-	validAccountIndexQueryTxExpectedJSON, _ := json.Marshal(accountIndex)
+	validAccountIndexQueryTxExpectedJSON, _ := json.Marshal(types.AccountIndex{Accounts: accountIDs})
+	firstPageExpectedJSON, _ := json.Marshal(types.AccountIndex{Accounts: accountIDs[:4], Next: "4"})
+	lastPageExpectedJSON, _ := json.Marshal(types.AccountIndex{Accounts: accountIDs[8:]})
+	entityAccountsExpectedJSON, _ := json.Marshal(types.AccountIndex{Accounts: accountIDs[:3], Next: "3"})
 
 	type args struct {
 		state *State
-		resource string
-		object string
+		query Query
 	}
 	tests := []struct {
 		name string
 		args args
 		want abci.ResponseQuery
 	}{
-		{"queryAccount", args{s, "account", validAccountQueryTx}, abci.ResponseQuery{Code:abci.CodeType_OK, Value: expectedJSON} },
-		{"invalidAccountID", args{s, "account", invalidAccountsQueryTx}, abci.ResponseQuery{Code: abci.CodeType_BaseInvalidInput, Log: "Invalid account_id: xx"}},
-		{"queryAccountIndex", args{s, "account", ""}, abci.ResponseQuery{Code:abci.CodeType_OK , Value: validAccountIndexQueryTxExpectedJSON}},
+		{"queryAccount", args{s, Query{Resource: "account", Object: validAccountQueryTx}}, abci.ResponseQuery{Code:abci.CodeType_OK, Value: expectedJSON} },
+		{"invalidAccountID", args{s, Query{Resource: "account", Object: invalidAccountsQueryTx}}, abci.ResponseQuery{Code: abci.CodeType_BaseUnknownAddress, Log: "Unknown account: \"xx\""}},
+		{"queryAccountIndex", args{s, Query{Resource: "account"}}, abci.ResponseQuery{Code:abci.CodeType_OK , Value: validAccountIndexQueryTxExpectedJSON}},
+		{"queryAccountIndexFirstPage", args{s, Query{Resource: "account", Params: url.Values{"limit": {"4"}}}}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: firstPageExpectedJSON}},
+		{"queryAccountIndexLastPage", args{s, Query{Resource: "account", Params: url.Values{"cursor": {"8"}, "limit": {"4"}}}}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: lastPageExpectedJSON}},
+		{"invalidCursor", args{s, Query{Resource: "account", Params: url.Values{"cursor": {"x"}}}}, abci.ResponseQuery{Code: abci.CodeType_BaseInvalidInput}},
+		{"queryEntityAccounts", args{s, Query{Resource: "legal_entity", Object: entity.ID, SubResource: "accounts", Params: url.Values{"limit": {"3"}}}}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: entityAccountsExpectedJSON}},
+		{"invalidEntityAccounts", args{s, Query{Resource: "legal_entity", Object: "xx", SubResource: "accounts"}}, abci.ResponseQuery{Code: abci.CodeType_BaseUnknownAddress}},
 	}
 	for _, tt := range tests {
-		got := ExecQuery(tt.args.state, tt.args.resource, tt.args.object)
+		got := ExecQuery(tt.args.state, tt.args.query)
 		if got.Code != abci.CodeType_OK && got.Code != tt.want.Code {
 			t.Errorf("%q. ExecQuery() = %v, want %v", tt.name, got, tt.want)
 		}
@@ -424,20 +450,30 @@ func Test_applyChangesToInput(t *testing.T) {
 		acc       *types.Account
 		isCheckTx bool
 	}
+	overdrawn := func() *types.Account {
+		return &types.Account{ID: accountID, Wallets: []types.Wallet{{Currency: "USD", Balance: math.MinInt64 + 10}}}
+	}
 	tests := []struct {
-		name string
-		args args
+		name    string
+		args    args
+		wantErr bool
 	}{
-		{"appendTx", args{mockObj, genTxTransferSender(), &types.Account{ID: accountID}, false}},
-		{"checkTx", args{mockObj, genTxTransferSender(), &types.Account{ID: accountID}, true}},
+		{"appendTx", args{mockObj, genTxTransferSender(), &types.Account{ID: accountID}, false}, false},
+		{"checkTx", args{mockObj, genTxTransferSender(), &types.Account{ID: accountID}, true}, false},
+		{"overflow", args{mockObj, genTxTransferSender(), overdrawn(), false}, true},
 	}
 	for _, tt := range tests {
 		ntimes := 0
-		if !tt.args.isCheckTx {
+		if !tt.args.isCheckTx && !tt.wantErr {
 			ntimes = 1
 		}
 		mockObj.EXPECT().SetAccount(tt.args.in.AccountID, tt.args.acc).Times(ntimes)
-		applyChangesToInput(tt.args.state, tt.args.in, tt.args.acc, tt.args.isCheckTx)
+		if got := applyChangesToInput(tt.args.state, tt.args.in, tt.args.acc, tt.args.isCheckTx); got.IsErr() != tt.wantErr {
+			t.Errorf("%q. applyChangesToInput() = %v, wantErr %v", tt.name, got, tt.wantErr)
+		}
+		if tt.wantErr && !tt.args.acc.Equal(overdrawn()) {
+			t.Errorf("%q. applyChangesToInput() changed the account to %v", tt.name, tt.args.acc)
+		}
 	}
 }
 
@@ -459,20 +495,30 @@ func Test_applyChangesToOutput(t *testing.T) {
 		acc       *types.Account
 		isCheckTx bool
 	}
+	rich := func() *types.Account {
+		return &types.Account{ID: accountID, Wallets: []types.Wallet{{Currency: "USD", Balance: math.MaxInt64 - 10}}}
+	}
 	tests := []struct {
-		name string
-		args args
+		name    string
+		args    args
+		wantErr bool
 	}{
-		{"appendTx", args{mockObj, genTxTransferSender(), genTxTransferRecipient(), &types.Account{ID: accountID}, false}},
-		{"checkTx", args{mockObj, genTxTransferSender(), genTxTransferRecipient(), &types.Account{ID: accountID}, true}},
+		{"appendTx", args{mockObj, genTxTransferSender(), genTxTransferRecipient(), &types.Account{ID: accountID}, false}, false},
+		{"checkTx", args{mockObj, genTxTransferSender(), genTxTransferRecipient(), &types.Account{ID: accountID}, true}, false},
+		{"overflow", args{mockObj, genTxTransferSender(), genTxTransferRecipient(), rich(), false}, true},
 	}
 	for _, tt := range tests {
 		ntimes := 0
-		if !tt.args.isCheckTx {
+		if !tt.args.isCheckTx && !tt.wantErr {
 			ntimes = 1
 		}
 		mockObj.EXPECT().SetAccount(tt.args.out.AccountID, tt.args.acc).Times(ntimes)
-		applyChangesToOutput(tt.args.state, tt.args.in, tt.args.out, tt.args.acc, tt.args.isCheckTx)
+		if got := applyChangesToOutput(tt.args.state, tt.args.in, tt.args.out, tt.args.acc, tt.args.isCheckTx); got.IsErr() != tt.wantErr {
+			t.Errorf("%q. applyChangesToOutput() = %v, wantErr %v", tt.name, got, tt.wantErr)
+		}
+		if tt.wantErr && !tt.args.acc.Equal(rich()) {
+			t.Errorf("%q. applyChangesToOutput() changed the account to %v", tt.name, tt.args.acc)
+		}
 	}
 }
 
@@ -505,9 +551,11 @@ func Test_validateCounterSignersAdvanced(t *testing.T) {
 		s.SetUser(u.User.PubKey.Address(), &u.User)
 		transferTx.CounterSigners[i] = types.TxTransferCounterSigner{
 			Address: u.User.PubKey.Address()}
-		transferTx.CounterSigners[i].SignTx(u.PrivKey, s.GetChainID())
 	}
 	transferTx.SignTx(senderprivateKey, s.GetChainID())
+	for _, u := range users {
+		transferTx.SignTx(u.PrivKey, s.GetChainID())
+	}
 
 	wrongSignatureTransferTx := types.TransferTx{
 		Committer: types.TxTransferCommitter{
@@ -517,11 +565,17 @@ func Test_validateCounterSignersAdvanced(t *testing.T) {
 		s.SetUser(u.User.PubKey.Address(), &u.User)
 		wrongSignatureTransferTx.CounterSigners[i] = types.TxTransferCounterSigner{
 			Address: u.User.PubKey.Address()}
-		wrongSignatureTransferTx.CounterSigners[i].SignTx(u.PrivKey, s.GetChainID())
 	}
 	wrongSignatureTransferTx.SignTx(senderprivateKey, s.GetChainID())
+	for _, u := range users {
+		wrongSignatureTransferTx.SignTx(u.PrivKey, s.GetChainID())
+	}
 	wrongSignatureTransferTx.CounterSigners[0].Signature = senderprivateKey.Sign([]byte("wrong_bytes"))
 
+	// Counter signatures of another transfer
+	otherTransferTx := transferTx
+	otherTransferTx.Sender.Amount = 1
+
 	// Make sender a duplicate of a countersigner
 	dupSendertransferTx := types.TransferTx{
 		Committer: types.TxTransferCommitter{
@@ -546,6 +600,7 @@ func Test_validateCounterSignersAdvanced(t *testing.T) {
 		{"invalidUser", args{s, ent, &nonExistUserSendertransferTx}, abci.ErrBaseUnknownAddress},
 		{"duplicateAddress", args{s, ent, &dupSendertransferTx}, abci.ErrBaseDuplicateAddress},
 		{"invalidSignatures", args{s, ent, &wrongSignatureTransferTx}, abci.ErrBaseInvalidSignature},
+		{"signaturesOfAnotherTransfer", args{s, ent, &otherTransferTx}, abci.ErrBaseInvalidSignature},
 		{"validCounterSigners", args{s, ent, &transferTx}, abci.OK},
 	}
 	for _, tt := range tests {
@@ -555,26 +610,98 @@ func Test_validateCounterSignersAdvanced(t *testing.T) {
 	}
 }
 
-func Test_validateWalletSequence(t *testing.T) {
-	type args struct {
-		acc *types.Account
-		in  types.TxTransferSender
+func Test_validateNonce(t *testing.T) {
+	s := NewState(bscoin.NewMemKVStore())
+	user := testutil.RandUsers(1)[0]
+	user.User.Nonce = 10
+	addr := user.User.PubKey.Address()
+	s.SetUser(addr, &user.User)
+	tests := []struct {
+		name string
+		tx   types.NoncedTx
+		want abci.Result
+	}{
+		{"unknownUser", &types.CreateAccountTx{Address: crypto.CRandBytes(20), Nonce: 1}, abci.ErrBaseUnknownAddress},
+		{"replayedNonce", &types.CreateAccountTx{Address: addr, Nonce: 10}, abci.ErrBaseInvalidSequence},
+		{"skippedNonce", &types.CreateAccountTx{Address: addr, Nonce: 12}, abci.ErrBaseInvalidSequence},
+		{"validNonce", &types.CreateAccountTx{Address: addr, Nonce: 11}, abci.OK},
+		{"validTransferNonce", &types.TransferTx{Committer: types.TxTransferCommitter{Address: addr, Nonce: 11}}, abci.OK},
 	}
+	for _, tt := range tests {
+		if got := validateNonce(s, tt.tx); got.Code != tt.want.Code {
+			t.Errorf("%q. validateNonce() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	incrementNonce(s, &types.CreateAccountTx{Address: addr, Nonce: 11})
+	if got := s.GetUser(addr).Nonce; got != 11 {
+		t.Errorf("incrementNonce() set nonce %v, want 11", got)
+	}
+}
+
+func TestExecTx_checkTxNonces(t *testing.T) {
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.chainID = chainID
+	entity := testutil.RandCH()
+	s.SetLegalEntity(entity.ID, entity)
+	user := testutil.RandUsersWithLegalEntity(1, entity, entity.Permissions)[0]
+	addr := user.User.PubKey.Address()
+	s.SetUser(addr, &user.User)
+	createAccountTx := func(nonce uint64) *types.CreateAccountTx {
+		tx := &types.CreateAccountTx{Address: addr, Nonce: nonce, AccountID: uuid.NewV4().String()}
+		tx.Signature = user.Sign(tx.SignBytes(chainID))
+		return tx
+	}
+
+	// A signer's Txs pending in the mempool are checked in turn
+	checkState := s.CacheWrap()
 	tests := []struct {
 		name string
-		args args
+		tx   *types.CreateAccountTx
 		want abci.Result
 	}{
-		{"invalidInitialSequence", args{&types.Account{Wallets: []types.Wallet{}}, types.TxTransferSender{Currency: "USD", Sequence: 10}}, abci.ErrBaseInvalidSequence},
-		{"invalidSequence", args{&types.Account{Wallets: []types.Wallet{types.Wallet{Currency: "USD", Sequence: 10}}}, types.TxTransferSender{Currency: "USD", Sequence: 10}}, abci.ErrBaseInvalidSequence},
-		{"validInitialSequence", args{&types.Account{}, types.TxTransferSender{Currency: "USD", Sequence: 1}}, abci.OK},
-		{"validSequence", args{&types.Account{Wallets: []types.Wallet{types.Wallet{Currency: "USD", Sequence: 10}}}, types.TxTransferSender{Currency: "USD", Sequence: 11}}, abci.OK},
+		{"first", createAccountTx(1), abci.OK},
+		{"pipelined", createAccountTx(2), abci.OK},
+		{"replayedNonce", createAccountTx(2), abci.ErrBaseInvalidSequence},
+		{"skippedNonce", createAccountTx(4), abci.ErrBaseInvalidSequence},
+	}
+	for _, tt := range tests {
+		if got := ExecTx(checkState, nil, tt.tx, true, nil); got.Code != tt.want.Code {
+			t.Errorf("%q. ExecTx() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := checkState.GetUser(addr).Nonce; got != 2 {
+		t.Errorf("CheckTx nonce = %v, want 2", got)
+	}
+	if got := s.GetUser(addr).Nonce; got != 0 {
+		t.Errorf("committed nonce = %v, want 0", got)
+	}
+}
+
+func Test_validateExpiry(t *testing.T) {
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetBlockHeight(10)
+	tests := []struct {
+		name      string
+		tx        types.NoncedTx
+		isCheckTx bool
+		want      abci.Result
+	}{
+		{"neverExpires", &types.IssueTx{}, false, abci.OK},
+		{"validUntilCurrentHeight", &types.IssueTx{ValidUntilHeight: 10}, false, abci.OK},
+		{"expired", &types.IssueTx{ValidUntilHeight: 9}, false, abci.ErrBaseInvalidInput},
+		{"checkTxValidUntilNextHeight", &types.IssueTx{ValidUntilHeight: 11}, true, abci.OK},
+		{"checkTxExpiresBeforeNextHeight", &types.IssueTx{ValidUntilHeight: 10}, true, abci.ErrBaseInvalidInput},
+		{"transferExpired", &types.TransferTx{Committer: types.TxTransferCommitter{ValidUntilHeight: 9}}, false, abci.ErrBaseInvalidInput},
 	}
 	for _, tt := range tests {
-		if got := validateWalletSequence(tt.args.acc, tt.args.in); got.Code != tt.want.Code {
-			t.Errorf("%q. validateWalletSequence() = %v, want %v", tt.name, got, tt.want)
+		if got := validateExpiry(s, tt.tx, tt.isCheckTx); got.Code != tt.want.Code {
+			t.Errorf("%q. validateExpiry() = %v, want %v", tt.name, got, tt.want)
 		}
 	}
+	if got := s.CacheWrap().BlockHeight(); got != 10 {
+		t.Errorf("CacheWrap().BlockHeight() = %v, want 10", got)
+	}
 }
 
 func Test_makeNewUser(t *testing.T) {
@@ -639,25 +766,18 @@ func Test_validateTransferTxHash(t *testing.T) {
 	currency := "EUR"
 
 	privateKey, _ := crypto.PrivKeyFromBytes(client.Decode(privateKeyParam))
-	counterSignerAddresses := make([][]byte, 1)
-	counterSignerAddresses[0] = client.Decode(counterSignerParam)
-	newSequenceID := 1
+	counterSignerPrivKey, _ := crypto.PrivKeyFromBytes(client.Decode(counterSignerParam))
+	nonce := uint64(1)
 
-	committer := types.TxTransferCommitter{Address: privateKey.PubKey().Address()}
+	committer := types.TxTransferCommitter{Address: privateKey.PubKey().Address(), Nonce: nonce}
 	sender := types.TxTransferSender{AccountID: senderID,
 		Amount:   amount,
 		Currency: currency,
-		Sequence: newSequenceID,
 	}
 
 	recipient := types.TxTransferRecipient{AccountID: recipientID}
 
-	counterSigners := make([]types.TxTransferCounterSigner, len(counterSignerAddresses))
-	for i, address := range counterSignerAddresses {
-		privKey, _ := crypto.PrivKeyFromBytes(address)
-		counterSigners[i] = types.TxTransferCounterSigner{Address: privKey.PubKey().Address()}
-		_ = counterSigners[i].SignTx(privKey, chainID)
-	}
+	counterSigners := []types.TxTransferCounterSigner{{Address: counterSignerPrivKey.PubKey().Address()}}
 
 	tx := &types.TransferTx{
 		Committer:      committer,
@@ -667,11 +787,12 @@ func Test_validateTransferTxHash(t *testing.T) {
 	}
 
 	_ = tx.SignTx(privateKey, chainID)
+	_ = tx.SignTx(counterSignerPrivKey, chainID)
 
 	txs := wire.BinaryBytes(struct{ types.Tx }{tx})
 
 	binaryHexa := fmt.Sprintf("%X", txs)
-	binaryExpected := "010114A5211E797F5E5B16929F55C9D53F7327C173201C010A7A7B726C67FCABA236A4E0E8138966D57E81DC11FE4F3129E785809A90A116DB98BFA0922A4866838025D12C794662B0C7256A1079A415B6B4C3F97531C809012431643264663161652D616363622D313165362D626262622D303066663532343461653766000000000000271001034555520101012436623664336130382D353532372D343935352D623466642D66356261376530383335343801010114C74F63C7544631C05ECA679171D824B4250CEDD301FB0A52C4E75170AE29354C391A4CF8FE9CA0A141D67912315621E505B2357EF0261A4E97799F549ECAFAEEAF5EE0D578F79B9DA8010DD6E983512B5A3CDD5902"
+	binaryExpected := "010114A5211E797F5E5B16929F55C9D53F7327C173201C000000000000000100000000000000000001AE4A61ABD9E3012EBBBBEADF47440C043261DF5D5D72D93B82D3296C7A0041E87A330C1A95621CB172DFEE5C98845E40308DA46ECAE773C30404185A23F56E0C012431643264663161652D616363622D313165362D626262622D30306666353234346165376600000000000027100103455552012436623664336130382D353532372D343935352D623466642D663562613765303833353438000001010114C74F63C7544631C05ECA679171D824B4250CEDD301410CA09795DF416797B472F604BF6DF0CACDE05CABC24CDF8F8181487AAFA50F5A20B02B6CEFDE9E2E7CBA2316298240141B01A5E5A1FDA4CEA5E1C45322CB00"
 	if !(binaryHexa == binaryExpected) {
 		t.Errorf("Sign() return %v, expected: %v", binaryHexa, binaryExpected)
 	}
@@ -0,0 +1,169 @@
+package state
+
+import (
+	"encoding/hex"
+
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+)
+
+// ExportGenesis walks the whole state into a genesis document from which
+// LoadGenesis rebuilds an identical state. Objects are listed in the order
+// of their indexes, so that the rebuilt indexes match the original ones.
+func ExportGenesis(state *State) *types.GenesisDoc {
+	doc := newExportDoc(state)
+	for _, section := range exportSections(state) {
+		section.index.Iterate(func(id string) bool {
+			section.add(doc, id)
+			return false
+		})
+	}
+	return doc
+}
+
+// ExportGenesisPage exports at most limit objects of the state, starting
+// from position cursor of the indexes ExportGenesis walks, one after the
+// other. It returns the position the next page starts at, 0 on the last
+// one. The pages of a state appended in order make up ExportGenesis.
+func ExportGenesisPage(state *State, cursor, limit int) (doc *types.GenesisDoc, next int) {
+	doc = newExportDoc(state)
+	next = cursor
+	start := 0 // Position of the section's first object
+	for _, section := range exportSections(state) {
+		n := section.index.Len()
+		if limit > 0 && next < start+n {
+			if next < start {
+				next = start
+			}
+			ids, _ := section.index.Range(next-start, limit)
+			for _, id := range ids {
+				section.add(doc, id)
+			}
+			next += len(ids)
+			limit -= len(ids)
+		}
+		start += n
+	}
+	if next >= start {
+		next = 0
+	}
+	return doc, next
+}
+
+func newExportDoc(state *State) *types.GenesisDoc {
+	return &types.GenesisDoc{
+		Version:       types.GenesisVersion,
+		ChainID:       state.ChainID(),
+		Currencies:    []*types.CurrencyEntry{},
+		LegalEntities: []*types.LegalEntity{},
+		Users:         []*types.User{},
+		Accounts:      []*types.Account{},
+		Supply:        []types.Balance{},
+		Transfers:     []*types.Transfer{},
+		TxResults:     []*types.TxResult{},
+		Txs:           []*types.TxRecord{},
+	}
+}
+
+// exportSection is an index of the objects of a genesis document.
+type exportSection struct {
+	index *Index
+	add   func(doc *types.GenesisDoc, id string)
+}
+
+// exportSections returns the indexes of the objects of a genesis document,
+// in the order LoadGenesis must rebuild them.
+func exportSections(state *State) []exportSection {
+	return []exportSection{
+		{state.CurrencyIndex(), func(doc *types.GenesisDoc, symbol string) {
+			doc.Currencies = append(doc.Currencies, mustGetCurrency(state, symbol))
+		}},
+		{state.LegalEntityIndex(), func(doc *types.GenesisDoc, id string) {
+			doc.LegalEntities = append(doc.LegalEntities, mustGetLegalEntity(state, id))
+		}},
+		{state.UserIndex(), func(doc *types.GenesisDoc, id string) {
+			doc.Users = append(doc.Users, mustGetUser(state, id))
+		}},
+		{state.AccountIndex(), func(doc *types.GenesisDoc, id string) {
+			doc.Accounts = append(doc.Accounts, mustGetAccount(state, id))
+		}},
+		{state.SupplyIndex(), func(doc *types.GenesisDoc, currency string) {
+			doc.Supply = append(doc.Supply, types.Balance{Currency: currency, Amount: state.GetSupply(currency)})
+		}},
+		{state.TransferIndex(), func(doc *types.GenesisDoc, id string) {
+			doc.Transfers = append(doc.Transfers, mustGetTransfer(state, id))
+		}},
+		{state.TxResultIndex(), func(doc *types.GenesisDoc, clientID string) {
+			doc.TxResults = append(doc.TxResults, mustGetTxResult(state, clientID))
+		}},
+		{state.TxRecordIndex(), func(doc *types.GenesisDoc, hash string) {
+			doc.Txs = append(doc.Txs, mustGetTxRecord(state, hash))
+		}},
+	}
+}
+
+// Indexes only list existing objects, a missing one means the state is corrupted.
+
+func mustGetLegalEntity(state *State, id string) *types.LegalEntity {
+	entity := state.GetLegalEntity(id)
+	if entity == nil {
+		common.PanicSanity(common.Fmt("Indexed LegalEntity not found: %q", id))
+	}
+	return entity
+}
+
+func mustGetUser(state *State, id string) *types.User {
+	addr, err := hex.DecodeString(id)
+	if err != nil {
+		common.PanicSanity(common.Fmt("Invalid user index entry: %q", id))
+	}
+	user := state.GetUser(addr)
+	if user == nil {
+		common.PanicSanity(common.Fmt("Indexed User not found: %q", id))
+	}
+	return user
+}
+
+func mustGetAccount(state *State, id string) *types.Account {
+	account := state.GetAccount(id)
+	if account == nil {
+		common.PanicSanity(common.Fmt("Indexed Account not found: %q", id))
+	}
+	return account
+}
+
+func mustGetCurrency(state *State, symbol string) *types.CurrencyEntry {
+	currency := state.GetCurrency(symbol)
+	if currency == nil {
+		common.PanicSanity(common.Fmt("Indexed currency not found: %q", symbol))
+	}
+	return currency
+}
+
+func mustGetTransfer(state *State, id string) *types.Transfer {
+	transfer := state.GetTransfer(id)
+	if transfer == nil {
+		common.PanicSanity(common.Fmt("Indexed transfer not found: %q", id))
+	}
+	return transfer
+}
+
+func mustGetTxResult(state *State, clientID string) *types.TxResult {
+	result := state.GetTxResult(clientID)
+	if result == nil {
+		common.PanicSanity(common.Fmt("Indexed tx result not found: %q", clientID))
+	}
+	return result
+}
+
+func mustGetTxRecord(state *State, id string) *types.TxRecord {
+	hash, err := hex.DecodeString(id)
+	if err != nil {
+		common.PanicSanity(common.Fmt("Invalid tx index entry: %q", id))
+	}
+	record := state.GetTxRecord(hash)
+	if record == nil {
+		common.PanicSanity(common.Fmt("Indexed tx record not found: %q", id))
+	}
+	return record
+}
@@ -0,0 +1,147 @@
+package state
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-wire"
+	eyes "github.com/tendermint/merkleeyes/client"
+)
+
+// mapStore is a KVStore whose hash only depends on its content.
+type mapStore map[string][]byte
+
+func (m mapStore) Get(key []byte) []byte {
+	return m[string(key)]
+}
+
+func (m mapStore) Set(key []byte, value []byte) {
+	m[string(key)] = value
+}
+
+func (m mapStore) Hash() []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write(m[k])
+	}
+	return h.Sum(nil)
+}
+
+func TestExportGenesis_roundTrip(t *testing.T) {
+	// Set up a state from a genesis document, then alter it with txs
+	chainID := "chain"
+	ch := testutil.RandCH()
+	chUser := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0]
+	gcm := testutil.RandGCM(nil)
+	gcm.EntityID = ch.ID
+	acc := testutil.RandAccount(gcm)
+	acc.Wallets = []types.Wallet{{Currency: "EUR", Balance: 100, Sequence: 1}}
+	store := mapStore{}
+	s := NewState(store)
+	LoadGenesis(s, &types.GenesisDoc{
+		Version:       types.GenesisVersion,
+		ChainID:       chainID,
+		LegalEntities: []*types.LegalEntity{ch, gcm},
+		Users:         []*types.User{&chUser.User},
+		Accounts:      []*types.Account{acc},
+	})
+
+	newAccountID := uuid.NewV4().String()
+	txs := []types.SignedTx{
+		&types.CreateAccountTx{Address: chUser.User.PubKey.Address(), Nonce: 1, AccountID: newAccountID},
+		&types.CreateLegalEntityTx{Address: chUser.User.PubKey.Address(), Nonce: 2, EntityID: uuid.NewV4().String(),
+			Type: types.EntityTypeICMByte, Name: "ICM", ParentID: gcm.ID},
+		&types.CreateUserTx{Address: chUser.User.PubKey.Address(), Nonce: 3, Name: "user",
+			PubKey: testutil.PrivUserFromSecret("").User.PubKey},
+		&types.IssueTx{Address: chUser.User.PubKey.Address(), Nonce: 4, ClientID: uuid.NewV4().String(), AccountID: newAccountID, Amount: 500, Currency: "USD"},
+		&types.RedeemTx{Address: chUser.User.PubKey.Address(), Nonce: 5, AccountID: acc.ID, Amount: 40, Currency: "EUR"},
+		&types.TransferTx{
+			Committer: types.TxTransferCommitter{Address: chUser.User.PubKey.Address(), Nonce: 6, ClientID: uuid.NewV4().String()},
+			Sender:    types.TxTransferSender{AccountID: acc.ID, Amount: 10, Currency: "EUR"},
+			Recipient: types.TxTransferRecipient{AccountID: newAccountID},
+			Reference: "trade-1",
+		},
+		// Overdraws the account, which the exported document must accept
+		&types.TransferTx{
+			Committer: types.TxTransferCommitter{Address: chUser.User.PubKey.Address(), Nonce: 7},
+			Sender:    types.TxTransferSender{AccountID: acc.ID, Amount: 200, Currency: "EUR"},
+			Recipient: types.TxTransferRecipient{AccountID: newAccountID},
+		},
+	}
+	for _, tx := range txs {
+		tx.SignTx(chUser.PrivKey, chainID)
+		res := ExecTx(s, nil, tx, false, nil)
+		if res.Code != abci.CodeType_OK {
+			t.Fatalf("ExecTx(%v) = %v", tx, res)
+		}
+		RecordTx(s, wire.BinaryBytes(struct{ types.Tx }{tx}), tx, res)
+	}
+
+	// Export the state, through its JSON form, and import it
+	doc := ExportGenesis(s)
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("GenesisDoc.Validate() error = %v", err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	imported, err := types.GenesisDocFromJSON(data)
+	if err != nil {
+		t.Fatalf("GenesisDocFromJSON() error = %v", err)
+	}
+	importedStore := mapStore{}
+	importedState := NewState(importedStore)
+	LoadGenesis(importedState, imported)
+
+	if got, want := importedStore.Hash(), store.Hash(); !reflect.DeepEqual(got, want) {
+		t.Errorf("imported state's hash = %X, want %X", got, want)
+	}
+	if got := ExportGenesis(importedState); !reflect.DeepEqual(got, doc) {
+		t.Errorf("ExportGenesis() = %v, want %v", got, doc)
+	}
+	if got := GetAccount(importedStore, acc.ID).Wallets[0].Balance; got != -150 {
+		t.Errorf("imported balance = %v, want -150", got)
+	}
+
+	// The pages of the export make up the whole document
+	for _, limit := range []int{1, 4, MaxPageLimit} {
+		paged, cursor := ExportGenesisPage(s, 0, limit)
+		for cursor != 0 {
+			var page *types.GenesisDoc
+			page, cursor = ExportGenesisPage(s, cursor, limit)
+			paged.Append(page)
+		}
+		if !reflect.DeepEqual(paged, doc) {
+			t.Errorf("ExportGenesisPage(limit: %d) = %v, want %v", limit, paged, doc)
+		}
+	}
+
+	// Every node upgrading its chain with the document must compute the
+	// same app hash, however the document was exported
+	eyesCli := eyes.NewLocalClient("", 0)
+	eyesState := NewState(eyesCli)
+	LoadGenesis(eyesState, imported)
+	reimportedCli := eyes.NewLocalClient("", 0)
+	LoadGenesis(NewState(reimportedCli), ExportGenesis(eyesState))
+	want, got := eyesCli.CommitSync(), reimportedCli.CommitSync()
+	if want.IsErr() || got.IsErr() {
+		t.Fatalf("CommitSync() = %v, %v", want, got)
+	}
+	if !reflect.DeepEqual(got.Data, want.Data) {
+		t.Errorf("reimported app hash = %X, want %X", got.Data, want.Data)
+	}
+}
@@ -0,0 +1,47 @@
+package state
+
+import (
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+)
+
+// LoadGenesis initializes the state with the objects of a genesis
+// document, which is expected to have been validated already.
+// The supply is computed from the wallets' balances unless given.
+func LoadGenesis(state *State, doc *types.GenesisDoc) {
+	state.SetChainID(doc.ChainID)
+	if len(doc.Currencies) > 0 {
+		SetCurrencies(state, doc.Currencies)
+	} else {
+		SetCurrencies(state, types.ISOCurrencies())
+	}
+	for _, e := range doc.LegalEntities {
+		state.SetLegalEntity(e.ID, e)
+		SetLegalEntityInIndex(state, e)
+	}
+	for _, u := range doc.Users {
+		state.SetUser(u.PubKey.Address(), u)
+		SetUserInIndex(state, u.PubKey.Address(), u.EntityID)
+	}
+	for _, acc := range doc.Accounts {
+		state.SetAccount(acc.ID, acc)
+		SetAccountInIndex(state, *acc)
+		if len(doc.Supply) == 0 {
+			if err := AddAccountToSupply(state, acc); err != nil {
+				common.PanicSanity(err)
+			}
+		}
+	}
+	for _, b := range doc.Supply {
+		state.SetSupply(b.Currency, b.Amount)
+	}
+	for _, t := range doc.Transfers {
+		state.SetTransfer(t)
+	}
+	for _, r := range doc.TxResults {
+		state.SetTxResult(r)
+	}
+	for _, r := range doc.Txs {
+		state.SetTxRecord(r)
+	}
+}
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"testing"
+
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+)
+
+func TestLoadGenesis(t *testing.T) {
+	ch := testutil.RandCH()
+	user := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0].User
+	acc := testutil.RandAccount(ch)
+	acc.Wallets = []types.Wallet{{Currency: "EUR", Balance: 100, Sequence: 1}}
+	doc := &types.GenesisDoc{
+		Version:       types.GenesisVersion,
+		ChainID:       "chain",
+		LegalEntities: []*types.LegalEntity{ch},
+		Users:         []*types.User{&user},
+		Accounts:      []*types.Account{acc},
+	}
+
+	s := NewState(bscoin.NewMemKVStore())
+	LoadGenesis(s, doc)
+	if got := s.GetChainID(); got != "chain" {
+		t.Errorf("State.GetChainID() = %v, want chain", got)
+	}
+	if !s.GetLegalEntity(ch.ID).Equal(ch) || !s.LegalEntityIndex().Has(ch.ID) {
+		t.Errorf("LoadGenesis() did not store the legal entity %v", ch)
+	}
+	if !s.GetUser(user.PubKey.Address()).Equal(&user) || !s.EntityUserIndex(ch.ID).Has(UserIndexID(user.PubKey.Address())) {
+		t.Errorf("LoadGenesis() did not store the user %v", user)
+	}
+	if !s.GetAccount(acc.ID).Equal(acc) || !s.EntityAccountIndex(ch.ID).Has(acc.ID) {
+		t.Errorf("LoadGenesis() did not store the account %v", acc)
+	}
+	if got := s.GetSupply("EUR"); got != 100 {
+		t.Errorf("State.GetSupply() = %v, want 100", got)
+	}
+	if err := CheckInvariants(s); err != nil {
+		t.Errorf("CheckInvariants() error = %v", err)
+	}
+}
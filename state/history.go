@@ -0,0 +1,324 @@
+package state
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	basecoin "github.com/tendermint/basecoin/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+// History records the values the ledger's store held before they were
+// overwritten at each height, so that queries can be answered as of a past
+// height. History lives in a node-local database outside of the merkle tree
+// as it is not part of consensus; nodes may thus keep different retention
+// windows.
+//
+// Given a height H, the following keys are used in the history database:
+//
+//	h/<H>/<key>  -> value of <key> at height H-1, if <key> changed at H
+//	c/<H>        -> list of keys changed at height H
+//	b/<B>/<key>  -> heights of bucket B that <key> changed at, in order
+//	i/<key>      -> buckets with heights <key> changed at, in order
+//	first        -> lowest height whose changes are still recorded
+//	last         -> last committed height, the state's unless the node stopped
+//	                between their commits
+//	version      -> historyVersion, absent if recorded without buckets
+//
+// Bucket B holds the heights from B*historyBucketSize included to
+// (B+1)*historyBucketSize excluded, so that the first change of a key
+// after a height is found in at most three reads, however long the
+// retention window is.
+type History struct {
+	db        basecoin.KVStore
+	retention uint64            // Number of heights kept, 0 keeps everything
+	height    uint64            // Height of the block being executed, 0 if none
+	changes   map[string][]byte // Previous values of the keys changed at height
+}
+
+const (
+	historyVersion    = 1
+	historyBucketSize = 1024
+)
+
+// NewHistory creates a History on top of the given database.
+func NewHistory(db basecoin.KVStore, retention uint64) *History {
+	return &History{db: db, retention: retention}
+}
+
+// Wrap returns a KVStore that records in h the changes made to store.
+func (h *History) Wrap(store basecoin.KVStore) basecoin.KVStore {
+	return &recordingStore{store: store, history: h}
+}
+
+// BeginBlock starts recording the changes made at height.
+func (h *History) BeginBlock(height uint64) {
+	h.height = height
+	h.changes = make(map[string][]byte)
+}
+
+// Commit persists the changes recorded at the current height and
+// prunes the heights that fell out of the retention window.
+func (h *History) Commit() {
+	if h.height == 0 {
+		return
+	}
+	keys := make([]string, 0, len(h.changes))
+	for key, prev := range h.changes {
+		h.db.Set(historyValueKey(h.height, []byte(key)), prev)
+		h.addChange([]byte(key), h.height)
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	h.db.Set(historyChangesKey(h.height), wire.BinaryBytes(keys))
+	// The heights recorded without buckets can't be queried
+	if h.FirstHeight() == 0 || len(h.db.Get(historyVersionKey())) == 0 {
+		h.setHeight(historyFirstKey(), h.height)
+		h.db.Set(historyVersionKey(), []byte{historyVersion})
+	}
+	h.setHeight(historyLastKey(), h.height)
+	h.prune()
+
+	h.height = 0
+	h.changes = nil
+}
+
+// FirstHeight returns the lowest height that can be queried, 0 if none.
+func (h *History) FirstHeight() uint64 {
+	return h.getHeight(historyFirstKey())
+}
+
+// LastHeight returns the last committed height.
+func (h *History) LastHeight() uint64 {
+	return h.getHeight(historyLastKey())
+}
+
+// At returns a read-only view of store as it was at the given height.
+func (h *History) At(store basecoin.KVStore, height uint64) (basecoin.KVStore, error) {
+	last := h.LastHeight()
+	if height == 0 || height == last {
+		return store, nil
+	}
+	if height > last {
+		return nil, fmt.Errorf("Height %d is greater than the last committed height %d", height, last)
+	}
+	if first := h.FirstHeight(); first == 0 || height+1 < first {
+		return nil, fmt.Errorf("Height %d is out of the history retention window (%d-%d)", height, first, last)
+	}
+	return &historicalStore{store: store, history: h, height: height, last: last}, nil
+}
+
+// record saves the value key had before its first change at the current height.
+func (h *History) record(key []byte, prev []byte) {
+	if h.height == 0 {
+		return
+	}
+	if _, ok := h.changes[string(key)]; ok {
+		return
+	}
+	h.changes[string(key)] = encodeHistoryValue(prev)
+}
+
+func (h *History) prune() {
+	if h.retention == 0 || h.height <= h.retention {
+		return
+	}
+	first := h.FirstHeight()
+	keep := h.height - h.retention + 1
+	for height := first; height < keep; height++ {
+		for _, key := range h.changedKeys(height) {
+			h.db.Set(historyValueKey(height, []byte(key)), []byte{})
+			h.removeChange([]byte(key), height)
+		}
+		h.db.Set(historyChangesKey(height), []byte{})
+	}
+	if first < keep {
+		h.setHeight(historyFirstKey(), keep)
+	}
+}
+
+// addChange adds height to the heights key changed at, after the others.
+func (h *History) addChange(key []byte, height uint64) {
+	bucket := height / historyBucketSize
+	heights := decodeHeights(h.db.Get(historyBucketKey(bucket, key)))
+	if len(heights) == 0 {
+		buckets := decodeHeights(h.db.Get(historyBucketsKey(key)))
+		h.db.Set(historyBucketsKey(key), encodeHeights(append(buckets, bucket)))
+	}
+	h.db.Set(historyBucketKey(bucket, key), encodeHeights(append(heights, height)))
+}
+
+// removeChange removes height from the heights key changed at. As heights
+// are pruned in order, it's the first of its bucket.
+func (h *History) removeChange(key []byte, height uint64) {
+	bucket := height / historyBucketSize
+	heights := decodeHeights(h.db.Get(historyBucketKey(bucket, key)))
+	if len(heights) == 0 || heights[0] != height {
+		return
+	}
+	h.db.Set(historyBucketKey(bucket, key), encodeHeights(heights[1:]))
+	if len(heights) == 1 {
+		buckets := decodeHeights(h.db.Get(historyBucketsKey(key)))
+		if len(buckets) > 0 && buckets[0] == bucket {
+			h.db.Set(historyBucketsKey(key), encodeHeights(buckets[1:]))
+		}
+	}
+}
+
+// nextChange returns the first height after height that key changed at,
+// 0 if none.
+func (h *History) nextChange(key []byte, height uint64) uint64 {
+	buckets := decodeHeights(h.db.Get(historyBucketsKey(key)))
+	i := sort.Search(len(buckets), func(i int) bool { return buckets[i] >= (height+1)/historyBucketSize })
+	// The next change is either in the bucket of height+1, or the first
+	// change of the following bucket
+	for ; i < len(buckets); i++ {
+		heights := decodeHeights(h.db.Get(historyBucketKey(buckets[i], key)))
+		if j := sort.Search(len(heights), func(j int) bool { return heights[j] > height }); j < len(heights) {
+			return heights[j]
+		}
+	}
+	return 0
+}
+
+func (h *History) changedKeys(height uint64) (keys []string) {
+	data := h.db.Get(historyChangesKey(height))
+	if len(data) == 0 {
+		return nil
+	}
+	if err := wire.ReadBinaryBytes(data, &keys); err != nil {
+		panic(common.Fmt("Error reading history changes %X error: %v",
+			data, err.Error()))
+	}
+	return keys
+}
+
+func (h *History) getHeight(key []byte) uint64 {
+	data := h.db.Get(key)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+func (h *History) setHeight(key []byte, height uint64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], height)
+	h.db.Set(key, b[:])
+}
+
+//----------------------------------------
+
+// recordingStore records the previous values of the keys it sets.
+type recordingStore struct {
+	store   basecoin.KVStore
+	history *History
+}
+
+func (s *recordingStore) Get(key []byte) []byte {
+	return s.store.Get(key)
+}
+
+func (s *recordingStore) Set(key []byte, value []byte) {
+	s.history.record(key, s.store.Get(key))
+	s.store.Set(key, value)
+}
+
+// historicalStore is a read-only view of the store at a past height.
+type historicalStore struct {
+	store   basecoin.KVStore
+	history *History
+	height  uint64
+	last    uint64
+}
+
+// Get returns the value key had at the end of height: that is the value
+// recorded at the first later height key changed at or, if it didn't
+// change since, its current value.
+func (s *historicalStore) Get(key []byte) []byte {
+	if height := s.history.nextChange(key, s.height); height != 0 && height <= s.last {
+		if data := s.history.db.Get(historyValueKey(height, key)); len(data) > 0 {
+			return decodeHistoryValue(data)
+		}
+	}
+	return s.store.Get(key)
+}
+
+func (s *historicalStore) Set(key []byte, value []byte) {
+	common.PanicSanity("Historical state is read-only")
+}
+
+//----------------------------------------
+
+func historyValueKey(height uint64, key []byte) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], height)
+	k := append([]byte("h/"), b[:]...)
+	k = append(k, '/')
+	return append(k, key...)
+}
+
+func historyChangesKey(height uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], height)
+	return append([]byte("c/"), b[:]...)
+}
+
+func historyBucketKey(bucket uint64, key []byte) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], bucket)
+	k := append([]byte("b/"), b[:]...)
+	k = append(k, '/')
+	return append(k, key...)
+}
+
+func historyBucketsKey(key []byte) []byte {
+	return append([]byte("i/"), key...)
+}
+
+func historyVersionKey() []byte {
+	return []byte("version")
+}
+
+func historyFirstKey() []byte {
+	return []byte("first")
+}
+
+func historyLastKey() []byte {
+	return []byte("last")
+}
+
+// Values are prefixed so that a key which did not exist can be told
+// apart from a pruned entry: 0x00 means absent, 0x01 precedes the value.
+func encodeHistoryValue(value []byte) []byte {
+	if len(value) == 0 {
+		return []byte{0x00}
+	}
+	return append([]byte{0x01}, value...)
+}
+
+func decodeHistoryValue(data []byte) []byte {
+	if data[0] == 0x00 {
+		return nil
+	}
+	return data[1:]
+}
+
+// Lists of heights or buckets are encoded as big endian uint64s.
+func encodeHeights(heights []uint64) []byte {
+	data := make([]byte, 8*len(heights))
+	for i, height := range heights {
+		binary.BigEndian.PutUint64(data[8*i:], height)
+	}
+	return data
+}
+
+func decodeHeights(data []byte) []uint64 {
+	heights := make([]uint64, len(data)/8)
+	for i := range heights {
+		heights[i] = binary.BigEndian.Uint64(data[8*i:])
+	}
+	return heights
+}
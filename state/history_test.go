@@ -0,0 +1,150 @@
+package state
+
+import (
+	"strconv"
+	"testing"
+
+	bscoin "github.com/tendermint/basecoin/types"
+)
+
+func TestHistory_At(t *testing.T) {
+	history := NewHistory(bscoin.NewMemKVStore(), 0)
+	store := history.Wrap(bscoin.NewMemKVStore())
+	key := []byte("key")
+	created := []byte("created")
+
+	// Genesis: height 0 is not recorded
+	store.Set([]byte("genesis"), []byte("g"))
+	history.Commit()
+	// Height 1: key doesn't exist yet
+	history.BeginBlock(1)
+	history.Commit()
+	// Height 2: key is created, then changed twice
+	history.BeginBlock(2)
+	store.Set(key, []byte("v1"))
+	store.Set(key, []byte("v2"))
+	store.Set(created, []byte("c"))
+	history.Commit()
+	// Height 3: nothing changes
+	history.BeginBlock(3)
+	history.Commit()
+	// Height 4: key changes
+	history.BeginBlock(4)
+	store.Set(key, []byte("v3"))
+	history.Commit()
+
+	tests := []struct {
+		name    string
+		height  uint64
+		key     []byte
+		want    string
+		wantErr bool
+	}{
+		{"beforeFirstHeight", 0, key, "v3", false},
+		{"genesis", 1, []byte("genesis"), "g", false},
+		{"notCreatedYet", 1, key, "", false},
+		{"lastWriteOfHeight", 2, key, "v2", false},
+		{"unchangedHeight", 3, key, "v2", false},
+		{"lastHeight", 4, key, "v3", false},
+		{"createdAtHeight", 1, created, "", false},
+		{"createdAfterHeight", 3, created, "c", false},
+		{"futureHeight", 5, key, "", true},
+	}
+	for _, tt := range tests {
+		view, err := history.At(store, tt.height)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. History.At() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if got := view.Get(tt.key); string(got) != tt.want {
+			t.Errorf("%q. Get() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestHistory_prune(t *testing.T) {
+	history := NewHistory(bscoin.NewMemKVStore(), 2)
+	store := history.Wrap(bscoin.NewMemKVStore())
+	key := []byte("key")
+	for height := uint64(1); height <= 5; height++ {
+		history.BeginBlock(height)
+		store.Set(key, []byte{byte(height)})
+		history.Commit()
+	}
+	if got := history.FirstHeight(); got != 4 {
+		t.Errorf("History.FirstHeight() = %v, want 4", got)
+	}
+	if _, err := history.At(store, 2); err == nil {
+		t.Errorf("History.At(2) expected to fail out of the retention window")
+	}
+	view, err := history.At(store, 3)
+	if err != nil {
+		t.Fatalf("History.At(3) error = %v", err)
+	}
+	if got := view.Get(key); len(got) != 1 || got[0] != 3 {
+		t.Errorf("Get() = %v, want [3]", got)
+	}
+	if got := len(history.changedKeys(3)); got != 0 {
+		t.Errorf("History.changedKeys(3) returned %v keys, want 0", got)
+	}
+}
+
+// countingStore counts the reads of a store.
+type countingStore struct {
+	bscoin.KVStore
+	gets int
+}
+
+func (s *countingStore) Get(key []byte) []byte {
+	s.gets++
+	return s.KVStore.Get(key)
+}
+
+func TestHistory_longRetention(t *testing.T) {
+	db := &countingStore{KVStore: bscoin.NewMemKVStore()}
+	history := NewHistory(db, 100000)
+	store := history.Wrap(bscoin.NewMemKVStore())
+	hot, cold := []byte("hot"), []byte("cold")
+	for height := uint64(1); height <= 3000; height++ {
+		history.BeginBlock(height)
+		value := []byte(strconv.FormatUint(height, 10))
+		store.Set(hot, value)
+		if height == 2 || height == 2500 {
+			store.Set(cold, value)
+		}
+		history.Commit()
+	}
+
+	tests := []struct {
+		name   string
+		height uint64
+		key    []byte
+		want   string
+	}{
+		{"startOfWindow", 1, hot, "1"},
+		{"notCreatedYet", 1, cold, ""},
+		{"created", 2, cold, "2"},
+		{"endOfBucket", 1023, hot, "1023"},
+		{"emptyBucket", 1024, cold, "2"},
+		{"beforeChange", 2499, cold, "2"},
+		{"unchangedSince", 2500, cold, "2500"},
+		{"beforeLastHeight", 2999, hot, "2999"},
+	}
+	for _, tt := range tests {
+		view, err := history.At(store, tt.height)
+		if err != nil {
+			t.Errorf("%q. History.At() error = %v", tt.name, err)
+			continue
+		}
+		db.gets = 0
+		if got := view.Get(tt.key); string(got) != tt.want {
+			t.Errorf("%q. Get() = %q, want %q", tt.name, got, tt.want)
+		}
+		if db.gets > 4 {
+			t.Errorf("%q. Get() read the history %d times, want at most 4", tt.name, db.gets)
+		}
+	}
+}
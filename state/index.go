@@ -0,0 +1,131 @@
+package state
+
+import (
+	"encoding/binary"
+
+	basecoin "github.com/tendermint/basecoin/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+// Index is an append-only, ordered list of IDs persisted in a KVStore.
+// Every entry lives under its own key, hence adding or looking up an ID
+// never requires the whole list to be loaded and rewritten.
+//
+// Given a prefix P, the following keys are used:
+//
+//	P/n          -> number of entries
+//	P/k/<id>     -> position of <id> in the list, 1-based
+//	P/p/<pos>    -> ID stored at the 0-based position <pos>
+type Index struct {
+	store  basecoin.KVStore
+	prefix string
+}
+
+// NewIndex creates an Index on top of the given store.
+func NewIndex(store basecoin.KVStore, prefix string) *Index {
+	return &Index{store: store, prefix: prefix}
+}
+
+// Len returns the number of entries of the index.
+func (i *Index) Len() int {
+	data := i.store.Get(i.lenKey())
+	if len(data) == 0 {
+		return 0
+	}
+	var n int
+	if err := wire.ReadBinaryBytes(data, &n); err != nil {
+		panic(common.Fmt("Error reading index length %X error: %v",
+			data, err.Error()))
+	}
+	return n
+}
+
+// Has returns whether id is listed in the index.
+func (i *Index) Has(id string) bool {
+	return len(i.store.Get(i.idKey(id))) != 0
+}
+
+// Add appends id to the index. It returns false if id was already there.
+func (i *Index) Add(id string) bool {
+	if i.Has(id) {
+		return false
+	}
+	n := i.Len()
+	i.store.Set(i.posKey(n), []byte(id))
+	i.store.Set(i.idKey(id), wire.BinaryBytes(n+1))
+	i.store.Set(i.lenKey(), wire.BinaryBytes(n+1))
+	return true
+}
+
+// Get returns the ID stored at position pos, or "" if out of range.
+func (i *Index) Get(pos int) string {
+	if pos < 0 {
+		return ""
+	}
+	return string(i.store.Get(i.posKey(pos)))
+}
+
+// Range returns at most limit IDs starting from position cursor,
+// along with the position the next page starts at. The latter is
+// 0 when there are no more entries to iterate.
+func (i *Index) Range(cursor, limit int) (ids []string, next int) {
+	n := i.Len()
+	if cursor < 0 {
+		cursor = 0
+	}
+	ids = []string{}
+	for pos := cursor; pos < n && len(ids) < limit; pos++ {
+		ids = append(ids, i.Get(pos))
+	}
+	if next = cursor + len(ids); next >= n {
+		next = 0
+	}
+	return ids, next
+}
+
+// Scan calls match for the IDs starting from position cursor until either
+// limit of them matched or maxScan IDs were visited. It returns the position
+// the next scan should start at, or 0 when the end of the index was reached.
+func (i *Index) Scan(cursor, limit, maxScan int, match func(id string) bool) (next int) {
+	n := i.Len()
+	if cursor < 0 {
+		cursor = 0
+	}
+	matched := 0
+	pos := cursor
+	for ; pos < n && matched < limit && pos-cursor < maxScan; pos++ {
+		if match(i.Get(pos)) {
+			matched++
+		}
+	}
+	if pos >= n {
+		return 0
+	}
+	return pos
+}
+
+// Iterate calls fn for every ID in the index, in insertion order,
+// until fn returns true.
+func (i *Index) Iterate(fn func(id string) (stop bool)) {
+	n := i.Len()
+	for pos := 0; pos < n; pos++ {
+		if fn(i.Get(pos)) {
+			return
+		}
+	}
+}
+
+func (i *Index) lenKey() []byte {
+	return []byte(i.prefix + "/n")
+}
+
+func (i *Index) idKey(id string) []byte {
+	return append([]byte(i.prefix+"/k/"), id...)
+}
+
+func (i *Index) posKey(pos int) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(pos))
+	return append([]byte(i.prefix+"/p/"), b[:]...)
+}
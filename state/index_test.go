@@ -0,0 +1,87 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	bscoin "github.com/tendermint/basecoin/types"
+)
+
+func TestIndex_Add(t *testing.T) {
+	index := NewIndex(bscoin.NewMemKVStore(), "test")
+	tests := []struct {
+		name    string
+		id      string
+		want    bool
+		wantLen int
+	}{
+		{"addNewItem", "a", true, 1},
+		{"addAnotherItem", "b", true, 2},
+		{"addExistingItem", "a", false, 2},
+	}
+	for _, tt := range tests {
+		if got := index.Add(tt.id); got != tt.want {
+			t.Errorf("%q. Index.Add() = %v, want %v", tt.name, got, tt.want)
+		}
+		if !index.Has(tt.id) {
+			t.Errorf("%q. Index.Has() = false, want true", tt.name)
+		}
+		if got := index.Len(); got != tt.wantLen {
+			t.Errorf("%q. Index.Len() = %v, want %v", tt.name, got, tt.wantLen)
+		}
+	}
+	if index.Has("c") {
+		t.Errorf("Index.Has(%q) = true, want false", "c")
+	}
+}
+
+func TestIndex_Range(t *testing.T) {
+	store := bscoin.NewMemKVStore()
+	index := NewIndex(store, "test")
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		index.Add(id)
+	}
+	// Indexes sharing a store must not interfere with each other
+	NewIndex(store, "test2").Add("z")
+
+	type args struct {
+		cursor int
+		limit  int
+	}
+	tests := []struct {
+		name     string
+		args     args
+		wantIDs  []string
+		wantNext int
+	}{
+		{"firstPage", args{0, 2}, []string{"a", "b"}, 2},
+		{"middlePage", args{2, 2}, []string{"c", "d"}, 4},
+		{"lastPage", args{4, 2}, []string{"e"}, 0},
+		{"wholeIndex", args{0, 10}, []string{"a", "b", "c", "d", "e"}, 0},
+		{"outOfRange", args{10, 2}, []string{}, 0},
+	}
+	for _, tt := range tests {
+		ids, next := index.Range(tt.args.cursor, tt.args.limit)
+		if !reflect.DeepEqual(ids, tt.wantIDs) {
+			t.Errorf("%q. Index.Range() ids = %v, want %v", tt.name, ids, tt.wantIDs)
+		}
+		if next != tt.wantNext {
+			t.Errorf("%q. Index.Range() next = %v, want %v", tt.name, next, tt.wantNext)
+		}
+	}
+}
+
+func TestIndex_Iterate(t *testing.T) {
+	index := NewIndex(bscoin.NewMemKVStore(), "test")
+	for _, id := range []string{"a", "b", "c"} {
+		index.Add(id)
+	}
+	got := []string{}
+	index.Iterate(func(id string) bool {
+		got = append(got, id)
+		return id == "b"
+	})
+	if want := []string{"a", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Index.Iterate() visited %v, want %v", got, want)
+	}
+}
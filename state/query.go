@@ -0,0 +1,539 @@
+package state
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/satori/go.uuid"
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+const (
+	// DefaultPageLimit is the number of items returned by list queries
+	// when the limit parameter is not given.
+	DefaultPageLimit = 100
+	// MaxPageLimit is the maximum number of items list queries can return.
+	MaxPageLimit = 1000
+	// MaxPageScan is the maximum number of index entries a filtered list
+	// query visits; pages may hence be shorter than the requested limit.
+	MaxPageScan = 10 * MaxPageLimit
+)
+
+// Query defines the attributes of a parsed query path, e.g.
+// /legal_entity/<id>/accounts?cursor=100&limit=50
+type Query struct {
+	Resource    string     // Type of the requested objects, e.g. account
+	Object      string     // ID of the requested object, may be empty
+	SubResource string     // Objects related to Object, e.g. accounts, may be empty
+	Params      url.Values // Query string parameters
+}
+
+// ExecQuery handles queries.
+func ExecQuery(state *State, q Query) abci.ResponseQuery {
+	switch {
+	case q.Resource == "account" && len(q.Object) > 0:
+		return accountQuery(state, q.Object, q)
+
+	case q.Resource == "account" && len(q.Object) == 0:
+		return accountIndexQuery(state, state.AccountIndex(), q)
+
+	case q.Resource == "legal_entity" && len(q.Object) > 0 && q.SubResource == "accounts":
+		return entityAccountIndexQuery(state, q.Object, q)
+
+	case q.Resource == "legal_entity" && len(q.Object) > 0 && q.SubResource == "balances":
+		return balancesQuery(state, q.Object, q)
+
+	case q.Resource == "legal_entity" && len(q.Object) > 0 && len(q.SubResource) == 0:
+		return legalEntityQuery(state, q.Object)
+
+	case q.Resource == "legal_entity" && len(q.Object) == 0:
+		return legalEntityIndexQuery(state, q)
+
+	case q.Resource == "user" && len(q.Object) > 0:
+		return userQuery(state, q.Object)
+
+	case q.Resource == "accounts" && len(q.Object) == 0:
+		return accountListQuery(state, q)
+
+	case q.Resource == "legal_entities" && len(q.Object) == 0:
+		return legalEntityListQuery(state, q)
+
+	case q.Resource == "users" && len(q.Object) == 0:
+		return userListQuery(state, q)
+
+	case q.Resource == "currency" && len(q.Object) > 0:
+		return currencyQuery(state, q.Object)
+
+	case q.Resource == "currencies" && len(q.Object) == 0:
+		return currencyListQuery(state, q)
+
+	case q.Resource == "transfer" && len(q.Object) > 0:
+		return transferQuery(state, q.Object)
+
+	case q.Resource == "transfers" && len(q.Object) == 0:
+		return transferListQuery(state, q)
+
+	case q.Resource == "tx" && len(q.Object) > 0:
+		return txQuery(state, q.Object)
+
+	case q.Resource == "supply" && len(q.Object) == 0:
+		return supplyQuery(state, q)
+
+	case q.Resource == "export" && len(q.Object) == 0:
+		return exportQuery(state, q)
+
+	default:
+		return abci.ResponseQuery{
+			Code: abci.CodeType_BaseEncodingError,
+			Log:  common.Fmt("Unknown resource and object: %v/%v/%v", q.Resource, q.Object, q.SubResource),
+		}
+	}
+}
+
+func accountQuery(state *State, accountID string, q Query) (res abci.ResponseQuery) {
+	decimal, err := boolParam(q.Params, "decimal")
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	account := state.GetAccount(accountID)
+	if account == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown account: %q", accountID)
+		return
+	}
+	returned := types.AccountsReturned{Account: []*types.Account{account}}
+	if decimal {
+		returned.Balances = formatAccounts(state, returned.Account)
+	}
+	return jsonResponse(returned)
+}
+
+func accountIndexQuery(state *State, index *Index, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	ids, next := index.Range(cursor, limit)
+	return jsonResponse(types.AccountIndex{Accounts: ids, Next: formatCursor(next)})
+}
+
+func entityAccountIndexQuery(state *State, entityID string, q Query) (res abci.ResponseQuery) {
+	if state.GetLegalEntity(entityID) == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown legal entity: %q", entityID)
+		return
+	}
+	return accountIndexQuery(state, state.EntityAccountIndex(entityID), q)
+}
+
+func legalEntityQuery(state *State, entityID string) (res abci.ResponseQuery) {
+	legalEntity := state.GetLegalEntity(entityID)
+	if legalEntity == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown legal entity: %q", entityID)
+		return
+	}
+	return jsonResponse(types.LegalEntitiesReturned{LegalEntities: []*types.LegalEntity{legalEntity}})
+}
+
+func legalEntityIndexQuery(state *State, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	ids, next := state.LegalEntityIndex().Range(cursor, limit)
+	return jsonResponse(types.LegalEntityIndex{Ids: ids, Next: formatCursor(next)})
+}
+
+// balancesQuery returns the aggregated balances of a legal entity,
+// rolled up across its descendants if the rollup parameter is true.
+func balancesQuery(state *State, entityID string, q Query) (res abci.ResponseQuery) {
+	rollup, err := boolParam(q.Params, "rollup")
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	decimal, err := boolParam(q.Params, "decimal")
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	report := BalanceReport(state, entityID, rollup)
+	if report == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown legal entity: %q", entityID)
+		return
+	}
+	if decimal {
+		types.FormatBalances(state, report.Totals)
+		for _, entity := range report.Entities {
+			types.FormatBalances(state, entity.Balances)
+		}
+	}
+	return jsonResponse(report)
+}
+
+// supplyQuery returns the supply report, with amounts rendered as
+// decimal strings if the decimal parameter is true.
+func supplyQuery(state *State, q Query) (res abci.ResponseQuery) {
+	decimal, err := boolParam(q.Params, "decimal")
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	report := SupplyReport(state)
+	if decimal {
+		types.FormatBalances(state, report.Supply)
+		types.FormatBalances(state, report.Balances)
+	}
+	return jsonResponse(report)
+}
+
+// exportQuery returns a page of the export of the state, which is paged
+// as a whole state can't be returned by a single query.
+func exportQuery(state *State, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	doc, next := ExportGenesisPage(state, cursor, limit)
+	return jsonResponse(types.GenesisPage{Genesis: doc, Next: formatCursor(next)})
+}
+
+func userQuery(state *State, addrHex string) (res abci.ResponseQuery) {
+	addr, err := hex.DecodeString(addrHex)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = common.Fmt("Invalid address: %q", addrHex)
+		return
+	}
+	user := state.GetUser(addr)
+	if user == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown user: %q", addrHex)
+		return
+	}
+	res.Code = abci.CodeType_OK
+	res.Value = wire.JSONBytes(types.UsersReturned{Users: []*types.User{user}})
+	return
+}
+
+//--------------------------------------------------------------------------------
+
+// accountListQuery returns a page of accounts matching the filters
+//
+//	entity_id  accounts owned by the given legal entity
+//	currency   accounts having a wallet in the given currency
+//	non_zero   if true, accounts with a non-zero balance (in currency, if given)
+func accountListQuery(state *State, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	nonZero, err := boolParam(q.Params, "non_zero")
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	decimal, err := boolParam(q.Params, "decimal")
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	currency := q.Params.Get("currency")
+	index := state.AccountIndex()
+	if entityID := q.Params.Get("entity_id"); len(entityID) > 0 {
+		index = state.EntityAccountIndex(entityID)
+	}
+
+	accounts := []*types.Account{}
+	next := index.Scan(cursor, limit, MaxPageScan, func(id string) bool {
+		acc := state.GetAccount(id)
+		if acc == nil || !accountMatches(acc, currency, nonZero) {
+			return false
+		}
+		accounts = append(accounts, acc)
+		return true
+	})
+	returned := types.AccountsReturned{Account: accounts, Next: formatCursor(next)}
+	if decimal {
+		returned.Balances = formatAccounts(state, accounts)
+	}
+	return jsonResponse(returned)
+}
+
+// formatAccounts returns the balances of the accounts' wallets, keyed by
+// account ID, with amounts rendered as decimal strings.
+func formatAccounts(state *State, accounts []*types.Account) map[string][]types.Balance {
+	formatted := make(map[string][]types.Balance, len(accounts))
+	for _, acc := range accounts {
+		balances := make([]types.Balance, len(acc.Wallets))
+		for i, wal := range acc.Wallets {
+			balances[i] = types.Balance{Currency: wal.Currency, Amount: wal.Balance}
+		}
+		types.FormatBalances(state, balances)
+		formatted[acc.ID] = balances
+	}
+	return formatted
+}
+
+func accountMatches(acc *types.Account, currency string, nonZero bool) bool {
+	if len(currency) == 0 && !nonZero {
+		return true
+	}
+	for _, wal := range acc.Wallets {
+		if len(currency) > 0 && wal.Currency != currency {
+			continue
+		}
+		if !nonZero || wal.Balance != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// legalEntityListQuery returns a page of legal entities matching the filters
+//
+//	type       entities of the given type, either its name or numeric value
+//	parent_id  entities whose parent is the given legal entity
+func legalEntityListQuery(state *State, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	var entityType byte
+	if s := q.Params.Get("type"); len(s) > 0 {
+		var ok bool
+		if entityType, ok = types.ParseEntityType(s); !ok {
+			res.Code = abci.CodeType_BaseInvalidInput
+			res.Log = common.Fmt("Invalid type: %q", s)
+			return
+		}
+	}
+	parentID := q.Params.Get("parent_id")
+
+	entities := []*types.LegalEntity{}
+	next := state.LegalEntityIndex().Scan(cursor, limit, MaxPageScan, func(id string) bool {
+		ent := state.GetLegalEntity(id)
+		if ent == nil || (entityType != 0 && ent.Type != entityType) ||
+			(len(parentID) > 0 && ent.EntityID != parentID) {
+			return false
+		}
+		entities = append(entities, ent)
+		return true
+	})
+	return jsonResponse(types.LegalEntitiesReturned{LegalEntities: entities, Next: formatCursor(next)})
+}
+
+// userListQuery returns a page of users matching the filters
+//
+//	entity_id  users belonging to the given legal entity
+func userListQuery(state *State, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	index := state.UserIndex()
+	if entityID := q.Params.Get("entity_id"); len(entityID) > 0 {
+		index = state.EntityUserIndex(entityID)
+	}
+
+	users := []*types.User{}
+	next := index.Scan(cursor, limit, MaxPageScan, func(id string) bool {
+		addr, err := hex.DecodeString(id)
+		if err != nil {
+			return false
+		}
+		user := state.GetUser(addr)
+		if user == nil {
+			return false
+		}
+		users = append(users, user)
+		return true
+	})
+	res.Code = abci.CodeType_OK
+	res.Value = wire.JSONBytes(types.UsersReturned{Users: users, Next: formatCursor(next)})
+	return
+}
+
+//--------------------------------------------------------------------------------
+
+func currencyQuery(state *State, symbol string) (res abci.ResponseQuery) {
+	currency := state.GetCurrency(symbol)
+	if currency == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown currency: %q", symbol)
+		return
+	}
+	return jsonResponse(types.CurrenciesReturned{Currencies: []*types.CurrencyEntry{currency}})
+}
+
+// currencyListQuery returns a page of the currency registry,
+// optionally filtered on whether currencies are retired.
+func currencyListQuery(state *State, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	filterRetired := len(q.Params.Get("retired")) > 0
+	retired, err := boolParam(q.Params, "retired")
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+
+	currencies := []*types.CurrencyEntry{}
+	next := state.CurrencyIndex().Scan(cursor, limit, MaxPageScan, func(symbol string) bool {
+		currency := state.GetCurrency(symbol)
+		if currency == nil || (filterRetired && currency.Retired != retired) {
+			return false
+		}
+		currencies = append(currencies, currency)
+		return true
+	})
+	return jsonResponse(types.CurrenciesReturned{Currencies: currencies, Next: formatCursor(next)})
+}
+
+func transferQuery(state *State, id string) (res abci.ResponseQuery) {
+	transfer := state.GetTransfer(id)
+	if transfer == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown transfer: %q", id)
+		return
+	}
+	return jsonResponse(types.TransfersReturned{Transfers: []*types.Transfer{transfer}})
+}
+
+// transferListQuery returns a page of the transfer history, oldest first,
+// optionally restricted to the transfers carrying a reference.
+func transferListQuery(state *State, q Query) (res abci.ResponseQuery) {
+	cursor, limit, err := pageParams(q.Params)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = err.Error()
+		return
+	}
+	index := state.TransferIndex()
+	if reference := q.Params.Get("reference"); len(reference) > 0 {
+		index = state.TransferReferenceIndex(reference)
+	}
+
+	ids, next := index.Range(cursor, limit)
+	transfers := make([]*types.Transfer, 0, len(ids))
+	for _, id := range ids {
+		transfers = append(transfers, mustGetTransfer(state, id))
+	}
+	return jsonResponse(types.TransfersReturned{Transfers: transfers, Next: formatCursor(next)})
+}
+
+// txQuery returns the result of the executed Tx carrying a ClientID if
+// given a UUID, the record of the delivered Tx with the given hash otherwise.
+func txQuery(state *State, id string) (res abci.ResponseQuery) {
+	if _, err := uuid.FromString(id); err == nil {
+		return txResultQuery(state, id)
+	}
+	hash, err := hex.DecodeString(id)
+	if err != nil {
+		res.Code = abci.CodeType_BaseInvalidInput
+		res.Log = common.Fmt("Invalid tx hash or client_id: %q", id)
+		return
+	}
+	record := state.GetTxRecord(hash)
+	if record == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown tx: %q", id)
+		return
+	}
+	return jsonResponse(record)
+}
+
+// txResultQuery returns the result of the executed Tx carrying a ClientID.
+// An unknown ClientID means the Tx was not executed, or not yet.
+func txResultQuery(state *State, clientID string) (res abci.ResponseQuery) {
+	result := state.GetTxResult(clientID)
+	if result == nil {
+		res.Code = abci.CodeType_BaseUnknownAddress
+		res.Log = common.Fmt("Unknown tx: %q", clientID)
+		return
+	}
+	return jsonResponse(result)
+}
+
+func jsonResponse(v interface{}) (res abci.ResponseQuery) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		res.Code = abci.CodeType_InternalError
+		res.Log = common.Fmt("Couldn't make the response: %v", err)
+		return
+	}
+	res.Code = abci.CodeType_OK
+	res.Value = data
+	return
+}
+
+// pageParams extracts cursor and limit from the query string parameters.
+func pageParams(params url.Values) (cursor int, limit int, err error) {
+	limit = DefaultPageLimit
+	if s := params.Get("cursor"); len(s) > 0 {
+		if cursor, err = strconv.Atoi(s); err != nil || cursor < 0 {
+			return 0, 0, fmt.Errorf("Invalid cursor: %q", s)
+		}
+	}
+	if s := params.Get("limit"); len(s) > 0 {
+		if limit, err = strconv.Atoi(s); err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("Invalid limit: %q", s)
+		}
+	}
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+	return cursor, limit, nil
+}
+
+// boolParam parses an optional boolean query string parameter.
+func boolParam(params url.Values, key string) (bool, error) {
+	s := params.Get(key)
+	if len(s) == 0 {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("Invalid %s: %q", key, s)
+	}
+	return b, nil
+}
+
+func formatCursor(next int) string {
+	if next == 0 {
+		return ""
+	}
+	return strconv.Itoa(next)
+}
@@ -0,0 +1,151 @@
+package state
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-wire"
+)
+
+func TestExecQuery_lists(t *testing.T) {
+	// Set up fixtures
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID("chain")
+	ch := testutil.RandCH()
+	gcm := testutil.RandGCM(nil)
+	gcm.EntityID = ch.ID
+	for _, e := range []*types.LegalEntity{ch, gcm} {
+		s.SetLegalEntity(e.ID, e)
+		SetLegalEntityInIndex(s, e)
+	}
+	chAccounts := testutil.RandAccounts(3, ch)
+	gcmAccounts := testutil.RandAccounts(3, gcm)
+	for _, acc := range append(chAccounts, gcmAccounts[2]) {
+		acc.Wallets = []types.Wallet{{Currency: "GBP", Balance: 0}}
+	}
+	gcmAccounts[0].Wallets = []types.Wallet{{Currency: "EUR", Balance: 100}}
+	gcmAccounts[1].Wallets = []types.Wallet{{Currency: "EUR", Balance: 0}, {Currency: "USD", Balance: 5}}
+	for _, acc := range append(chAccounts, gcmAccounts...) {
+		s.SetAccount(acc.ID, acc)
+		SetAccountInIndex(s, *acc)
+	}
+	users := testutil.RandUsersWithLegalEntity(2, gcm, gcm.Permissions)
+	for _, u := range users {
+		s.SetUser(u.User.PubKey.Address(), &u.User)
+		SetUserInIndex(s, u.User.PubKey.Address(), u.User.EntityID)
+	}
+
+	accountsJSON := func(next string, accs ...*types.Account) []byte {
+		data, _ := json.Marshal(types.AccountsReturned{Account: accs, Next: next})
+		return data
+	}
+	entitiesJSON := func(next string, ents ...*types.LegalEntity) []byte {
+		data, _ := json.Marshal(types.LegalEntitiesReturned{LegalEntities: ents, Next: next})
+		return data
+	}
+	usersJSON := func(next string, usrs ...*types.User) []byte {
+		if usrs == nil {
+			usrs = []*types.User{}
+		}
+		return wire.JSONBytes(types.UsersReturned{Users: usrs, Next: next})
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  abci.ResponseQuery
+	}{
+		{"allAccounts", Query{Resource: "accounts"},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: accountsJSON("", append(chAccounts, gcmAccounts...)...)}},
+		{"accountsPage", Query{Resource: "accounts", Params: url.Values{"limit": {"2"}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: accountsJSON("2", chAccounts[:2]...)}},
+		{"accountsByEntity", Query{Resource: "accounts", Params: url.Values{"entity_id": {gcm.ID}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: accountsJSON("", gcmAccounts...)}},
+		{"accountsByCurrency", Query{Resource: "accounts", Params: url.Values{"currency": {"EUR"}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: accountsJSON("", gcmAccounts[:2]...)}},
+		{"accountsNonZeroByCurrency", Query{Resource: "accounts", Params: url.Values{"currency": {"EUR"}, "non_zero": {"true"}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: accountsJSON("", gcmAccounts[0])}},
+		{"accountsNonZero", Query{Resource: "accounts", Params: url.Values{"non_zero": {"true"}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: accountsJSON("", gcmAccounts[:2]...)}},
+		{"accountsNonZeroFilteredPage", Query{Resource: "accounts", Params: url.Values{"non_zero": {"true"}, "limit": {"1"}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: accountsJSON("4", gcmAccounts[0])}},
+		{"accountsInvalidNonZero", Query{Resource: "accounts", Params: url.Values{"non_zero": {"maybe"}}},
+			abci.ResponseQuery{Code: abci.CodeType_BaseInvalidInput}},
+		{"allEntities", Query{Resource: "legal_entities"},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: entitiesJSON("", ch, gcm)}},
+		{"entitiesByType", Query{Resource: "legal_entities", Params: url.Values{"type": {"gcm"}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: entitiesJSON("", gcm)}},
+		{"entitiesByParent", Query{Resource: "legal_entities", Params: url.Values{"parent_id": {ch.ID}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: entitiesJSON("", gcm)}},
+		{"entitiesInvalidType", Query{Resource: "legal_entities", Params: url.Values{"type": {"bank"}}},
+			abci.ResponseQuery{Code: abci.CodeType_BaseInvalidInput}},
+		{"allUsers", Query{Resource: "users"},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: usersJSON("", &users[0].User, &users[1].User)}},
+		{"usersByEntity", Query{Resource: "users", Params: url.Values{"entity_id": {ch.ID}}},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: usersJSON("")}},
+		{"user", Query{Resource: "user", Object: UserIndexID(users[0].User.PubKey.Address())},
+			abci.ResponseQuery{Code: abci.CodeType_OK, Value: usersJSON("", &users[0].User)}},
+		{"unknownUser", Query{Resource: "user", Object: "00"},
+			abci.ResponseQuery{Code: abci.CodeType_BaseUnknownAddress}},
+	}
+	for _, tt := range tests {
+		got := ExecQuery(s, tt.query)
+		if got.Code != tt.want.Code {
+			t.Errorf("%q. ExecQuery() = %v, want %v", tt.name, got, tt.want)
+		}
+		if got.Code == abci.CodeType_OK && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ExecQuery() = %s, want %s", tt.name, got.Value, tt.want.Value)
+		}
+	}
+}
+
+func TestExecQuery_decimal(t *testing.T) {
+	s := NewState(bscoin.NewMemKVStore())
+	SetCurrencies(s, []*types.CurrencyEntry{
+		{Symbol: "EUR", DecimalPlaces: 2, MinimumUnit: 1},
+		{Symbol: "JPY", DecimalPlaces: 0, MinimumUnit: 1},
+	})
+	ch := testutil.RandCH()
+	s.SetLegalEntity(ch.ID, ch)
+	SetLegalEntityInIndex(s, ch)
+	acc := testutil.RandAccount(ch)
+	acc.Wallets = []types.Wallet{{Currency: "EUR", Balance: 10050}, {Currency: "JPY", Balance: 700}}
+	s.SetAccount(acc.ID, acc)
+	SetAccountInIndex(s, *acc)
+	AddAccountToSupply(s, acc)
+
+	want := []types.Balance{{Currency: "EUR", Amount: 10050, Display: "100.50"}, {Currency: "JPY", Amount: 700, Display: "700"}}
+
+	var accounts types.AccountsReturned
+	res := ExecQuery(s, Query{Resource: "account", Object: acc.ID, Params: url.Values{"decimal": {"true"}}})
+	if err := json.Unmarshal(res.Value, &accounts); err != nil || !reflect.DeepEqual(accounts.Balances[acc.ID], want) {
+		t.Errorf("account: got %s, want balances %v", res.Value, want)
+	}
+	res = ExecQuery(s, Query{Resource: "account", Object: acc.ID})
+	if err := json.Unmarshal(res.Value, &accounts); err != nil || accounts.Balances != nil {
+		t.Errorf("account without decimal: got %s, want no balances", res.Value)
+	}
+
+	var report types.BalanceReport
+	res = ExecQuery(s, Query{Resource: "legal_entity", Object: ch.ID, SubResource: "balances", Params: url.Values{"decimal": {"true"}}})
+	if err := json.Unmarshal(res.Value, &report); err != nil || !reflect.DeepEqual(report.Totals, want) {
+		t.Errorf("balances: got %s, want totals %v", res.Value, want)
+	}
+
+	var supply types.SupplyReport
+	res = ExecQuery(s, Query{Resource: "supply", Params: url.Values{"decimal": {"true"}}})
+	if err := json.Unmarshal(res.Value, &supply); err != nil || !reflect.DeepEqual(supply.Supply, want) {
+		t.Errorf("supply: got %s, want supply %v", res.Value, want)
+	}
+
+	res = ExecQuery(s, Query{Resource: "supply", Params: url.Values{"decimal": {"maybe"}}})
+	if res.Code != abci.CodeType_BaseInvalidInput {
+		t.Errorf("supply with invalid decimal: got code %v", res.Code)
+	}
+}
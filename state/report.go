@@ -0,0 +1,65 @@
+package state
+
+import (
+	"github.com/tendermint/clearchain/types"
+)
+
+// EntityBalances sums the wallets' balances of all the accounts
+// owned by a legal entity, by currency.
+func EntityBalances(state *State, entityID string) (types.Balances, int) {
+	balances := types.Balances{}
+	n := 0
+	state.EntityAccountIndex(entityID).Iterate(func(id string) bool {
+		if acc := state.GetAccount(id); acc != nil {
+			balances.Add(acc)
+			n++
+		}
+		return false
+	})
+	return balances, n
+}
+
+// BalanceReport aggregates the balances of a legal entity's accounts and,
+// if rollup is true, of the accounts of all its descendants too.
+// It returns nil if the legal entity does not exist.
+func BalanceReport(state *State, entityID string, rollup bool) *types.BalanceReport {
+	root := state.GetLegalEntity(entityID)
+	if root == nil {
+		return nil
+	}
+	report := &types.BalanceReport{EntityID: entityID, Rollup: rollup, Entities: []types.EntityBalances{}}
+	totals := types.Balances{}
+
+	// Breadth-first walk of the hierarchy; visited guards against cycles
+	visited := map[string]bool{root.ID: true}
+	queue := []*types.LegalEntity{root}
+	for len(queue) > 0 {
+		entity := queue[0]
+		queue = queue[1:]
+
+		balances, n := EntityBalances(state, entity.ID)
+		totals.Merge(balances)
+		report.Entities = append(report.Entities, types.EntityBalances{
+			EntityID: entity.ID,
+			Name:     entity.Name,
+			ParentID: entity.EntityID,
+			Accounts: n,
+			Balances: balances.ToSlice(),
+		})
+		if !rollup {
+			break
+		}
+		state.EntityChildIndex(entity.ID).Iterate(func(id string) bool {
+			if visited[id] {
+				return false
+			}
+			visited[id] = true
+			if child := state.GetLegalEntity(id); child != nil {
+				queue = append(queue, child)
+			}
+			return false
+		})
+	}
+	report.Totals = totals.ToSlice()
+	return report
+}
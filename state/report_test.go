@@ -0,0 +1,73 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+)
+
+func TestBalanceReport(t *testing.T) {
+	// Set up fixtures: ch -> gcm -> icm
+	s := NewState(bscoin.NewMemKVStore())
+	ch := testutil.RandCH()
+	gcm := testutil.RandGCM(nil)
+	gcm.EntityID = ch.ID
+	icm := testutil.RandICM(nil)
+	icm.EntityID = gcm.ID
+	for _, e := range []*types.LegalEntity{ch, gcm, icm} {
+		s.SetLegalEntity(e.ID, e)
+		SetLegalEntityInIndex(s, e)
+	}
+	wallets := map[string][]types.Wallet{
+		ch.ID:  {{Currency: "EUR", Balance: 100}},
+		gcm.ID: {{Currency: "EUR", Balance: 20}, {Currency: "USD", Balance: 5}},
+		icm.ID: {{Currency: "USD", Balance: 3}},
+	}
+	for _, e := range []*types.LegalEntity{ch, gcm, icm} {
+		for _, acc := range testutil.RandAccounts(2, e) {
+			acc.Wallets = wallets[e.ID]
+			s.SetAccount(acc.ID, acc)
+			SetAccountInIndex(s, *acc)
+		}
+	}
+
+	type args struct {
+		entityID string
+		rollup   bool
+	}
+	tests := []struct {
+		name         string
+		args         args
+		wantTotals   []types.Balance
+		wantEntities []string
+	}{
+		{"entityOnly", args{gcm.ID, false},
+			[]types.Balance{{Currency: "EUR", Amount: 40}, {Currency: "USD", Amount: 10}}, []string{gcm.ID}},
+		{"rollupFromRoot", args{ch.ID, true},
+			[]types.Balance{{Currency: "EUR", Amount: 240}, {Currency: "USD", Amount: 16}}, []string{ch.ID, gcm.ID, icm.ID}},
+		{"rollupFromLeaf", args{icm.ID, true},
+			[]types.Balance{{Currency: "USD", Amount: 6}}, []string{icm.ID}},
+	}
+	for _, tt := range tests {
+		got := BalanceReport(s, tt.args.entityID, tt.args.rollup)
+		if !reflect.DeepEqual(got.Totals, tt.wantTotals) {
+			t.Errorf("%q. BalanceReport().Totals = %v, want %v", tt.name, got.Totals, tt.wantTotals)
+		}
+		entities := make([]string, len(got.Entities))
+		for i, e := range got.Entities {
+			entities[i] = e.EntityID
+			if e.Accounts != 2 {
+				t.Errorf("%q. BalanceReport().Entities[%d].Accounts = %v, want 2", tt.name, i, e.Accounts)
+			}
+		}
+		if !reflect.DeepEqual(entities, tt.wantEntities) {
+			t.Errorf("%q. BalanceReport().Entities = %v, want %v", tt.name, entities, tt.wantEntities)
+		}
+	}
+	if got := BalanceReport(s, "nonexisting", true); got != nil {
+		t.Errorf("BalanceReport() = %v, want nil", got)
+	}
+}
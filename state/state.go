@@ -1,6 +1,8 @@
 package state
 
 import (
+	"encoding/binary"
+
 	basecoin "github.com/tendermint/basecoin/types"
 	"github.com/tendermint/clearchain/types"
 	common "github.com/tendermint/go-common"
@@ -10,6 +12,7 @@ import (
 // State defines the attributes of the system's state
 type State struct {
 	chainID string
+	height  uint64 // Height of the block being executed
 	store   basecoin.KVStore
 	cache   *basecoin.KVCache // optional
 }
@@ -35,6 +38,38 @@ func (s *State) GetChainID() string {
 	return s.chainID
 }
 
+// ChainID returns the State's chain ID, or "" if not set yet
+func (s *State) ChainID() string {
+	return s.chainID
+}
+
+// SetBlockHeight sets the height of the block being executed,
+// Txs are checked against it for expiry
+func (s *State) SetBlockHeight(height uint64) {
+	s.height = height
+}
+
+// BlockHeight returns the height of the block being executed,
+// or 0 if no block has begun yet
+func (s *State) BlockHeight() uint64 {
+	return s.height
+}
+
+// StoreBlockHeight stores the height of the block being executed, which is
+// committed along with the block, so that a restarted node knows the
+// height of its last block before the next one begins.
+func (s *State) StoreBlockHeight() {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], s.height)
+	s.store.Set(BlockHeightKey(), b[:])
+}
+
+// LoadBlockHeight sets the State's block height to the stored one,
+// that of the last committed block, 0 if none.
+func (s *State) LoadBlockHeight() {
+	s.height = GetBlockHeight(s.store)
+}
+
 // Get retrieves the value for the respective key from the State's store
 func (s *State) Get(key []byte) (value []byte) {
 	return s.store.Get(key)
@@ -75,36 +110,34 @@ func (s *State) SetLegalEntity(id string, l *types.LegalEntity) {
 	SetLegalEntity(s.store, id, l)
 }
 
-// GetAccountIndex gets the accounts index
-func (s *State) GetAccountIndex() *types.AccountIndex {
-	return GetAccountIndex(s.store)
+// AccountIndex returns the index of all accounts
+func (s *State) AccountIndex() *Index {
+	return AccountIndex(s.store)
 }
 
-// SetAccountIndex sets the accounts index
-func (s *State) SetAccountIndex(index *types.AccountIndex) {
-	accBytes := wire.BinaryBytes(index)
-	s.store.Set(AccountIndexKey(), accBytes)
+// LegalEntityIndex returns the index of all legal entities
+func (s *State) LegalEntityIndex() *Index {
+	return LegalEntityIndex(s.store)
 }
 
-//Gets existing LegalEntityIndex from store or nil if nonexistent. Can panic if store's data is corrupt.
-func (s *State) GetLegalEntityIndex() *types.LegalEntityIndex {
-	data := s.store.Get(legalEntityIndexKey())
-	if len(data) == 0 {
-		return nil
-	}
-	var LegalEntityIndex *types.LegalEntityIndex
-	err := wire.ReadBinaryBytes(data, &LegalEntityIndex)
-	if err != nil {
-		panic(common.Fmt("Error reading LegalEntityIndex %X error: %v",
-			data, err.Error()))
-	}
-	return LegalEntityIndex
+// EntityAccountIndex returns the index of the accounts owned by a legal entity
+func (s *State) EntityAccountIndex(entityID string) *Index {
+	return EntityAccountIndex(s.store, entityID)
+}
+
+// EntityChildIndex returns the index of a legal entity's children
+func (s *State) EntityChildIndex(entityID string) *Index {
+	return EntityChildIndex(s.store, entityID)
+}
+
+// UserIndex returns the index of all users
+func (s *State) UserIndex() *Index {
+	return UserIndex(s.store)
 }
 
-//Sets LegalEntityIndex in store
-func (s *State) SetLegalEntityIndex(LegalEntityIndex *types.LegalEntityIndex) {
-	LegalEntityIndexBytes := wire.BinaryBytes(LegalEntityIndex)
-	s.store.Set(legalEntityIndexKey(), LegalEntityIndexBytes)
+// EntityUserIndex returns the index of the users belonging to a legal entity
+func (s *State) EntityUserIndex(entityID string) *Index {
+	return EntityUserIndex(s.store, entityID)
 }
 
 //----------------------------------------
@@ -113,6 +146,7 @@ func (s *State) CacheWrap() *State {
 	cache := basecoin.NewKVCache(s.store)
 	return &State{
 		chainID: s.chainID,
+		height:  s.height,
 		store:   cache,
 		cache:   cache,
 	}
@@ -125,6 +159,23 @@ func (s *State) CacheSync() {
 
 //----------------------------------------
 
+// BlockHeightKey is the data store's key of the height of the last block
+func BlockHeightKey() []byte {
+	return []byte("base/height")
+}
+
+// GetBlockHeight retrieves the height of the last block from the given
+// store, 0 if none
+func GetBlockHeight(store basecoin.KVStore) uint64 {
+	data := store.Get(BlockHeightKey())
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+//----------------------------------------
+
 // AccountKey generates a data store's unique key for an Account
 func AccountKey(id string) []byte {
 	return append([]byte("base/a/"), id...)
@@ -209,26 +260,68 @@ func SetLegalEntity(store basecoin.KVStore, id string, ent *types.LegalEntity) {
 
 //----------------------------------------
 
-// AccountIndexKey generates a data store's unique key for an AccountIndex
-func AccountIndexKey() []byte {
-	return []byte("base/i/a")
+// AccountIndexKey generates the key prefix of the AccountIndex
+func AccountIndexKey() string {
+	return "base/i/a"
 }
 
-func legalEntityIndexKey() []byte {
-	return []byte("base/i/l")
+// LegalEntityIndexKey generates the key prefix of the LegalEntityIndex
+func LegalEntityIndexKey() string {
+	return "base/i/l"
 }
 
-// GetAccountIndex retrieves a AccountIndex from the given store
-func GetAccountIndex(store basecoin.KVStore) *types.AccountIndex {
-	data := store.Get(AccountIndexKey())
-	if len(data) == 0 {
-		return nil
-	}
-	var acc *types.AccountIndex
-	err := wire.ReadBinaryBytes(data, &acc)
-	if err != nil {
-		panic(common.Fmt("Error reading account index %X error: %v",
-			data, err.Error()))
-	}
-	return acc
+// EntityAccountIndexKey generates the key prefix of a legal entity's accounts index
+func EntityAccountIndexKey(entityID string) string {
+	return "base/i/ea/" + entityID
+}
+
+// EntityChildIndexKey generates the key prefix of a legal entity's children index
+func EntityChildIndexKey(entityID string) string {
+	return "base/i/ec/" + entityID
+}
+
+// UserIndexKey generates the key prefix of the UserIndex
+func UserIndexKey() string {
+	return "base/i/u"
+}
+
+// EntityUserIndexKey generates the key prefix of a legal entity's users index
+func EntityUserIndexKey(entityID string) string {
+	return "base/i/eu/" + entityID
+}
+
+// UserIndexID returns the representation of a user's address
+// stored in the users indexes
+func UserIndexID(addr []byte) string {
+	return common.Fmt("%X", addr)
+}
+
+// AccountIndex returns the index of all accounts in the given store
+func AccountIndex(store basecoin.KVStore) *Index {
+	return NewIndex(store, AccountIndexKey())
+}
+
+// LegalEntityIndex returns the index of all legal entities in the given store
+func LegalEntityIndex(store basecoin.KVStore) *Index {
+	return NewIndex(store, LegalEntityIndexKey())
+}
+
+// EntityAccountIndex returns the index of the accounts owned by a legal entity in the given store
+func EntityAccountIndex(store basecoin.KVStore, entityID string) *Index {
+	return NewIndex(store, EntityAccountIndexKey(entityID))
+}
+
+// UserIndex returns the index of all users in the given store
+func UserIndex(store basecoin.KVStore) *Index {
+	return NewIndex(store, UserIndexKey())
+}
+
+// EntityUserIndex returns the index of the users belonging to a legal entity in the given store
+func EntityUserIndex(store basecoin.KVStore, entityID string) *Index {
+	return NewIndex(store, EntityUserIndexKey(entityID))
+}
+
+// EntityChildIndex returns the index of a legal entity's children in the given store
+func EntityChildIndex(store basecoin.KVStore, entityID string) *Index {
+	return NewIndex(store, EntityChildIndexKey(entityID))
 }
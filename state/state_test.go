@@ -34,6 +34,23 @@ func TestSetChainID(t *testing.T) {
 	}
 }
 
+func TestLoadBlockHeight(t *testing.T) {
+	store := bscoin.NewMemKVStore()
+	s := NewState(store)
+	s.LoadBlockHeight()
+	if ret := s.BlockHeight(); ret != 0 {
+		t.Errorf("BlockHeight() return %v, expected 0", ret)
+	}
+	s.SetBlockHeight(7)
+	s.StoreBlockHeight()
+	// As a restarted node would
+	restarted := NewState(store)
+	restarted.LoadBlockHeight()
+	if ret := restarted.CacheWrap().BlockHeight(); ret != 7 {
+		t.Errorf("BlockHeight() return %v, expected 7", ret)
+	}
+}
+
 func TestGet(t *testing.T) {
 	s := NewState(bscoin.NewMemKVStore())
 	if ret := s.Get([]byte("key")); ret != nil {
@@ -0,0 +1,142 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	basecoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+// SupplyKey generates a data store's unique key for a currency's total supply
+func SupplyKey(currency string) []byte {
+	return append([]byte("base/s/"), currency...)
+}
+
+// SupplyIndexKey generates the key prefix of the index of currencies with a supply
+func SupplyIndexKey() string {
+	return "base/i/s"
+}
+
+// GetSupply retrieves a currency's total supply from the given store
+func GetSupply(store basecoin.KVStore, currency string) int64 {
+	data := store.Get(SupplyKey(currency))
+	if len(data) == 0 {
+		return 0
+	}
+	var amount int64
+	err := wire.ReadBinaryBytes(data, &amount)
+	if err != nil {
+		panic(common.Fmt("Error reading supply %X error: %v",
+			data, err.Error()))
+	}
+	return amount
+}
+
+// SetSupply stores a currency's total supply to the given store
+func SetSupply(store basecoin.KVStore, currency string, amount int64) {
+	store.Set(SupplyKey(currency), wire.BinaryBytes(amount))
+	NewIndex(store, SupplyIndexKey()).Add(currency)
+}
+
+// GetSupply retrieves the total supply of a currency
+func (s *State) GetSupply(currency string) int64 {
+	return GetSupply(s.store, currency)
+}
+
+// SetSupply sets the total supply of a currency
+func (s *State) SetSupply(currency string, amount int64) {
+	SetSupply(s.store, currency, amount)
+}
+
+// SupplyIndex returns the index of the currencies with a supply
+func (s *State) SupplyIndex() *Index {
+	return NewIndex(s.store, SupplyIndexKey())
+}
+
+// AddSupply adds amount, which may be negative, to a currency's total
+// supply. The supply is left untouched if it would overflow.
+func AddSupply(state *State, currency string, amount int64) error {
+	supply, err := types.Amount(state.GetSupply(currency)).Add(types.Amount(amount))
+	if err != nil {
+		return fmt.Errorf("Supply of %s would overflow: %v", currency, err)
+	}
+	state.SetSupply(currency, int64(supply))
+	return nil
+}
+
+// AddAccountToSupply adds the balances of an account's wallets to the total
+// supply. It accounts for money created outside of IssueTx, i.e. at genesis.
+func AddAccountToSupply(state *State, acc *types.Account) error {
+	for _, wal := range acc.Wallets {
+		if err := AddSupply(state, wal.Currency, wal.Balance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Supply returns the total supply of every currency.
+func Supply(state *State) types.Balances {
+	supply := types.Balances{}
+	state.SupplyIndex().Iterate(func(currency string) bool {
+		supply[currency] = state.GetSupply(currency)
+		return false
+	})
+	return supply
+}
+
+// TotalBalances sums the balances of every wallet on the ledger, by currency.
+func TotalBalances(state *State) types.Balances {
+	balances := types.Balances{}
+	state.AccountIndex().Iterate(func(id string) bool {
+		if acc := state.GetAccount(id); acc != nil {
+			balances.Add(acc)
+		}
+		return false
+	})
+	return balances
+}
+
+// SupplyReport checks that the money on the ledger is conserved, i.e.
+// that the sum of all wallet balances equals the tracked total supply
+// for every currency.
+func SupplyReport(state *State) *types.SupplyReport {
+	supply := Supply(state)
+	balances := TotalBalances(state)
+
+	currencies := map[string]bool{}
+	for currency := range supply {
+		currencies[currency] = true
+	}
+	for currency := range balances {
+		currencies[currency] = true
+	}
+	violations := []string{}
+	for currency := range currencies {
+		if supply[currency] != balances[currency] {
+			violations = append(violations, common.Fmt("%s: supply %d != balances %d",
+				currency, supply[currency], balances[currency]))
+		}
+	}
+	sort.Strings(violations)
+
+	return &types.SupplyReport{
+		Supply:     supply.ToSlice(),
+		Balances:   balances.ToSlice(),
+		Violations: violations,
+	}
+}
+
+// CheckInvariants returns an error describing any violation of
+// the conservation of money.
+func CheckInvariants(state *State) error {
+	report := SupplyReport(state)
+	if len(report.Violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("money is not conserved: %s", strings.Join(report.Violations, "; "))
+}
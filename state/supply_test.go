@@ -0,0 +1,93 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+)
+
+func TestExecTx_issueAndRedeem(t *testing.T) {
+	// Set up fixtures
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID(chainID)
+	SetCurrencies(s, types.ISOCurrencies())
+	ch := testutil.RandCH()
+	chUser := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0]
+	gcm := testutil.RandGCM(nil)
+	gcmUser := testutil.RandUsersWithLegalEntity(1, gcm, types.NewPermByTxType(types.TxTypeIssue, types.TxTypeRedeem))[0]
+	gcm.Permissions = gcm.Permissions.Add(gcmUser.User.Permissions)
+	account := testutil.RandAccount(gcm)
+	account.Wallets = nil
+	for _, e := range []*types.LegalEntity{ch, gcm} {
+		s.SetLegalEntity(e.ID, e)
+		SetLegalEntityInIndex(s, e)
+	}
+	for _, u := range []*types.PrivUser{chUser, gcmUser} {
+		s.SetUser(u.User.PubKey.Address(), &u.User)
+	}
+	s.SetAccount(account.ID, account)
+	SetAccountInIndex(s, *account)
+
+	issueTx := func(u *types.PrivUser, amount int64, nonce uint64) *types.IssueTx {
+		tx := &types.IssueTx{Address: u.User.PubKey.Address(), Nonce: nonce, AccountID: account.ID, Amount: amount, Currency: "EUR"}
+		tx.SignTx(u.PrivKey, chainID)
+		return tx
+	}
+	redeemTx := func(u *types.PrivUser, amount int64, nonce uint64) *types.RedeemTx {
+		tx := &types.RedeemTx{Address: u.User.PubKey.Address(), Nonce: nonce, AccountID: account.ID, Amount: amount, Currency: "EUR"}
+		tx.SignTx(u.PrivKey, chainID)
+		return tx
+	}
+
+	tests := []struct {
+		name       string
+		tx         types.Tx
+		want       abci.CodeType
+		wantSupply int64
+	}{
+		{"issue", issueTx(chUser, 1000, 1), abci.CodeType_OK, 1000},
+		{"issueByNonCH", issueTx(gcmUser, 1000, 1), abci.CodeType_Unauthorized, 1000},
+		{"issueReplayedNonce", issueTx(chUser, 1000, 1), abci.CodeType_BaseInvalidSequence, 1000},
+		{"redeem", redeemTx(chUser, 400, 2), abci.CodeType_OK, 600},
+		{"redeemByNonCH", redeemTx(gcmUser, 100, 1), abci.CodeType_Unauthorized, 600},
+		{"redeemInsufficientFunds", redeemTx(chUser, 700, 3), abci.CodeType_BaseInsufficientFunds, 600},
+	}
+	for _, tt := range tests {
+		if got := ExecTx(s, nil, tt.tx, false, nil); got.Code != tt.want {
+			t.Errorf("%q. ExecTx() = %v, want %v", tt.name, got, tt.want)
+		}
+		if got := s.GetSupply("EUR"); got != tt.wantSupply {
+			t.Errorf("%q. State.GetSupply() = %v, want %v", tt.name, got, tt.wantSupply)
+		}
+		if err := CheckInvariants(s); err != nil {
+			t.Errorf("%q. CheckInvariants() error = %v", tt.name, err)
+		}
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	s := NewState(bscoin.NewMemKVStore())
+	acc := testutil.RandAccount(testutil.RandCH())
+	acc.Wallets = []types.Wallet{{Currency: "EUR", Balance: 100}, {Currency: "USD", Balance: 5}}
+	s.SetAccount(acc.ID, acc)
+	SetAccountInIndex(s, *acc)
+	if err := CheckInvariants(s); err == nil {
+		t.Errorf("CheckInvariants() expected to fail when money is not accounted for in the supply")
+	}
+	AddAccountToSupply(s, acc)
+	if err := CheckInvariants(s); err != nil {
+		t.Errorf("CheckInvariants() error = %v", err)
+	}
+	// Money created out of thin air
+	acc.Wallets[0].Balance += 1
+	s.SetAccount(acc.ID, acc)
+	report := SupplyReport(s)
+	if want := []string{"EUR: supply 100 != balances 101"}; !reflect.DeepEqual(report.Violations, want) {
+		t.Errorf("SupplyReport().Violations = %v, want %v", report.Violations, want)
+	}
+}
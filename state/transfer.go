@@ -0,0 +1,71 @@
+package state
+
+import (
+	"encoding/hex"
+
+	basecoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+// TransferKey generates a data store's unique key for a transfer of the history
+func TransferKey(id string) []byte {
+	return append([]byte("base/t/"), id...)
+}
+
+// TransferIndexKey generates the key prefix of the transfer history's index
+func TransferIndexKey() string {
+	return "base/i/t"
+}
+
+// TransferReferenceIndexKey generates the key prefix of the index of the
+// transfers carrying a reference. The reference is hex encoded as it may
+// contain slashes, which would clash with the index's own keys.
+func TransferReferenceIndexKey(reference string) string {
+	return "base/i/tr/" + hex.EncodeToString([]byte(reference))
+}
+
+// GetTransfer retrieves a transfer of the history from the given store
+func GetTransfer(store basecoin.KVStore, id string) *types.Transfer {
+	data := store.Get(TransferKey(id))
+	if len(data) == 0 {
+		return nil
+	}
+	var transfer *types.Transfer
+	err := wire.ReadBinaryBytes(data, &transfer)
+	if err != nil {
+		panic(common.Fmt("Error reading transfer %X error: %v",
+			data, err.Error()))
+	}
+	return transfer
+}
+
+// SetTransfer appends a transfer to the history kept in the given store
+func SetTransfer(store basecoin.KVStore, transfer *types.Transfer) {
+	store.Set(TransferKey(transfer.ID), wire.BinaryBytes(transfer))
+	NewIndex(store, TransferIndexKey()).Add(transfer.ID)
+	if len(transfer.Reference) > 0 {
+		NewIndex(store, TransferReferenceIndexKey(transfer.Reference)).Add(transfer.ID)
+	}
+}
+
+// GetTransfer retrieves a transfer of the history
+func (s *State) GetTransfer(id string) *types.Transfer {
+	return GetTransfer(s.store, id)
+}
+
+// SetTransfer appends a transfer to the history
+func (s *State) SetTransfer(transfer *types.Transfer) {
+	SetTransfer(s.store, transfer)
+}
+
+// TransferIndex returns the index of the transfer history, oldest first
+func (s *State) TransferIndex() *Index {
+	return NewIndex(s.store, TransferIndexKey())
+}
+
+// TransferReferenceIndex returns the index of the transfers carrying a reference
+func (s *State) TransferReferenceIndex(reference string) *Index {
+	return NewIndex(s.store, TransferReferenceIndexKey(reference))
+}
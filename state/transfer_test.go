@@ -0,0 +1,123 @@
+package state
+
+import (
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+)
+
+func TestExecTx_transferHistory(t *testing.T) {
+	// Set up fixtures
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID(chainID)
+	s.SetBlockHeight(7)
+	SetCurrencies(s, types.ISOCurrencies())
+	ch := testutil.RandCH()
+	user := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0]
+	s.SetLegalEntity(ch.ID, ch)
+	s.SetUser(user.User.PubKey.Address(), &user.User)
+	accounts := testutil.RandAccounts(2, ch)
+	for _, acc := range accounts {
+		acc.Wallets = nil
+		s.SetAccount(acc.ID, acc)
+	}
+	transferTx := func(nonce uint64, reference string) *types.TransferTx {
+		tx := &types.TransferTx{
+			Committer: types.TxTransferCommitter{Address: user.User.PubKey.Address(), Nonce: nonce},
+			Sender:    types.TxTransferSender{AccountID: accounts[0].ID, Amount: 100, Currency: "EUR"},
+			Recipient: types.TxTransferRecipient{AccountID: accounts[1].ID},
+			Reference: reference,
+			Memo:      "Settlement",
+		}
+		tx.SignTx(user.PrivKey, chainID)
+		return tx
+	}
+
+	tx1, tx2, tx3 := transferTx(1, "trade-1"), transferTx(2, ""), transferTx(3, "trade-1")
+	if res := ExecTx(s.CacheWrap(), nil, tx1, true, nil); res.IsErr() {
+		t.Fatalf("CheckTx: ExecTx() = %v", res)
+	}
+	if got := s.TransferIndex().Len(); got != 0 {
+		t.Errorf("CheckTx: TransferIndex().Len() = %v, want 0", got)
+	}
+	for _, tx := range []*types.TransferTx{tx1, tx2, tx3} {
+		if res := ExecTx(s, nil, tx, false, nil); res.IsErr() {
+			t.Fatalf("ExecTx(%v) = %v", tx, res)
+		}
+	}
+
+	want := types.NewTransfer(tx1, 7)
+	if got := s.GetTransfer(want.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTransfer(%q) = %v, want %v", want.ID, got, want)
+	}
+	if got := s.TransferIndex().Len(); got != 3 {
+		t.Errorf("TransferIndex().Len() = %v, want 3", got)
+	}
+
+	byReference, _ := json.Marshal(types.TransfersReturned{Transfers: []*types.Transfer{want, types.NewTransfer(tx3, 7)}})
+	firstPage, _ := json.Marshal(types.TransfersReturned{Transfers: []*types.Transfer{want}, Next: "1"})
+	byID, _ := json.Marshal(types.TransfersReturned{Transfers: []*types.Transfer{want}})
+	noTransfers, _ := json.Marshal(types.TransfersReturned{Transfers: []*types.Transfer{}})
+	tests := []struct {
+		name  string
+		query Query
+		want  abci.ResponseQuery
+	}{
+		{"byReference", Query{Resource: "transfers", Params: url.Values{"reference": {"trade-1"}}}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: byReference}},
+		{"firstPage", Query{Resource: "transfers", Params: url.Values{"limit": {"1"}}}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: firstPage}},
+		{"unknownReference", Query{Resource: "transfers", Params: url.Values{"reference": {"trade-2"}}}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: noTransfers}},
+		{"byID", Query{Resource: "transfer", Object: want.ID}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: byID}},
+		{"unknownID", Query{Resource: "transfer", Object: "xx"}, abci.ResponseQuery{Code: abci.CodeType_BaseUnknownAddress}},
+	}
+	for _, tt := range tests {
+		got := ExecQuery(s, tt.query)
+		if got.Code != tt.want.Code {
+			t.Errorf("%q. ExecQuery() = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		if got.Code == abci.CodeType_OK && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ExecQuery() = %s, want %s", tt.name, got.Value, tt.want.Value)
+		}
+	}
+}
+
+func TestExecTx_transferToSameAccount(t *testing.T) {
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID(chainID)
+	SetCurrencies(s, types.ISOCurrencies())
+	ch := testutil.RandCH()
+	user := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0]
+	s.SetLegalEntity(ch.ID, ch)
+	s.SetUser(user.User.PubKey.Address(), &user.User)
+	acc := testutil.RandAccount(ch)
+	acc.Wallets = []types.Wallet{{Currency: "EUR", Balance: 100}}
+	s.SetAccount(acc.ID, acc)
+	SetAccountInIndex(s, *acc)
+	AddAccountToSupply(s, acc)
+
+	tx := &types.TransferTx{
+		Committer: types.TxTransferCommitter{Address: user.User.PubKey.Address(), Nonce: 1},
+		Sender:    types.TxTransferSender{AccountID: acc.ID, Amount: 40, Currency: "EUR"},
+		Recipient: types.TxTransferRecipient{AccountID: acc.ID},
+	}
+	tx.SignTx(user.PrivKey, chainID)
+	for _, isCheckTx := range []bool{true, false} {
+		if got := ExecTx(s, nil, tx, isCheckTx, nil); got.Code != abci.CodeType_BaseInvalidOutput {
+			t.Errorf("ExecTx(isCheckTx: %v) = %v, want %v", isCheckTx, got, abci.CodeType_BaseInvalidOutput)
+		}
+	}
+	if got := s.GetAccount(acc.ID).Wallets; !reflect.DeepEqual(got, acc.Wallets) {
+		t.Errorf("GetAccount().Wallets = %v, want %v", got, acc.Wallets)
+	}
+	if err := CheckInvariants(s); err != nil {
+		t.Errorf("CheckInvariants() error = %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	abci "github.com/tendermint/abci/types"
+	basecoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+// TxRecordKey generates a data store's unique key for the record of a
+// delivered Tx
+func TxRecordKey(hash []byte) []byte {
+	return append([]byte("base/h/"), hash...)
+}
+
+// TxRecordIndexKey generates the key prefix of the index of Tx records,
+// whose entries are the Txs' hex encoded hashes
+func TxRecordIndexKey() string {
+	return "base/i/h"
+}
+
+// GetTxRecord retrieves the record of a delivered Tx from the given store
+func GetTxRecord(store basecoin.KVStore, hash []byte) *types.TxRecord {
+	data := store.Get(TxRecordKey(hash))
+	if len(data) == 0 {
+		return nil
+	}
+	var record *types.TxRecord
+	err := wire.ReadBinaryBytes(data, &record)
+	if err != nil {
+		panic(common.Fmt("Error reading tx record %X error: %v",
+			data, err.Error()))
+	}
+	return record
+}
+
+// SetTxRecord records a delivered Tx in the given store
+func SetTxRecord(store basecoin.KVStore, record *types.TxRecord) {
+	store.Set(TxRecordKey(record.Hash), wire.BinaryBytes(record))
+	NewIndex(store, TxRecordIndexKey()).Add(common.Fmt("%X", record.Hash))
+}
+
+// GetTxRecord retrieves the record of a delivered Tx
+func (s *State) GetTxRecord(hash []byte) *types.TxRecord {
+	return GetTxRecord(s.store, hash)
+}
+
+// SetTxRecord records a delivered Tx
+func (s *State) SetTxRecord(record *types.TxRecord) {
+	SetTxRecord(s.store, record)
+}
+
+// TxRecordIndex returns the index of Tx records, oldest first
+func (s *State) TxRecordIndex() *Index {
+	return NewIndex(s.store, TxRecordIndexKey())
+}
+
+// RecordTx records the result of a Tx delivered at the current height,
+// unless the same bytes were already delivered.
+func RecordTx(state *State, txBytes []byte, tx types.Tx, res abci.Result) {
+	ntx, ok := tx.(types.NoncedTx)
+	if !ok || state.GetTxRecord(types.TxHash(txBytes)) != nil {
+		return
+	}
+	state.SetTxRecord(types.NewTxRecord(txBytes, ntx, state.BlockHeight(), res))
+}
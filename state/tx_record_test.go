@@ -0,0 +1,83 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+	"github.com/tendermint/go-wire"
+)
+
+func TestRecordTx(t *testing.T) {
+	// Set up fixtures
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID(chainID)
+	s.SetBlockHeight(7)
+	SetCurrencies(s, types.ISOCurrencies())
+	ch := testutil.RandCH()
+	user := testutil.RandUsersWithLegalEntity(1, ch, ch.Permissions)[0]
+	s.SetLegalEntity(ch.ID, ch)
+	s.SetUser(user.User.PubKey.Address(), &user.User)
+	account := testutil.RandAccount(ch)
+	s.SetAccount(account.ID, account)
+	deliverTx := func(tx types.SignedTx) (*types.TxRecord, abci.Result) {
+		tx.SignTx(user.PrivKey, chainID)
+		txBytes := wire.BinaryBytes(struct{ types.Tx }{tx})
+		res := ExecTx(s, nil, tx, false, nil)
+		RecordTx(s, txBytes, tx, res)
+		return s.GetTxRecord(types.TxHash(txBytes)), res
+	}
+
+	issueTx := &types.IssueTx{Address: user.User.PubKey.Address(), Nonce: 1, AccountID: account.ID, Amount: 100, Currency: "EUR"}
+	issued, res := deliverTx(issueTx)
+	if res.IsErr() {
+		t.Fatalf("ExecTx() = %v", res)
+	}
+	if issued == nil || issued.Height != 7 || issued.Type != types.TxTypeIssue || issued.Code != abci.CodeType_OK ||
+		!reflect.DeepEqual(issued.Signer, user.User.PubKey.Address()) {
+		t.Fatalf("GetTxRecord() = %v, want the record of %v", issued, issueTx)
+	}
+	if tx, err := issued.DecodeTx(); err != nil || !reflect.DeepEqual(tx, issueTx) {
+		t.Errorf("TxRecord.DecodeTx() = %v, %v, want %v", tx, err, issueTx)
+	}
+
+	// A failed Tx is recorded with its result, a replayed one is not recorded twice
+	failed, res := deliverTx(&types.IssueTx{Address: user.User.PubKey.Address(), Nonce: 2, AccountID: account.ID, Amount: 100, Currency: "XYZ"})
+	if failed == nil || failed.Code != res.Code || failed.Log != res.Log || res.IsOK() {
+		t.Errorf("GetTxRecord() = %v, want the record of a failed tx: %v", failed, res)
+	}
+	if replayed, _ := deliverTx(issueTx); !reflect.DeepEqual(replayed, issued) {
+		t.Errorf("GetTxRecord() = %v, want %v", replayed, issued)
+	}
+	if got := s.TxRecordIndex().Len(); got != 2 {
+		t.Errorf("TxRecordIndex().Len() = %v, want 2", got)
+	}
+
+	byHash, _ := json.Marshal(issued)
+	tests := []struct {
+		name  string
+		query Query
+		want  abci.ResponseQuery
+	}{
+		{"byHash", Query{Resource: "tx", Object: fmt.Sprintf("%X", issued.Hash)}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: byHash}},
+		{"byLowerCaseHash", Query{Resource: "tx", Object: fmt.Sprintf("%x", issued.Hash)}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: byHash}},
+		{"unknownHash", Query{Resource: "tx", Object: "0123456789ABCDEF0123456789ABCDEF01234567"}, abci.ResponseQuery{Code: abci.CodeType_BaseUnknownAddress}},
+		{"invalidHash", Query{Resource: "tx", Object: "xyz"}, abci.ResponseQuery{Code: abci.CodeType_BaseInvalidInput}},
+	}
+	for _, tt := range tests {
+		got := ExecQuery(s, tt.query)
+		if got.Code != tt.want.Code {
+			t.Errorf("%q. ExecQuery() = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		if got.Code == abci.CodeType_OK && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ExecQuery() = %s, want %s", tt.name, got.Value, tt.want.Value)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package state
+
+import (
+	basecoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/types"
+	common "github.com/tendermint/go-common"
+	"github.com/tendermint/go-wire"
+)
+
+// TxResultKey generates a data store's unique key for the result of the Tx
+// carrying a ClientID
+func TxResultKey(clientID string) []byte {
+	return append([]byte("base/x/"), clientID...)
+}
+
+// TxResultIndexKey generates the key prefix of the index of Tx results
+func TxResultIndexKey() string {
+	return "base/i/x"
+}
+
+// GetTxResult retrieves the result of the Tx carrying a ClientID from the given store
+func GetTxResult(store basecoin.KVStore, clientID string) *types.TxResult {
+	data := store.Get(TxResultKey(clientID))
+	if len(data) == 0 {
+		return nil
+	}
+	var result *types.TxResult
+	err := wire.ReadBinaryBytes(data, &result)
+	if err != nil {
+		panic(common.Fmt("Error reading tx result %X error: %v",
+			data, err.Error()))
+	}
+	return result
+}
+
+// SetTxResult records the result of a Tx in the given store
+func SetTxResult(store basecoin.KVStore, result *types.TxResult) {
+	store.Set(TxResultKey(result.ClientID), wire.BinaryBytes(result))
+	NewIndex(store, TxResultIndexKey()).Add(result.ClientID)
+}
+
+// GetTxResult retrieves the result of the Tx carrying a ClientID
+func (s *State) GetTxResult(clientID string) *types.TxResult {
+	return GetTxResult(s.store, clientID)
+}
+
+// SetTxResult records the result of a Tx
+func (s *State) SetTxResult(result *types.TxResult) {
+	SetTxResult(s.store, result)
+}
+
+// TxResultIndex returns the index of Tx results, oldest first
+func (s *State) TxResultIndex() *Index {
+	return NewIndex(s.store, TxResultIndexKey())
+}
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	abci "github.com/tendermint/abci/types"
+	bscoin "github.com/tendermint/basecoin/types"
+	"github.com/tendermint/clearchain/testutil"
+	"github.com/tendermint/clearchain/types"
+)
+
+func TestExecTx_duplicateTx(t *testing.T) {
+	// Set up fixtures
+	chainID := "chain"
+	s := NewState(bscoin.NewMemKVStore())
+	s.SetChainID(chainID)
+	s.SetBlockHeight(7)
+	SetCurrencies(s, types.ISOCurrencies())
+	ch := testutil.RandCH()
+	users := testutil.RandUsersWithLegalEntity(2, ch, ch.Permissions)
+	s.SetLegalEntity(ch.ID, ch)
+	for _, user := range users {
+		s.SetUser(user.User.PubKey.Address(), &user.User)
+	}
+	accounts := testutil.RandAccounts(2, ch)
+	for _, acc := range accounts {
+		acc.Wallets = nil
+		s.SetAccount(acc.ID, acc)
+	}
+	transferTx := func(user *types.PrivUser, nonce uint64, clientID string, senderID string) *types.TransferTx {
+		tx := &types.TransferTx{
+			Committer: types.TxTransferCommitter{Address: user.User.PubKey.Address(), Nonce: nonce, ClientID: clientID},
+			Sender:    types.TxTransferSender{AccountID: senderID, Amount: 100, Currency: "EUR"},
+			Recipient: types.TxTransferRecipient{AccountID: accounts[1].ID},
+		}
+		tx.SignTx(user.PrivKey, chainID)
+		return tx
+	}
+	clientID, failedID := uuid.NewV4().String(), uuid.NewV4().String()
+	tx := transferTx(users[0], 1, clientID, accounts[0].ID)
+	if res := ExecTx(s, nil, tx, false, nil); res.IsErr() {
+		t.Fatalf("ExecTx() = %v", res)
+	}
+	want := &types.TxResult{ClientID: clientID, Signer: users[0].User.PubKey.Address(), Nonce: 1, Height: 7, Code: abci.CodeType_OK}
+	if got := s.GetTxResult(clientID); !reflect.DeepEqual(got, want) {
+		t.Fatalf("GetTxResult(%q) = %v, want %v", clientID, got, want)
+	}
+	// A failed Tx is not recorded, its ClientID can be used again
+	if res := ExecTx(s, nil, transferTx(users[0], 2, failedID, "unknown"), false, nil); res.IsOK() {
+		t.Fatalf("ExecTx() = %v, want an error", res)
+	}
+	if got := s.GetTxResult(failedID); got != nil {
+		t.Errorf("GetTxResult(%q) = %v, want nil", failedID, got)
+	}
+
+	original, _ := json.Marshal(want)
+	tests := []struct {
+		name      string
+		tx        *types.TransferTx
+		isCheckTx bool
+		wantCode  abci.CodeType
+		wantData  []byte
+	}{
+		{"checkTxResubmitted", tx, true, types.CodeTypeDuplicateTx, original},
+		{"deliverTxResubmitted", tx, false, types.CodeTypeDuplicateTx, original},
+		{"resignedWithNextNonce", transferTx(users[0], 2, clientID, accounts[0].ID), false, types.CodeTypeDuplicateTx, original},
+		{"usedByAnotherUser", transferTx(users[1], 1, clientID, accounts[0].ID), false, abci.CodeType_BaseInvalidInput, nil},
+		{"failedResubmitted", transferTx(users[0], 2, failedID, accounts[0].ID), false, abci.CodeType_OK, nil},
+		{"noClientID", transferTx(users[0], 3, "", accounts[0].ID), false, abci.CodeType_OK, nil},
+	}
+	for _, tt := range tests {
+		got := ExecTx(s, nil, tt.tx, tt.isCheckTx, nil)
+		if got.Code != tt.wantCode || !reflect.DeepEqual(got.Data, tt.wantData) {
+			t.Errorf("%q. ExecTx() = %v, want code %v and data %s", tt.name, got, tt.wantCode, tt.wantData)
+		}
+	}
+	if got := s.TxResultIndex().Len(); got != 2 {
+		t.Errorf("TxResultIndex().Len() = %v, want 2", got)
+	}
+	if got := s.GetUser(users[0].User.PubKey.Address()).Nonce; got != 3 {
+		t.Errorf("GetUser().Nonce = %v, want 3", got)
+	}
+
+	queries := []struct {
+		name  string
+		query Query
+		want  abci.ResponseQuery
+	}{
+		{"known", Query{Resource: "tx", Object: clientID}, abci.ResponseQuery{Code: abci.CodeType_OK, Value: original}},
+		{"unknown", Query{Resource: "tx", Object: uuid.NewV4().String()}, abci.ResponseQuery{Code: abci.CodeType_BaseUnknownAddress}},
+	}
+	for _, tt := range queries {
+		got := ExecQuery(s, tt.query)
+		if got.Code != tt.want.Code {
+			t.Errorf("%q. ExecQuery() = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		if got.Code == abci.CodeType_OK && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q. ExecQuery() = %s, want %s", tt.name, got.Value, tt.want.Value)
+		}
+	}
+}
@@ -83,7 +83,7 @@ func (account *Account) SetWallet(wallet Wallet) {
 type Wallet struct {
 	Currency string `json:"currency"`
 	Balance  int64  `json:"balance"`
-	Sequence int    `json:"sequence"`
+	Sequence int    `json:"sequence"` // Number of movements of the wallet
 }
 
 // Equal provides an equality operator
@@ -103,7 +103,9 @@ func (w *Wallet) String() string {
 
 // AccountsReturned defines the attributes of response's payload
 type AccountsReturned struct {
-	Account []*Account `json:"accounts"`
+	Account  []*Account           `json:"accounts"`
+	Balances map[string][]Balance `json:"balances,omitempty"` // Wallets' balances by account ID, when decimal is set
+	Next     string               `json:"next,omitempty"`     // Cursor of the next page, empty on the last one
 }
 
 //-----------------------------------------
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"bytes"
+
+	abci "github.com/tendermint/abci/types"
+	common "github.com/tendermint/go-common"
+	crypto "github.com/tendermint/go-crypto"
+	wire "github.com/tendermint/go-wire"
+)
+
+const (
+	// TxTypeAddCurrency defines AddCurrencyTx's code
+	TxTypeAddCurrency = byte(0x07)
+)
+
+// AddCurrencyTx defines the attributes of a currency registry's
+// addition, by which the clearing house supports a new currency
+// or instrument.
+type AddCurrencyTx struct {
+	Address          []byte           `json:"address"`                      // Hash of the user's PubKey
+	Nonce            uint64           `json:"nonce"`                        // Must be 1 greater than the user's last nonce
+	ValidUntilHeight uint64           `json:"valid_until_height,omitempty"` // Last height the Tx can be executed at, 0 if it never expires
+	ClientID         string           `json:"client_id,omitempty"`          // Client-generated UUID that makes the Tx idempotent, see CodeTypeDuplicateTx
+	Currency         CurrencyEntry    `json:"currency"`
+	Signature        crypto.Signature `json:"signature"`
+}
+
+// SignTx signs the transaction if its address and the privateKey's one match.
+func (tx *AddCurrencyTx) SignTx(privateKey crypto.PrivKey, chainID string) error {
+	sig, err := SignTx(tx.SignBytes(chainID), tx.Address, privateKey)
+	if err != nil {
+		return err
+	}
+	tx.Signature = sig
+	return nil
+}
+
+// SetSignature sets the Tx's signature if addr is the signer's address
+func (tx *AddCurrencyTx) SetSignature(addr []byte, sig crypto.Signature) bool {
+	if !bytes.Equal(tx.Address, addr) {
+		return false
+	}
+	tx.Signature = sig
+	return true
+}
+
+// TxType returns the byte type of AddCurrencyTx
+func (tx *AddCurrencyTx) TxType() byte {
+	return TxTypeAddCurrency
+}
+
+// Signer returns the address of the user who signs the Tx
+func (tx *AddCurrencyTx) Signer() []byte {
+	return tx.Address
+}
+
+// GetNonce returns the nonce of the user who signs the Tx
+func (tx *AddCurrencyTx) GetNonce() uint64 {
+	return tx.Nonce
+}
+
+// GetValidUntilHeight returns the last height the Tx can be executed at
+func (tx *AddCurrencyTx) GetValidUntilHeight() uint64 {
+	return tx.ValidUntilHeight
+}
+
+// GetClientID returns the client-generated ID of the Tx, if any
+func (tx *AddCurrencyTx) GetClientID() string {
+	return tx.ClientID
+}
+
+// SignBytes generates a byte-to-byte signature
+func (tx *AddCurrencyTx) SignBytes(chainID string) []byte {
+	signBytes := wire.BinaryBytes(chainID)
+	sig := tx.Signature
+	tx.Signature = nil
+	signBytes = append(signBytes, wire.BinaryBytes(tx)...)
+	tx.Signature = sig
+	return signBytes
+}
+
+// ValidateBasic performs basic validation on the Tx.
+func (tx *AddCurrencyTx) ValidateBasic() abci.Result {
+	if len(tx.Address) != 20 {
+		return abci.ErrBaseInvalidInput.AppendLog("Invalid address length")
+	}
+	if tx.Signature == nil {
+		return abci.ErrBaseInvalidSignature.AppendLog("The transaction must be signed")
+	}
+	if tx.Nonce == 0 {
+		return abci.ErrBaseInvalidSequence.AppendLog("Nonce must be greater than 0")
+	}
+	if res := validateClientID(tx.ClientID); res.IsErr() {
+		return res
+	}
+	if res := tx.Currency.ValidateBasic(); res.IsErr() {
+		return res
+	}
+	if tx.Currency.Retired {
+		return abci.ErrBaseInvalidInput.AppendLog("A new currency can't be retired")
+	}
+	return abci.OK
+}
+
+func (tx *AddCurrencyTx) String() string {
+	return common.Fmt("AddCurrencyTx{%x,%v}", tx.Address, &tx.Currency)
+}
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+func TestAddCurrencyTx_ValidateBasic(t *testing.T) {
+	addr := crypto.CRandBytes(20)
+	sig := crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))
+	currency := CurrencyEntry{Symbol: "XAU-G", Name: "Gold gram", DecimalPlaces: 3, MinimumUnit: 1}
+	retired := currency
+	retired.Retired = true
+	tests := []struct {
+		name string
+		tx   AddCurrencyTx
+		want abci.Result
+	}{
+		{"emptyTx", AddCurrencyTx{}, abci.ErrBaseInvalidInput},
+		{"invalidSignature", AddCurrencyTx{addr, 1, 0, "", currency, nil}, abci.ErrBaseInvalidSignature},
+		{"invalidCurrency", AddCurrencyTx{addr, 1, 0, "", CurrencyEntry{Symbol: "gold"}, sig}, abci.ErrBaseInvalidInput},
+		{"retiredCurrency", AddCurrencyTx{addr, 1, 0, "", retired, sig}, abci.ErrBaseInvalidInput},
+		{"invalidNonce", AddCurrencyTx{addr, 0, 0, "", currency, sig}, abci.ErrBaseInvalidSequence},
+		{"invalidClientID", AddCurrencyTx{addr, 1, 0, "client-id", currency, sig}, abci.ErrBaseInvalidInput},
+		{"valid", AddCurrencyTx{addr, 1, 0, "", currency, sig}, abci.OK},
+	}
+	for _, tt := range tests {
+		if got := tt.tx.ValidateBasic(); got.Code != tt.want.Code {
+			t.Errorf("%q. AddCurrencyTx.ValidateBasic() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
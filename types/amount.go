@@ -0,0 +1,141 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Amount is a quantity of a currency expressed in its minor units,
+// e.g. 10000 is 100.00 EUR and 100 JPY.
+type Amount int64
+
+// ParseAmount parses a decimal string such as "100.00" or "-0.5" into an
+// Amount of a currency with the given number of decimal places. The string
+// may not have more decimals than the currency, exponents, thousands
+// separators or a leading plus sign.
+func ParseAmount(s string, decimalPlaces uint) (Amount, error) {
+	if decimalPlaces > MaxDecimalPlaces {
+		return 0, fmt.Errorf("Too many decimal places: %d", decimalPlaces)
+	}
+	digits := s
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+	integer, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		integer, fraction = digits[:i], digits[i+1:]
+		if len(fraction) == 0 {
+			return 0, fmt.Errorf("Invalid amount: %q", s)
+		}
+	}
+	if len(integer) == 0 || !isDigits(integer) || !isDigits(fraction) {
+		return 0, fmt.Errorf("Invalid amount: %q", s)
+	}
+	if uint(len(fraction)) > decimalPlaces {
+		return 0, fmt.Errorf("Amount %q has more than %d decimal places", s, decimalPlaces)
+	}
+	fraction += strings.Repeat("0", int(decimalPlaces)-len(fraction))
+
+	// Accumulate negatively so that math.MinInt64 can be parsed too
+	var a int64
+	for _, c := range integer + fraction {
+		d := int64(c - '0')
+		if a < (math.MinInt64+d)/10 {
+			return 0, fmt.Errorf("Amount out of range: %q", s)
+		}
+		a = a*10 - d
+	}
+	if !negative {
+		if a == math.MinInt64 {
+			return 0, fmt.Errorf("Amount out of range: %q", s)
+		}
+		a = -a
+	}
+	return Amount(a), nil
+}
+
+// Parse parses a decimal string into an Amount of the given ISO 4217
+// currency, e.g. Parse("100.00", "EUR") is 10000. Use the ParseAmount
+// method of the registry's CurrencyEntry for any other instrument.
+func Parse(s string, symbol string) (Amount, error) {
+	currency, ok := Currencies[symbol]
+	if !ok {
+		return 0, fmt.Errorf("Unknown ISO 4217 currency: %q", symbol)
+	}
+	return ParseAmount(s, currency.DecimalPlaces())
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Format renders the Amount as a decimal string with the given number of
+// decimal places, e.g. Amount(10000).Format(2) is "100.00".
+func (a Amount) Format(decimalPlaces uint) string {
+	// Work with the absolute value as a uint64 so that math.MinInt64 is handled
+	sign, abs := "", uint64(a)
+	if a < 0 {
+		sign, abs = "-", uint64(-a)
+	}
+	digits := fmt.Sprintf("%0*d", int(decimalPlaces)+1, abs)
+	if decimalPlaces == 0 {
+		return sign + digits
+	}
+	i := len(digits) - int(decimalPlaces)
+	return sign + digits[:i] + "." + digits[i:]
+}
+
+// Add returns a+b, or an error if the sum overflows.
+func (a Amount) Add(b Amount) (Amount, error) {
+	c := a + b
+	if (b > 0 && c < a) || (b < 0 && c > a) {
+		return 0, fmt.Errorf("Amount overflow: %d + %d", a, b)
+	}
+	return c, nil
+}
+
+// Sub returns a-b, or an error if the difference overflows.
+func (a Amount) Sub(b Amount) (Amount, error) {
+	c := a - b
+	if (b > 0 && c > a) || (b < 0 && c < a) {
+		return 0, fmt.Errorf("Amount overflow: %d - %d", a, b)
+	}
+	return c, nil
+}
+
+// ParseAmount parses a decimal string into an Amount of the currency.
+func (c ConcreteCurrency) ParseAmount(s string) (Amount, error) {
+	return ParseAmount(s, c.DecimalPlaces())
+}
+
+// FormatAmount renders an amount of the currency as a decimal string.
+func (c ConcreteCurrency) FormatAmount(amount int64) string {
+	return Amount(amount).Format(c.DecimalPlaces())
+}
+
+// ParseAmount parses a decimal string into an Amount of the currency.
+func (c *CurrencyEntry) ParseAmount(s string) (Amount, error) {
+	return ParseAmount(s, c.DecimalPlaces)
+}
+
+// FormatAmount renders an amount of the currency as a decimal string.
+func (c *CurrencyEntry) FormatAmount(amount int64) string {
+	return Amount(amount).Format(c.DecimalPlaces)
+}
+
+// FormatBalances sets the Display of the balances whose currency is
+// registered with currencies.
+func FormatBalances(currencies CurrencyGetter, balances []Balance) {
+	for i := range balances {
+		if currency := currencies.GetCurrency(balances[i].Currency); currency != nil {
+			balances[i].Display = currency.FormatAmount(balances[i].Amount)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package types
+
+import (
+	"math"
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name          string
+		s             string
+		decimalPlaces uint
+		want          Amount
+		wantErr       bool
+	}{
+		{"twoDecimals", "100.00", 2, 10000, false},
+		{"oneDecimal", "100.5", 2, 10050, false},
+		{"noDecimals", "100", 2, 10000, false},
+		{"zero", "0", 2, 0, false},
+		{"negative", "-0.05", 2, -5, false},
+		{"noDecimalPlaces", "100", 0, 100, false},
+		{"threeDecimalPlaces", "1.234", 3, 1234, false},
+		{"max", "92233720368547758.07", 2, math.MaxInt64, false},
+		{"min", "-92233720368547758.08", 2, math.MinInt64, false},
+		{"overflow", "92233720368547758.08", 2, 0, true},
+		{"underflow", "-92233720368547758.09", 2, 0, true},
+		{"tooManyDecimals", "1.001", 2, 0, true},
+		{"decimalsWithoutDecimalPlaces", "1.0", 0, 0, true},
+		{"empty", "", 2, 0, true},
+		{"minusOnly", "-", 2, 0, true},
+		{"noInteger", ".5", 2, 0, true},
+		{"noFraction", "5.", 2, 0, true},
+		{"plusSign", "+5", 2, 0, true},
+		{"thousandsSeparator", "1,000.00", 2, 0, true},
+		{"exponent", "1e3", 2, 0, true},
+		{"twoPoints", "1.0.0", 2, 0, true},
+		{"spaces", " 1.00", 2, 0, true},
+		{"invalidDecimalPlaces", "1", MaxDecimalPlaces + 1, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAmount(tt.s, tt.decimalPlaces)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q. ParseAmount() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q. ParseAmount() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if got, err := Parse("100.00", "EUR"); err != nil || got != 10000 {
+		t.Errorf("Parse(100.00, EUR) = %v, %v, want 10000", got, err)
+	}
+	if got, err := Parse("100", "JPY"); err != nil || got != 100 {
+		t.Errorf("Parse(100, JPY) = %v, %v, want 100", got, err)
+	}
+	if _, err := Parse("1.00", "JPY"); err == nil {
+		t.Error("Parse(1.00, JPY) should fail")
+	}
+	if _, err := Parse("1.00", "XYZ"); err == nil {
+		t.Error("Parse(1.00, XYZ) should fail")
+	}
+}
+
+func TestAmount_Format(t *testing.T) {
+	tests := []struct {
+		a             Amount
+		decimalPlaces uint
+		want          string
+	}{
+		{10000, 2, "100.00"},
+		{5, 2, "0.05"},
+		{0, 2, "0.00"},
+		{-5, 2, "-0.05"},
+		{-10050, 2, "-100.50"},
+		{100, 0, "100"},
+		{1234, 3, "1.234"},
+		{math.MaxInt64, 2, "92233720368547758.07"},
+		{math.MinInt64, 2, "-92233720368547758.08"},
+		{1, MaxDecimalPlaces, "0.000000000000000001"},
+	}
+	for _, tt := range tests {
+		got := tt.a.Format(tt.decimalPlaces)
+		if got != tt.want {
+			t.Errorf("Amount(%d).Format(%d) = %q, want %q", tt.a, tt.decimalPlaces, got, tt.want)
+			continue
+		}
+		if back, err := ParseAmount(got, tt.decimalPlaces); err != nil || back != tt.a {
+			t.Errorf("ParseAmount(%q, %d) = %v, %v, want %d", got, tt.decimalPlaces, back, err, tt.a)
+		}
+	}
+}
+
+func TestAmount_AddSub(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    Amount
+		sum     Amount
+		sumErr  bool
+		diff    Amount
+		diffErr bool
+	}{
+		{"small", 3, 2, 5, false, 1, false},
+		{"negative", -3, 2, -1, false, -5, false},
+		{"maxPlusOne", math.MaxInt64, 1, 0, true, math.MaxInt64 - 1, false},
+		{"minMinusOne", math.MinInt64, 1, math.MinInt64 + 1, false, 0, true},
+		{"minusMin", 0, math.MinInt64, math.MinInt64, false, 0, true},
+		{"maxPlusMin", math.MaxInt64, math.MinInt64, -1, false, 0, true},
+	}
+	for _, tt := range tests {
+		sum, err := tt.a.Add(tt.b)
+		if (err != nil) != tt.sumErr || (err == nil && sum != tt.sum) {
+			t.Errorf("%q. Add() = %v, %v, want %v, wantErr %v", tt.name, sum, err, tt.sum, tt.sumErr)
+		}
+		diff, err := tt.a.Sub(tt.b)
+		if (err != nil) != tt.diffErr || (err == nil && diff != tt.diff) {
+			t.Errorf("%q. Sub() = %v, %v, want %v, wantErr %v", tt.name, diff, err, tt.diff, tt.diffErr)
+		}
+	}
+}
+
+func TestAmount_AddSubQuick(t *testing.T) {
+	minInt64, maxInt64 := big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)
+	fits := func(x *big.Int) bool { return x.Cmp(minInt64) >= 0 && x.Cmp(maxInt64) <= 0 }
+	add := func(a, b int64) bool {
+		want := new(big.Int).Add(big.NewInt(a), big.NewInt(b))
+		got, err := Amount(a).Add(Amount(b))
+		if !fits(want) {
+			return err != nil
+		}
+		return err == nil && int64(got) == want.Int64()
+	}
+	sub := func(a, b int64) bool {
+		want := new(big.Int).Sub(big.NewInt(a), big.NewInt(b))
+		got, err := Amount(a).Sub(Amount(b))
+		if !fits(want) {
+			return err != nil
+		}
+		return err == nil && int64(got) == want.Int64()
+	}
+	format := func(a int64, decimalPlaces uint8) bool {
+		d := uint(decimalPlaces) % (MaxDecimalPlaces + 1)
+		got, err := ParseAmount(Amount(a).Format(d), d)
+		return err == nil && int64(got) == a
+	}
+	for name, f := range map[string]interface{}{"Add": add, "Sub": sub, "Format": format} {
+		if err := quick.Check(f, &quick.Config{MaxCount: 10000}); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestFormatBalances(t *testing.T) {
+	currencies := NewCurrencyMap(
+		&CurrencyEntry{Symbol: "EUR", DecimalPlaces: 2, MinimumUnit: 1},
+		&CurrencyEntry{Symbol: "WHEAT-BU", DecimalPlaces: 3, MinimumUnit: 250},
+	)
+	balances := []Balance{{Currency: "EUR", Amount: 10050}, {Currency: "WHEAT-BU", Amount: 1500}, {Currency: "XYZ", Amount: 7}}
+	FormatBalances(currencies, balances)
+	want := []string{"100.50", "1.500", ""}
+	for i, b := range balances {
+		if b.Display != want[i] {
+			t.Errorf("%s: Display = %q, want %q", b.Currency, b.Display, want[i])
+		}
+	}
+}
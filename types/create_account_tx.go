@@ -1,6 +1,8 @@
 package types
 
 import (
+	"bytes"
+
 	"github.com/satori/go.uuid"
 	abci "github.com/tendermint/abci/types"
 	common "github.com/tendermint/go-common"
@@ -15,9 +17,12 @@ const (
 
 // CreateAccountTx defines the attributes of an account create.
 type CreateAccountTx struct {
-	Address   []byte           `json:"address"`    // Hash of the user's PubKey
-	AccountID string           `json:"account_id"` // ID of the new account
-	Signature crypto.Signature `json:"signature"`
+	Address          []byte           `json:"address"`                      // Hash of the user's PubKey
+	Nonce            uint64           `json:"nonce"`                        // Must be 1 greater than the user's last nonce
+	ValidUntilHeight uint64           `json:"valid_until_height,omitempty"` // Last height the Tx can be executed at, 0 if it never expires
+	ClientID         string           `json:"client_id,omitempty"`          // Client-generated UUID that makes the Tx idempotent, see CodeTypeDuplicateTx
+	AccountID        string           `json:"account_id"`                   // ID of the new account
+	Signature        crypto.Signature `json:"signature"`
 }
 
 // SignTx signs the transaction if its address and the privateKey's one match.
@@ -30,11 +35,40 @@ func (tx *CreateAccountTx) SignTx(privateKey crypto.PrivKey, chainID string) err
 	return nil
 }
 
+// SetSignature sets the Tx's signature if addr is the signer's address
+func (tx *CreateAccountTx) SetSignature(addr []byte, sig crypto.Signature) bool {
+	if !bytes.Equal(tx.Address, addr) {
+		return false
+	}
+	tx.Signature = sig
+	return true
+}
+
 // TxType returns the byte type of CreateAccountTx
 func (tx *CreateAccountTx) TxType() byte {
 	return TxTypeCreateAccount
 }
 
+// Signer returns the address of the user who signs the Tx
+func (tx *CreateAccountTx) Signer() []byte {
+	return tx.Address
+}
+
+// GetNonce returns the nonce of the user who signs the Tx
+func (tx *CreateAccountTx) GetNonce() uint64 {
+	return tx.Nonce
+}
+
+// GetValidUntilHeight returns the last height the Tx can be executed at
+func (tx *CreateAccountTx) GetValidUntilHeight() uint64 {
+	return tx.ValidUntilHeight
+}
+
+// GetClientID returns the client-generated ID of the Tx, if any
+func (tx *CreateAccountTx) GetClientID() string {
+	return tx.ClientID
+}
+
 // SignBytes generates a byte-to-byte signature
 func (tx *CreateAccountTx) SignBytes(chainID string) []byte {
 	signBytes := wire.BinaryBytes(chainID)
@@ -53,6 +87,12 @@ func (tx *CreateAccountTx) ValidateBasic() abci.Result {
 	if tx.Signature == nil {
 		return abci.ErrBaseInvalidSignature.AppendLog("The transaction must be signed")
 	}
+	if tx.Nonce == 0 {
+		return abci.ErrBaseInvalidSequence.AppendLog("Nonce must be greater than 0")
+	}
+	if res := validateClientID(tx.ClientID); res.IsErr() {
+		return res
+	}
 	if _, err := uuid.FromString(tx.AccountID); err != nil {
 		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Invalid account_id: %s", err))
 	}
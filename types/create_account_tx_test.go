@@ -53,6 +53,7 @@ func TestCreateAccountTx_SignBytes(t *testing.T) {
 func TestCreateAccountTx_ValidateBasic(t *testing.T) {
 	type fields struct {
 		Address   []byte
+		Nonce     uint64
 		AccountID string
 		Signature crypto.Signature
 	}
@@ -63,13 +64,15 @@ func TestCreateAccountTx_ValidateBasic(t *testing.T) {
 	}{
 		{"emptyTx", fields{}, abci.ErrBaseInvalidInput},
 		{"invalidAddress", fields{Address: []byte("")}, abci.ErrBaseInvalidInput},
-		{"invalidSignature", fields{crypto.CRandBytes(20), uuid.NewV4().String(), nil}, abci.ErrBaseInvalidSignature},
-		{"invalidAccountID", fields{crypto.CRandBytes(20), "", crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))}, abci.ErrBaseInvalidInput},
-		{"valid", fields{crypto.CRandBytes(20), uuid.NewV4().String(), crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))}, abci.OK},
+		{"invalidSignature", fields{crypto.CRandBytes(20), 1, uuid.NewV4().String(), nil}, abci.ErrBaseInvalidSignature},
+		{"invalidNonce", fields{crypto.CRandBytes(20), 0, uuid.NewV4().String(), crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))}, abci.ErrBaseInvalidSequence},
+		{"invalidAccountID", fields{crypto.CRandBytes(20), 1, "", crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))}, abci.ErrBaseInvalidInput},
+		{"valid", fields{crypto.CRandBytes(20), 1, uuid.NewV4().String(), crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))}, abci.OK},
 	}
 	for _, tt := range tests {
 		tx := &CreateAccountTx{
 			Address:   tt.fields.Address,
+			Nonce:     tt.fields.Nonce,
 			AccountID: tt.fields.AccountID,
 			Signature: tt.fields.Signature,
 		}
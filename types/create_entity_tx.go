@@ -1,6 +1,8 @@
 package types
 
 import (
+	"bytes"
+
 	uuid "github.com/satori/go.uuid"
 	abci "github.com/tendermint/abci/types"
 	common "github.com/tendermint/go-common"
@@ -15,12 +17,15 @@ const (
 
 // CreateLegalEntityTx defines the attributes of a legal entity create.
 type CreateLegalEntityTx struct {
-	Address   []byte           `json:"address"`   // Hash of the user's PubKey
-	EntityID  string           `json:"entity_id"` // ID of the new legal entity
-	ParentID  string           `json:"parent_id"` // ID of the new legal entity's parent
-	Type      byte             `json:"type"`      // Mandatory
-	Name      string           `json:"name"`      // Could be empty
-	Signature crypto.Signature `json:"signature"`
+	Address          []byte           `json:"address"`                      // Hash of the user's PubKey
+	Nonce            uint64           `json:"nonce"`                        // Must be 1 greater than the user's last nonce
+	ValidUntilHeight uint64           `json:"valid_until_height,omitempty"` // Last height the Tx can be executed at, 0 if it never expires
+	ClientID         string           `json:"client_id,omitempty"`          // Client-generated UUID that makes the Tx idempotent, see CodeTypeDuplicateTx
+	EntityID         string           `json:"entity_id"`                    // ID of the new legal entity
+	ParentID         string           `json:"parent_id"`                    // ID of the new legal entity's parent
+	Type             byte             `json:"type"`                         // Mandatory
+	Name             string           `json:"name"`                         // Could be empty
+	Signature        crypto.Signature `json:"signature"`
 }
 
 // SignTx signs the transaction if its address and the privateKey's one match.
@@ -33,11 +38,40 @@ func (tx *CreateLegalEntityTx) SignTx(privateKey crypto.PrivKey, chainID string)
 	return nil
 }
 
+// SetSignature sets the Tx's signature if addr is the signer's address
+func (tx *CreateLegalEntityTx) SetSignature(addr []byte, sig crypto.Signature) bool {
+	if !bytes.Equal(tx.Address, addr) {
+		return false
+	}
+	tx.Signature = sig
+	return true
+}
+
 // TxType returns the byte type of CreateLegalEntityTx
 func (tx *CreateLegalEntityTx) TxType() byte {
 	return TxTypeCreateLegalEntity
 }
 
+// Signer returns the address of the user who signs the Tx
+func (tx *CreateLegalEntityTx) Signer() []byte {
+	return tx.Address
+}
+
+// GetNonce returns the nonce of the user who signs the Tx
+func (tx *CreateLegalEntityTx) GetNonce() uint64 {
+	return tx.Nonce
+}
+
+// GetValidUntilHeight returns the last height the Tx can be executed at
+func (tx *CreateLegalEntityTx) GetValidUntilHeight() uint64 {
+	return tx.ValidUntilHeight
+}
+
+// GetClientID returns the client-generated ID of the Tx, if any
+func (tx *CreateLegalEntityTx) GetClientID() string {
+	return tx.ClientID
+}
+
 // SignBytes generates a byte-to-byte signature
 func (tx *CreateLegalEntityTx) SignBytes(chainID string) []byte {
 	signBytes := wire.BinaryBytes(chainID)
@@ -56,6 +90,12 @@ func (tx *CreateLegalEntityTx) ValidateBasic() abci.Result {
 	if tx.Signature == nil {
 		return abci.ErrBaseInvalidSignature.AppendLog("The transaction must be signed")
 	}
+	if tx.Nonce == 0 {
+		return abci.ErrBaseInvalidSequence.AppendLog("Nonce must be greater than 0")
+	}
+	if res := validateClientID(tx.ClientID); res.IsErr() {
+		return res
+	}
 	if _, err := uuid.FromString(tx.EntityID); err != nil {
 		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Invalid entity_id: %s", err))
 	}
@@ -60,6 +60,7 @@ func TestCreateLegalEntityTx_ValidateBasic(t *testing.T) {
 	genSig := func() crypto.Signature { return crypto.GenPrivKeyEd25519().Sign(randBytes()) }
 	type fields struct {
 		Address   []byte
+		Nonce     uint64
 		EntityID  string
 		Type      byte
 		Name      string
@@ -72,15 +73,17 @@ func TestCreateLegalEntityTx_ValidateBasic(t *testing.T) {
 	}{
 		{"emptyTx", fields{}, abci.ErrBaseInvalidInput},
 		{"invalidAddress", fields{Address: []byte("")}, abci.ErrBaseInvalidInput},
-		{"invalidSignature", fields{Address: randBytes(), EntityID: genID()}, abci.ErrBaseInvalidSignature},
-		{"invalidEntityID", fields{Address: randBytes(), EntityID: "", Signature: genSig()}, abci.ErrBaseInvalidInput},
-		{"invalidEntityType", fields{Address: randBytes(), EntityID: genID(), Signature: genSig(), Type: byte(0xFF)}, abci.ErrBaseInvalidInput},
-		{"valid", fields{randBytes(), genID(), byte(0xFF), "", genSig()}, abci.ErrBaseInvalidInput},
+		{"invalidSignature", fields{Address: randBytes(), Nonce: 1, EntityID: genID()}, abci.ErrBaseInvalidSignature},
+		{"invalidNonce", fields{Address: randBytes(), EntityID: genID(), Signature: genSig()}, abci.ErrBaseInvalidSequence},
+		{"invalidEntityID", fields{Address: randBytes(), Nonce: 1, EntityID: "", Signature: genSig()}, abci.ErrBaseInvalidInput},
+		{"invalidEntityType", fields{Address: randBytes(), Nonce: 1, EntityID: genID(), Signature: genSig(), Type: byte(0xFF)}, abci.ErrBaseInvalidInput},
+		{"valid", fields{randBytes(), 1, genID(), byte(0xFF), "", genSig()}, abci.ErrBaseInvalidInput},
 		//		{"valid", fields{randBytes(), uuid.NewV4().String(), crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))}, abci.OK},
 	}
 	for _, tt := range tests {
 		tx := &CreateLegalEntityTx{
 			Address:   tt.fields.Address,
+			Nonce:     tt.fields.Nonce,
 			EntityID:  tt.fields.EntityID,
 			Type:      tt.fields.Type,
 			Name:      tt.fields.Name,
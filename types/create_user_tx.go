@@ -1,6 +1,8 @@
 package types
 
 import (
+	"bytes"
+
 	abci "github.com/tendermint/abci/types"
 	common "github.com/tendermint/go-common"
 	crypto "github.com/tendermint/go-crypto"
@@ -14,11 +16,14 @@ const (
 
 // CreateUserTx defines the attributes of a user create.
 type CreateUserTx struct {
-	Address   []byte           `json:"address"`    // Hash of the user's PubKey
-	Name      string           `json:"name"`       // Human-readable identifier, mandatory
-	PubKey    crypto.PubKey    `json:"pub_key"`    // New user's public key
-	CanCreate bool             `json:"can_create"` // Whether the user is a super user or not
-	Signature crypto.Signature `json:"signature"`
+	Address          []byte           `json:"address"`                      // Hash of the user's PubKey
+	Nonce            uint64           `json:"nonce"`                        // Must be 1 greater than the user's last nonce
+	ValidUntilHeight uint64           `json:"valid_until_height,omitempty"` // Last height the Tx can be executed at, 0 if it never expires
+	ClientID         string           `json:"client_id,omitempty"`          // Client-generated UUID that makes the Tx idempotent, see CodeTypeDuplicateTx
+	Name             string           `json:"name"`                         // Human-readable identifier, mandatory
+	PubKey           crypto.PubKey    `json:"pub_key"`                      // New user's public key
+	CanCreate        bool             `json:"can_create"`                   // Whether the user is a super user or not
+	Signature        crypto.Signature `json:"signature"`
 }
 
 // SignTx signs the transaction if its address and the privateKey's one match.
@@ -31,11 +36,40 @@ func (tx *CreateUserTx) SignTx(privateKey crypto.PrivKey, chainID string) error
 	return nil
 }
 
+// SetSignature sets the Tx's signature if addr is the signer's address
+func (tx *CreateUserTx) SetSignature(addr []byte, sig crypto.Signature) bool {
+	if !bytes.Equal(tx.Address, addr) {
+		return false
+	}
+	tx.Signature = sig
+	return true
+}
+
 // TxType returns the byte type of CreateUserTx
 func (tx *CreateUserTx) TxType() byte {
 	return TxTypeCreateUser
 }
 
+// Signer returns the address of the user who signs the Tx
+func (tx *CreateUserTx) Signer() []byte {
+	return tx.Address
+}
+
+// GetNonce returns the nonce of the user who signs the Tx
+func (tx *CreateUserTx) GetNonce() uint64 {
+	return tx.Nonce
+}
+
+// GetValidUntilHeight returns the last height the Tx can be executed at
+func (tx *CreateUserTx) GetValidUntilHeight() uint64 {
+	return tx.ValidUntilHeight
+}
+
+// GetClientID returns the client-generated ID of the Tx, if any
+func (tx *CreateUserTx) GetClientID() string {
+	return tx.ClientID
+}
+
 // SignBytes generates a byte-to-byte signature
 func (tx *CreateUserTx) SignBytes(chainID string) []byte {
 	signBytes := wire.BinaryBytes(chainID)
@@ -60,6 +94,12 @@ func (tx *CreateUserTx) ValidateBasic() abci.Result {
 	if tx.Signature == nil {
 		return abci.ErrBaseInvalidSignature.AppendLog("The transaction must be signed")
 	}
+	if tx.Nonce == 0 {
+		return abci.ErrBaseInvalidSequence.AppendLog("Nonce must be greater than 0")
+	}
+	if res := validateClientID(tx.ClientID); res.IsErr() {
+		return res
+	}
 	return abci.OK
 }
 
@@ -59,6 +59,7 @@ func TestCreateUserTx_ValidateBasic(t *testing.T) {
 	genSig := func() crypto.Signature { return crypto.GenPrivKeyEd25519().Sign(randBytes()) }
 	type fields struct {
 		Address   []byte
+		Nonce     uint64
 		Name      string
 		PubKey    crypto.PubKey
 		CanCreate bool
@@ -74,11 +75,13 @@ func TestCreateUserTx_ValidateBasic(t *testing.T) {
 		{"invalidUserAddr", fields{Address: randBytes()}, abci.ErrBaseInvalidPubKey},
 		{"invalidName", fields{Address: randBytes(), PubKey: randPubKey()}, abci.ErrBaseInvalidInput},
 		{"invalidSignature", fields{Address: randBytes(), PubKey: randPubKey(), Name: "name"}, abci.ErrBaseInvalidSignature},
-		{"valid", fields{Address: randBytes(), PubKey: randPubKey(), Name: "name", Signature: genSig()}, abci.OK},
+		{"invalidNonce", fields{Address: randBytes(), PubKey: randPubKey(), Name: "name", Signature: genSig()}, abci.ErrBaseInvalidSequence},
+		{"valid", fields{Address: randBytes(), Nonce: 1, PubKey: randPubKey(), Name: "name", Signature: genSig()}, abci.OK},
 	}
 	for _, tt := range tests {
 		tx := &CreateUserTx{
 			Address:   tt.fields.Address,
+			Nonce:     tt.fields.Nonce,
 			Name:      tt.fields.Name,
 			PubKey:    tt.fields.PubKey,
 			CanCreate: tt.fields.CanCreate,
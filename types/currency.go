@@ -1,10 +1,27 @@
 package types
 
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	abci "github.com/tendermint/abci/types"
+	common "github.com/tendermint/go-common"
+)
+
 var (
-	// Currencies contains the in-memory database of supported currencies
+	// Currencies contains the ISO 4217 currencies the ledger's
+	// currency registry is seeded with unless genesis says otherwise
 	Currencies map[string]ConcreteCurrency
 )
 
+// MaxDecimalPlaces is the maximum number of decimals of a
+// registry's currency, beyond which int64 amounts are too coarse
+const MaxDecimalPlaces = 18
+
+var currencySymbolRegexp = regexp.MustCompile(`^[A-Z0-9][A-Z0-9\-]{1,15}$`)
+
 func init() {
 	Currencies = make(map[string]ConcreteCurrency)
 	currencyData := []struct {
@@ -37,7 +54,6 @@ func init() {
 		{2, 1, "BTN"},
 		{2, 1, "BWP"},
 		{2, 1, "BYN"},
-		{0, 1, "BYR"},
 		{2, 1, "BZD"},
 		{2, 1, "CAD"},
 		{2, 1, "CDF"},
@@ -108,7 +124,7 @@ func init() {
 		{2, 1, "MMK"},
 		{2, 1, "MNT"},
 		{2, 1, "MOP"},
-		{1, 1, "MRO"},
+		{2, 1, "MRU"},
 		{2, 1, "MUR"},
 		{2, 1, "MVR"},
 		{2, 1, "MWK"},
@@ -146,7 +162,7 @@ func init() {
 		{2, 1, "SOS"},
 		{2, 1, "SRD"},
 		{2, 1, "SSP"},
-		{2, 1, "STD"},
+		{2, 1, "STN"},
 		{2, 1, "SVC"},
 		{2, 1, "SYP"},
 		{2, 1, "SZL"},
@@ -166,7 +182,7 @@ func init() {
 		{0, 1, "UYI"},
 		{2, 1, "UYU"},
 		{2, 1, "UZS"},
-		{2, 1, "VEF"},
+		{2, 1, "VES"},
 		{0, 1, "VND"},
 		{0, 1, "VUV"},
 		{2, 1, "WST"},
@@ -218,3 +234,136 @@ func (c ConcreteCurrency) MinimumUnit() int64 {
 func (c ConcreteCurrency) ValidateAmount(amount int64) bool {
 	return (amount % c.MinimumUnit()) == 0
 }
+
+//-----------------------------------------------------------------------------
+
+// CurrencyEntry defines the attributes of a currency, or of any other
+// instrument such as a tokenised security or a commodity unit, in the
+// ledger's on-chain currency registry.
+type CurrencyEntry struct {
+	Symbol        string `json:"symbol"`            // ISO 4217 code or custom instrument's symbol
+	Name          string `json:"name,omitempty"`    // Human-readable name, optional
+	DecimalPlaces uint   `json:"decimal_places"`    // Number of decimals amounts are expressed with
+	MinimumUnit   int64  `json:"minimum_unit"`      // Amounts must be multiples of it
+	Retired       bool   `json:"retired,omitempty"` // Retired currencies can only be redeemed
+}
+
+// NewCurrencyEntry creates a registry's entry from a Currency.
+func NewCurrencyEntry(c Currency) *CurrencyEntry {
+	return &CurrencyEntry{Symbol: c.Symbol(), DecimalPlaces: c.DecimalPlaces(), MinimumUnit: c.MinimumUnit()}
+}
+
+// IsValidCurrencySymbol checks whether s can be used as a
+// currency's symbol, e.g. USD, XAU or ACME-2030.
+func IsValidCurrencySymbol(s string) bool {
+	return currencySymbolRegexp.MatchString(s)
+}
+
+// ValidateBasic performs basic validation on a CurrencyEntry.
+func (c *CurrencyEntry) ValidateBasic() abci.Result {
+	if !IsValidCurrencySymbol(c.Symbol) {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Invalid currency symbol: %q", c.Symbol))
+	}
+	if c.DecimalPlaces > MaxDecimalPlaces {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Too many decimal places: %d", c.DecimalPlaces))
+	}
+	if c.MinimumUnit <= 0 {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Minimum unit must be positive: %d", c.MinimumUnit))
+	}
+	return abci.OK
+}
+
+// ValidateAmount checks whether an amount is valid for the currency
+func (c *CurrencyEntry) ValidateAmount(amount int64) bool {
+	return (amount % c.MinimumUnit) == 0
+}
+
+func (c *CurrencyEntry) String() string {
+	if c == nil {
+		return "nil-CurrencyEntry"
+	}
+	return common.Fmt("CurrencyEntry{%s %q %v %v %v}", c.Symbol, c.Name, c.DecimalPlaces, c.MinimumUnit, c.Retired)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. An ISO 4217 code may
+// be given in place of the object as a shorthand, e.g. "EUR".
+func (c *CurrencyEntry) UnmarshalJSON(data []byte) error {
+	var symbol string
+	if err := json.Unmarshal(data, &symbol); err == nil {
+		currency, ok := Currencies[symbol]
+		if !ok {
+			return fmt.Errorf("Unknown ISO 4217 currency: %q", symbol)
+		}
+		*c = *NewCurrencyEntry(currency)
+		return nil
+	}
+	type entry CurrencyEntry // Drops the methods to avoid recursing
+	return json.Unmarshal(data, (*entry)(c))
+}
+
+// CurrenciesReturned defines the attributes of response's payload
+type CurrenciesReturned struct {
+	Currencies []*CurrencyEntry `json:"currencies"`
+	Next       string           `json:"next,omitempty"` // Cursor of the next page, empty on the last one
+}
+
+// CurrencyGetter is implemented by any value that has a GetCurrency method.
+type CurrencyGetter interface {
+	GetCurrency(symbol string) *CurrencyEntry
+}
+
+// CurrencySetter is implemented by any value that has a SetCurrency method.
+type CurrencySetter interface {
+	SetCurrency(symbol string, currency *CurrencyEntry)
+}
+
+// CurrencyMap is an in-memory currency registry.
+type CurrencyMap map[string]*CurrencyEntry
+
+// NewCurrencyMap creates a CurrencyMap holding the given currencies.
+func NewCurrencyMap(currencies ...*CurrencyEntry) CurrencyMap {
+	m := make(CurrencyMap, len(currencies))
+	for _, c := range currencies {
+		m[c.Symbol] = c
+	}
+	return m
+}
+
+// GetCurrency returns the currency registered with the given symbol, nil if none.
+func (m CurrencyMap) GetCurrency(symbol string) *CurrencyEntry {
+	return m[symbol]
+}
+
+// ISOCurrencies returns the ISO 4217 currencies as CurrencyEntries, sorted by symbol.
+func ISOCurrencies() []*CurrencyEntry {
+	symbols := make([]string, 0, len(Currencies))
+	for symbol := range Currencies {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	entries := make([]*CurrencyEntry, len(symbols))
+	for i, symbol := range symbols {
+		entries[i] = NewCurrencyEntry(Currencies[symbol])
+	}
+	return entries
+}
+
+// validateMoney checks that amount is positive and valid for the
+// currency, which must be registered.
+func validateMoney(currencies CurrencyGetter, amount int64, symbol string) abci.Result {
+	if amount <= 0 {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Amount must be positive: %v", amount))
+	}
+	if !IsValidCurrencySymbol(symbol) {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Invalid currency symbol: %q", symbol))
+	}
+	currency := currencies.GetCurrency(symbol)
+	if currency == nil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Unsupported currency: %q", symbol))
+	}
+	if !currency.ValidateAmount(amount) {
+		return abci.ErrBaseInvalidInput.AppendLog(
+			common.Fmt("Invalid amount %d for currency %s", amount, currency.Symbol))
+	}
+	return abci.OK
+}
@@ -36,3 +36,23 @@ func TestValidateAmount(t *testing.T) {
 			c.Symbol(), amount, amount%c.MinimumUnit())
 	}
 }
+
+func TestCurrencyEntry_ValidateBasic(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency CurrencyEntry
+		wantErr  bool
+	}{
+		{"iso", CurrencyEntry{Symbol: "EUR", DecimalPlaces: 2, MinimumUnit: 1}, false},
+		{"customInstrument", CurrencyEntry{Symbol: "ACME-2030", Name: "ACME bond", DecimalPlaces: 0, MinimumUnit: 1000}, false},
+		{"lowercaseSymbol", CurrencyEntry{Symbol: "eur", DecimalPlaces: 2, MinimumUnit: 1}, true},
+		{"shortSymbol", CurrencyEntry{Symbol: "E", DecimalPlaces: 2, MinimumUnit: 1}, true},
+		{"tooManyDecimals", CurrencyEntry{Symbol: "EUR", DecimalPlaces: MaxDecimalPlaces + 1, MinimumUnit: 1}, true},
+		{"zeroMinimumUnit", CurrencyEntry{Symbol: "EUR", DecimalPlaces: 2}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.currency.ValidateBasic(); got.IsErr() != tt.wantErr {
+			t.Errorf("%q. CurrencyEntry.ValidateBasic() = %v, wantErr %v", tt.name, got, tt.wantErr)
+		}
+	}
+}
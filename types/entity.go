@@ -3,6 +3,8 @@ package types
 import (
 	"bytes"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/tendermint/go-common"
 )
@@ -20,6 +22,37 @@ func IsValidEntityType(b byte) bool {
 	return bytes.Contains([]byte{EntityTypeCHByte, EntityTypeGCMByte, EntityTypeICMByte, EntityTypeCustodianByte}, []byte{b})
 }
 
+var entityTypesByName = map[string]byte{
+	"ch":        EntityTypeCHByte,
+	"gcm":       EntityTypeGCMByte,
+	"icm":       EntityTypeICMByte,
+	"custodian": EntityTypeCustodianByte,
+}
+
+// ParseEntityType converts either an entity type's name (e.g. "gcm")
+// or its numeric value (e.g. "2") into the respective byte identifier.
+func ParseEntityType(s string) (byte, bool) {
+	if t, ok := entityTypesByName[strings.ToLower(s)]; ok {
+		return t, true
+	}
+	n, err := strconv.ParseUint(s, 10, 8)
+	if err != nil || !IsValidEntityType(byte(n)) {
+		return 0, false
+	}
+	return byte(n), true
+}
+
+// EntityTypeName returns the name of an entity type, e.g. "gcm", or its
+// numeric value if it has none.
+func EntityTypeName(t byte) string {
+	for name, b := range entityTypesByName {
+		if b == t {
+			return name
+		}
+	}
+	return strconv.Itoa(int(t))
+}
+
 // LegalEntity defines the attributes of a legal entity
 type LegalEntity struct {
 	ID          string `json:"id"`           // LegalEntity's ID
@@ -51,6 +84,7 @@ func NewLegalEntityByType(t byte, id string, name string, creatorAddr []byte, En
 func NewCH(id string, name string, creatorAddr []byte, EntityID string) *LegalEntity {
 	return NewLegalEntity(id, EntityTypeCHByte, name, NewPermByTxType(
 		TxTypeTransfer, TxTypeCreateAccount, TxTypeCreateLegalEntity, TxTypeCreateUser,
+		TxTypeIssue, TxTypeRedeem, TxTypeAddCurrency, TxTypeUpdateCurrency, TxTypeRetireCurrency,
 	), creatorAddr, EntityID)
 }
 
@@ -98,8 +132,10 @@ func (l *LegalEntity) String() string {
 	return fmt.Sprintf("LegalEntity{%x %s %q %v %x %v}", l.Type, l.ID, l.Name, l.Permissions, l.CreatorAddr, l.EntityID)
 }
 
+// LegalEntitiesReturned defines the attributes of response's payload
 type LegalEntitiesReturned struct {
 	LegalEntities []*LegalEntity `json:"legal_entities"`
+	Next          string         `json:"next,omitempty"` // Cursor of the next page, empty on the last one
 }
 
 //--------------------------------------------
@@ -154,3 +154,41 @@ func TestIsValidEntityType(t *testing.T) {
 		}
 	}
 }
+
+func TestParseEntityType(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		want   byte
+		wantOk bool
+	}{
+		{"name", "gcm", EntityTypeGCMByte, true},
+		{"nameUpperCase", "Custodian", EntityTypeCustodianByte, true},
+		{"number", "1", EntityTypeCHByte, true},
+		{"invalidName", "bank", 0, false},
+		{"invalidNumber", "255", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseEntityType(tt.s)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("%q. ParseEntityType() = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestEntityTypeName(t *testing.T) {
+	tests := []struct {
+		name string
+		t    byte
+		want string
+	}{
+		{"ch", EntityTypeCHByte, "ch"},
+		{"custodian", EntityTypeCustodianByte, "custodian"},
+		{"unknown", 0x09, "9"},
+	}
+	for _, tt := range tests {
+		if got := EntityTypeName(tt.t); got != tt.want {
+			t.Errorf("%q. EntityTypeName() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
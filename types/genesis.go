@@ -0,0 +1,376 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/satori/go.uuid"
+	"github.com/tendermint/go-wire"
+)
+
+// GenesisVersion is the version of the genesis document's schema
+const GenesisVersion = 1
+
+// GenesisDoc defines the initial state of the ledger.
+type GenesisDoc struct {
+	Version       int              `json:"version"`
+	ChainID       string           `json:"chain_id"`
+	Height        uint64           `json:"height,omitempty"`     // Height the state was exported at, if any
+	Currencies    []*CurrencyEntry `json:"currencies,omitempty"` // Currency registry, the ISO 4217 currencies if empty
+	LegalEntities []*LegalEntity   `json:"legal_entities"`
+	Users         []*User          `json:"users"`
+	Accounts      []*Account       `json:"accounts"`
+	Supply        []Balance        `json:"supply,omitempty"`     // Must match the sum of the wallets' balances if given
+	Transfers     []*Transfer      `json:"transfers,omitempty"`  // Transfer history, oldest first
+	TxResults     []*TxResult      `json:"tx_results,omitempty"` // Results of the Txs that carried a ClientID, oldest first
+	Txs           []*TxRecord      `json:"txs,omitempty"`        // Records of the delivered Txs, oldest first
+}
+
+// genesisJSON mirrors GenesisDoc; users are left raw as
+// their PubKey must be handled by go-wire's JSON codec.
+type genesisJSON struct {
+	Version       int               `json:"version"`
+	ChainID       string            `json:"chain_id"`
+	Height        uint64            `json:"height,omitempty"`
+	Currencies    []*CurrencyEntry  `json:"currencies,omitempty"`
+	LegalEntities []*LegalEntity    `json:"legal_entities"`
+	Users         []json.RawMessage `json:"users"`
+	Accounts      []*Account        `json:"accounts"`
+	Supply        []Balance         `json:"supply,omitempty"`
+	Transfers     []*Transfer       `json:"transfers,omitempty"`
+	TxResults     []*TxResult       `json:"tx_results,omitempty"`
+	Txs           []*TxRecord       `json:"txs,omitempty"`
+}
+
+// GenesisDocFromJSON decodes and validates a genesis document.
+func GenesisDocFromJSON(data []byte) (*GenesisDoc, error) {
+	var doc GenesisDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("Error decoding genesis document: %v", err)
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (g GenesisDoc) MarshalJSON() ([]byte, error) {
+	raw := genesisJSON{
+		Version:       g.Version,
+		ChainID:       g.ChainID,
+		Height:        g.Height,
+		Currencies:    g.Currencies,
+		LegalEntities: g.LegalEntities,
+		Users:         make([]json.RawMessage, len(g.Users)),
+		Accounts:      g.Accounts,
+		Supply:        g.Supply,
+		Transfers:     g.Transfers,
+		TxResults:     g.TxResults,
+		Txs:           g.Txs,
+	}
+	for i, user := range g.Users {
+		raw.Users[i] = wire.JSONBytes(user)
+	}
+	return json.Marshal(raw)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (g *GenesisDoc) UnmarshalJSON(data []byte) error {
+	var raw genesisJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	users := make([]*User, len(raw.Users))
+	for i, userJSON := range raw.Users {
+		var err error
+		wire.ReadJSONPtr(&users[i], userJSON, &err)
+		if err != nil {
+			return fmt.Errorf("user #%d: %v", i, err)
+		}
+	}
+	*g = GenesisDoc{
+		Version:       raw.Version,
+		ChainID:       raw.ChainID,
+		Height:        raw.Height,
+		Currencies:    raw.Currencies,
+		LegalEntities: raw.LegalEntities,
+		Users:         users,
+		Accounts:      raw.Accounts,
+		Supply:        raw.Supply,
+		Transfers:     raw.Transfers,
+		TxResults:     raw.TxResults,
+		Txs:           raw.Txs,
+	}
+	return nil
+}
+
+// GenesisPage is a page of the export of the ledger's state, whose
+// objects are appended to the document of the previous pages.
+type GenesisPage struct {
+	Genesis *GenesisDoc `json:"genesis"`
+	Next    string      `json:"next,omitempty"` // Cursor of the next page, empty on the last one
+}
+
+// Append appends the objects of a page of an export to the document.
+func (g *GenesisDoc) Append(page *GenesisDoc) {
+	g.Currencies = append(g.Currencies, page.Currencies...)
+	g.LegalEntities = append(g.LegalEntities, page.LegalEntities...)
+	g.Users = append(g.Users, page.Users...)
+	g.Accounts = append(g.Accounts, page.Accounts...)
+	g.Supply = append(g.Supply, page.Supply...)
+	g.Transfers = append(g.Transfers, page.Transfers...)
+	g.TxResults = append(g.TxResults, page.TxResults...)
+	g.Txs = append(g.Txs, page.Txs...)
+}
+
+// CurrencyMap returns the currency registry the ledger starts with.
+func (g *GenesisDoc) CurrencyMap() CurrencyMap {
+	if len(g.Currencies) == 0 {
+		return NewCurrencyMap(ISOCurrencies()...)
+	}
+	m := make(CurrencyMap, len(g.Currencies))
+	for _, c := range g.Currencies {
+		if c != nil {
+			m[c.Symbol] = c
+		}
+	}
+	return m
+}
+
+// Validate checks the genesis document's consistency: objects must be
+// well formed and unique, refer to objects defined in the document, and
+// only be granted permissions their legal entity holds. Balances may be
+// negative, as a transfer doesn't check that the sender's covers its amount.
+// All problems found are reported in the returned error.
+func (g *GenesisDoc) Validate() error {
+	var problems []string
+	report := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+
+	if g.Version != GenesisVersion {
+		report("unsupported version %d, want %d", g.Version, GenesisVersion)
+	}
+	if len(g.ChainID) == 0 {
+		report("chain_id is missing")
+	}
+
+	// Currencies
+	currencies := g.CurrencyMap()
+	seen := make(map[string]bool)
+	for i, c := range g.Currencies {
+		if c == nil {
+			report("currency #%d is null", i)
+			continue
+		}
+		if res := c.ValidateBasic(); res.IsErr() {
+			report("currency %q: %s", c.Symbol, res.Log)
+		}
+		if seen[c.Symbol] {
+			report("currency %q is duplicated", c.Symbol)
+		}
+		seen[c.Symbol] = true
+	}
+
+	// Legal entities
+	entities := make(map[string]*LegalEntity)
+	for i, e := range g.LegalEntities {
+		if e == nil {
+			report("legal entity #%d is null", i)
+			continue
+		}
+		if _, err := uuid.FromString(e.ID); err != nil {
+			report("legal entity #%d: invalid id %q", i, e.ID)
+		}
+		if _, ok := entities[e.ID]; ok {
+			report("legal entity %s is duplicated", e.ID)
+		}
+		entities[e.ID] = e
+		if !IsValidEntityType(e.Type) {
+			report("legal entity %s: invalid type %d", e.ID, e.Type)
+		}
+		if !e.Permissions.IsValid() {
+			report("legal entity %s: unknown permissions %d", e.ID, e.Permissions)
+		}
+		if e.Type != EntityTypeCHByte && e.Permissions.Has(PermCHOnly) {
+			report("legal entity %s: permissions %d are reserved to the clearing house", e.ID, e.Permissions)
+		}
+	}
+	for _, e := range g.LegalEntities {
+		if e == nil || len(e.EntityID) == 0 {
+			continue
+		}
+		if _, ok := entities[e.EntityID]; !ok {
+			report("legal entity %s: unknown parent %s", e.ID, e.EntityID)
+			continue
+		}
+		// Walk up the hierarchy, which must be a tree
+		seen := map[string]bool{e.ID: true}
+		for parent := entities[e.EntityID]; parent != nil; parent = entities[parent.EntityID] {
+			if seen[parent.ID] {
+				report("legal entity %s: cyclic hierarchy", e.ID)
+				break
+			}
+			seen[parent.ID] = true
+		}
+	}
+
+	// Users
+	users := make(map[string]bool)
+	for i, u := range g.Users {
+		if u == nil || u.PubKey == nil {
+			report("user #%d: pub_key is missing", i)
+			continue
+		}
+		addr := fmt.Sprintf("%X", u.PubKey.Address())
+		if users[addr] {
+			report("user %s is duplicated", addr)
+		}
+		users[addr] = true
+		if len(u.Name) == 0 {
+			report("user %s: name is missing", addr)
+		}
+		if !u.Permissions.IsValid() {
+			report("user %s: unknown permissions %d", addr, u.Permissions)
+		}
+		entity, ok := entities[u.EntityID]
+		if !ok {
+			report("user %s: unknown legal entity %q", addr, u.EntityID)
+			continue
+		}
+		if u.Permissions.Clear(entity.Permissions) != PermNone {
+			report("user %s: permissions %d exceed its legal entity's %d", addr, u.Permissions, entity.Permissions)
+		}
+	}
+
+	// Accounts
+	accounts := make(map[string]bool)
+	balances := Balances{}
+	for i, acc := range g.Accounts {
+		if acc == nil {
+			report("account #%d is null", i)
+			continue
+		}
+		if _, err := uuid.FromString(acc.ID); err != nil {
+			report("account #%d: invalid id %q", i, acc.ID)
+		}
+		if accounts[acc.ID] {
+			report("account %s is duplicated", acc.ID)
+		}
+		accounts[acc.ID] = true
+		if _, ok := entities[acc.EntityID]; !ok {
+			report("account %s: unknown legal entity %q", acc.ID, acc.EntityID)
+		}
+		wallets := make(map[string]bool)
+		for _, wal := range acc.Wallets {
+			if currencies.GetCurrency(wal.Currency) == nil {
+				report("account %s: currency %q is not registered", acc.ID, wal.Currency)
+			}
+			if wallets[wal.Currency] {
+				report("account %s: wallet %s is duplicated", acc.ID, wal.Currency)
+			}
+			wallets[wal.Currency] = true
+			total, err := Amount(balances[wal.Currency]).Add(Amount(wal.Balance))
+			if err != nil {
+				report("account %s: total balance of %s overflows", acc.ID, wal.Currency)
+			}
+			balances[wal.Currency] = int64(total)
+		}
+	}
+
+	// Supply
+	if len(g.Supply) > 0 {
+		supply := Balances{}
+		for _, b := range g.Supply {
+			if _, ok := supply[b.Currency]; ok {
+				report("supply of %s is duplicated", b.Currency)
+			}
+			supply[b.Currency] = b.Amount
+		}
+		for _, b := range balances.ToSlice() {
+			if supply[b.Currency] != b.Amount {
+				report("supply of %s is %d, but the wallets hold %d", b.Currency, supply[b.Currency], b.Amount)
+			}
+		}
+		for _, b := range g.Supply {
+			if _, ok := balances[b.Currency]; !ok && b.Amount != 0 {
+				report("supply of %s is %d, but no wallet holds it", b.Currency, b.Amount)
+			}
+		}
+	}
+
+	// Transfers
+	transfers := make(map[string]bool)
+	for i, t := range g.Transfers {
+		if t == nil {
+			report("transfer #%d is null", i)
+			continue
+		}
+		if len(t.ID) == 0 {
+			report("transfer #%d: id is missing", i)
+		}
+		if transfers[t.ID] {
+			report("transfer %s is duplicated", t.ID)
+		}
+		transfers[t.ID] = true
+		if !accounts[t.SenderID] {
+			report("transfer %s: unknown sender account %q", t.ID, t.SenderID)
+		}
+		if !accounts[t.RecipientID] {
+			report("transfer %s: unknown recipient account %q", t.ID, t.RecipientID)
+		}
+		if len(t.Reference) > MaxReferenceLength {
+			report("transfer %s: reference is longer than %d bytes", t.ID, MaxReferenceLength)
+		}
+		if len(t.Memo) > MaxMemoLength {
+			report("transfer %s: memo is longer than %d bytes", t.ID, MaxMemoLength)
+		}
+	}
+
+	// Tx results
+	clientIDs := make(map[string]bool)
+	for i, r := range g.TxResults {
+		if r == nil {
+			report("tx result #%d is null", i)
+			continue
+		}
+		if _, err := uuid.FromString(r.ClientID); err != nil {
+			report("tx result #%d: invalid client_id %q", i, r.ClientID)
+		}
+		if clientIDs[r.ClientID] {
+			report("tx result %s is duplicated", r.ClientID)
+		}
+		clientIDs[r.ClientID] = true
+		if !users[fmt.Sprintf("%X", r.Signer)] {
+			report("tx result %s: unknown signer %X", r.ClientID, r.Signer)
+		}
+	}
+
+	// Tx records
+	hashes := make(map[string]bool)
+	for i, r := range g.Txs {
+		if r == nil {
+			report("tx #%d is null", i)
+			continue
+		}
+		hash := fmt.Sprintf("%X", r.Hash)
+		if hashes[hash] {
+			report("tx %s is duplicated", hash)
+		}
+		hashes[hash] = true
+		if !bytes.Equal(r.Hash, TxHash(r.Tx)) {
+			report("tx %s: hash doesn't match the tx", hash)
+		}
+		if _, err := r.DecodeTx(); err != nil {
+			report("tx %s: can't be decoded: %v", hash, err)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("Invalid genesis document:\n\t%s", strings.Join(problems, "\n\t"))
+	}
+	return nil
+}
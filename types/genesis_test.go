@@ -0,0 +1,175 @@
+package types
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/satori/go.uuid"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	"github.com/tendermint/go-wire"
+)
+
+func validGenesisDoc() *GenesisDoc {
+	ch := NewCH(uuid.NewV4().String(), "CH", nil, "")
+	gcm := NewGCM(uuid.NewV4().String(), "GCM", nil, ch.ID)
+	acc := NewAccount(uuid.NewV4().String(), gcm.ID)
+	acc.Wallets = []Wallet{{Currency: "EUR", Balance: 100, Sequence: 1}}
+	return &GenesisDoc{
+		Version:       GenesisVersion,
+		ChainID:       "chain",
+		Currencies:    []*CurrencyEntry{NewCurrencyEntry(Currencies["EUR"])},
+		LegalEntities: []*LegalEntity{ch, gcm},
+		Users: []*User{
+			NewUser(crypto.GenPrivKeyEd25519().PubKey(), "user", ch.ID, ch.Permissions),
+		},
+		Accounts: []*Account{acc},
+		Supply:   []Balance{{Currency: "EUR", Amount: 100}},
+	}
+}
+
+// genesisTransfer returns a transfer between the accounts of g
+func genesisTransfer(g *GenesisDoc) *Transfer {
+	return &Transfer{ID: "A5211E-1", SenderID: g.Accounts[0].ID, RecipientID: g.Accounts[0].ID, Amount: 10, Currency: "EUR", Reference: "trade-1"}
+}
+
+func genesisTxResult(g *GenesisDoc) *TxResult {
+	return &TxResult{ClientID: "1d3e1e0c-0e8b-4d8a-9a67-3c1e0e2b5f0a", Signer: g.Users[0].PubKey.Address(), Nonce: 1, Height: 1}
+}
+
+func genesisTxRecord(g *GenesisDoc) *TxRecord {
+	tx := &CreateAccountTx{Address: g.Users[0].PubKey.Address(), Nonce: 1, AccountID: g.Accounts[0].ID}
+	return NewTxRecord(wire.BinaryBytes(struct{ Tx }{tx}), tx, 1, abci.OK)
+}
+
+func TestGenesisDocFromJSON(t *testing.T) {
+	doc := validGenesisDoc()
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	got, err := GenesisDocFromJSON(data)
+	if err != nil {
+		t.Fatalf("GenesisDocFromJSON() error = %v", err)
+	}
+	if len(got.Users) != 1 || !got.Users[0].Equal(doc.Users[0]) {
+		t.Errorf("GenesisDocFromJSON().Users = %v, want %v", got.Users, doc.Users)
+	}
+	if len(got.Accounts) != 1 || !got.Accounts[0].Equal(doc.Accounts[0]) {
+		t.Errorf("GenesisDocFromJSON().Accounts = %v, want %v", got.Accounts, doc.Accounts)
+	}
+	// ISO 4217 currencies can be given by their code
+	got, err = GenesisDocFromJSON([]byte(`{"version":1,"chain_id":"chain","currencies":["EUR",{"symbol":"XAU-G","decimal_places":3,"minimum_unit":10}]}`))
+	if err != nil {
+		t.Fatalf("GenesisDocFromJSON() error = %v", err)
+	}
+	if want := []*CurrencyEntry{{Symbol: "EUR", DecimalPlaces: 2, MinimumUnit: 1}, {Symbol: "XAU-G", DecimalPlaces: 3, MinimumUnit: 10}}; !reflect.DeepEqual(got.Currencies, want) {
+		t.Errorf("GenesisDocFromJSON().Currencies = %v, want %v", got.Currencies, want)
+	}
+	if _, err := GenesisDocFromJSON([]byte(`{"version":`)); err == nil {
+		t.Errorf("GenesisDocFromJSON() expected to fail on malformed JSON")
+	}
+
+	// The sample genesis file must always be valid
+	data, err = ioutil.ReadFile("../genesis.json")
+	if err != nil {
+		t.Fatalf("ioutil.ReadFile() error = %v", err)
+	}
+	if _, err := GenesisDocFromJSON(data); err != nil {
+		t.Errorf("GenesisDocFromJSON(genesis.json) error = %v", err)
+	}
+}
+
+func TestGenesisDoc_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(g *GenesisDoc)
+		wantErr string
+	}{
+		{"valid", func(g *GenesisDoc) {}, ""},
+		{"unsupportedVersion", func(g *GenesisDoc) { g.Version = 0 }, "unsupported version"},
+		{"missingChainID", func(g *GenesisDoc) { g.ChainID = "" }, "chain_id is missing"},
+		{"customInstrument", func(g *GenesisDoc) {
+			g.Currencies = append(g.Currencies, &CurrencyEntry{Symbol: "WHEAT-BU", DecimalPlaces: 3, MinimumUnit: 250})
+		}, ""},
+		{"invalidCurrency", func(g *GenesisDoc) {
+			g.Currencies = append(g.Currencies, &CurrencyEntry{Symbol: "usd", DecimalPlaces: 2, MinimumUnit: 1})
+		}, "Invalid currency symbol"},
+		{"duplicatedCurrency", func(g *GenesisDoc) { g.Currencies = append(g.Currencies, g.Currencies[0]) }, "is duplicated"},
+		{"duplicatedEntity", func(g *GenesisDoc) { g.LegalEntities = append(g.LegalEntities, g.LegalEntities[0]) }, "is duplicated"},
+		{"unknownParent", func(g *GenesisDoc) { g.LegalEntities[0].EntityID = uuid.NewV4().String() }, "unknown parent"},
+		{"cyclicHierarchy", func(g *GenesisDoc) { g.LegalEntities[0].EntityID = g.LegalEntities[1].ID }, "cyclic hierarchy"},
+		{"nonCHIssuer", func(g *GenesisDoc) { g.LegalEntities[1].Permissions = g.LegalEntities[1].Permissions.Add(PermIssueTx) }, "reserved to the clearing house"},
+		{"unknownPermissions", func(g *GenesisDoc) { g.LegalEntities[0].Permissions = Perm(1 << 40) }, "unknown permissions"},
+		{"userUnknownEntity", func(g *GenesisDoc) { g.Users[0].EntityID = uuid.NewV4().String() }, "unknown legal entity"},
+		{"userExceedingPermissions", func(g *GenesisDoc) { g.Users[0].EntityID = g.LegalEntities[1].ID }, "exceed"},
+		{"duplicatedUser", func(g *GenesisDoc) { g.Users = append(g.Users, g.Users[0]) }, "is duplicated"},
+		{"accountUnknownEntity", func(g *GenesisDoc) { g.Accounts[0].EntityID = "" }, "unknown legal entity"},
+		{"disallowedCurrency", func(g *GenesisDoc) {
+			g.Accounts[0].Wallets = append(g.Accounts[0].Wallets, Wallet{Currency: "USD"})
+		}, "not registered"},
+		{"negativeBalance", func(g *GenesisDoc) {
+			acc := &Account{ID: uuid.NewV4().String(), EntityID: g.Accounts[0].EntityID}
+			acc.Wallets = []Wallet{{Currency: "EUR", Balance: -30}}
+			g.Accounts = append(g.Accounts, acc)
+			g.Accounts[0].Wallets[0].Balance = 130
+		}, ""},
+		{"balanceOverflow", func(g *GenesisDoc) {
+			acc := &Account{ID: uuid.NewV4().String(), EntityID: g.Accounts[0].EntityID}
+			acc.Wallets = []Wallet{{Currency: "EUR", Balance: math.MaxInt64}}
+			g.Accounts = append(g.Accounts, acc)
+			g.Supply = nil
+		}, "overflows"},
+		{"supplyMismatch", func(g *GenesisDoc) { g.Supply[0].Amount = 99 }, "but the wallets hold"},
+		{"noSupply", func(g *GenesisDoc) { g.Supply = nil }, ""},
+		{"transfer", func(g *GenesisDoc) { g.Transfers = []*Transfer{genesisTransfer(g)} }, ""},
+		{"duplicatedTransfer", func(g *GenesisDoc) { g.Transfers = []*Transfer{genesisTransfer(g), genesisTransfer(g)} }, "is duplicated"},
+		{"transferUnknownAccount", func(g *GenesisDoc) {
+			g.Transfers = []*Transfer{genesisTransfer(g)}
+			g.Transfers[0].RecipientID = uuid.NewV4().String()
+		}, "unknown recipient account"},
+		{"transferReferenceTooLong", func(g *GenesisDoc) {
+			g.Transfers = []*Transfer{genesisTransfer(g)}
+			g.Transfers[0].Reference = strings.Repeat("x", MaxReferenceLength+1)
+		}, "reference is longer"},
+		{"txResult", func(g *GenesisDoc) { g.TxResults = []*TxResult{genesisTxResult(g)} }, ""},
+		{"duplicatedTxResult", func(g *GenesisDoc) { g.TxResults = []*TxResult{genesisTxResult(g), genesisTxResult(g)} }, "is duplicated"},
+		{"txResultInvalidClientID", func(g *GenesisDoc) {
+			g.TxResults = []*TxResult{genesisTxResult(g)}
+			g.TxResults[0].ClientID = "client-id"
+		}, "invalid client_id"},
+		{"txResultUnknownSigner", func(g *GenesisDoc) {
+			g.TxResults = []*TxResult{genesisTxResult(g)}
+			g.TxResults[0].Signer = []byte{0x01}
+		}, "unknown signer"},
+		{"txRecord", func(g *GenesisDoc) { g.Txs = []*TxRecord{genesisTxRecord(g)} }, ""},
+		{"duplicatedTxRecord", func(g *GenesisDoc) { g.Txs = []*TxRecord{genesisTxRecord(g), genesisTxRecord(g)} }, "is duplicated"},
+		{"txRecordHashMismatch", func(g *GenesisDoc) {
+			g.Txs = []*TxRecord{genesisTxRecord(g)}
+			g.Txs[0].Hash = TxHash(nil)
+		}, "hash doesn't match"},
+		{"txRecordUndecodable", func(g *GenesisDoc) {
+			g.Txs = []*TxRecord{genesisTxRecord(g)}
+			g.Txs[0].Tx = []byte{0xFF}
+			g.Txs[0].Hash = TxHash(g.Txs[0].Tx)
+		}, "can't be decoded"},
+	}
+	for _, tt := range tests {
+		g := validGenesisDoc()
+		tt.mutate(g)
+		err := g.Validate()
+		if len(tt.wantErr) == 0 {
+			if err != nil {
+				t.Errorf("%q. GenesisDoc.Validate() error = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%q. GenesisDoc.Validate() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
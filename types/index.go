@@ -8,9 +8,10 @@ type Index interface {
 	Add(s string)
 }
 
-// AccountIndex stores the list of accounts managed on the ledger.
+// AccountIndex stores a page of the list of accounts managed on the ledger.
 type AccountIndex struct {
 	Accounts []string `json:"accounts"`
+	Next     string   `json:"next,omitempty"` // Cursor of the next page, empty on the last one
 }
 
 // NewAccountIndex creates a new accounts index
@@ -42,27 +43,13 @@ func (i *AccountIndex) Add(s string) {
 
 //-----------------------------------------
 
-// AccountIndexGetter is implemented by any value that has a GetAccountIndex
-type AccountIndexGetter interface {
-	GetAccountIndex() *AccountIndex
-}
-
-// AccountIndexSetter is implemented by any value that has a SetAccountIndex
-type AccountIndexSetter interface {
-	SetAccountIndex(i *AccountIndex)
-}
-
-// AccountIndexGetterSetter is implemented by any value that has both
-// GetAccountIndex and SetAccountIndex
-type AccountIndexGetterSetter interface {
-	GetAccountIndex() *AccountIndex
-	SetAccountIndex(i *AccountIndex)
-}
-
+// LegalEntityIndex stores a page of the list of legal entities managed on the ledger.
 type LegalEntityIndex struct {
-	Ids []string `json:"ids"`
+	Ids  []string `json:"ids"`
+	Next string   `json:"next,omitempty"` // Cursor of the next page, empty on the last one
 }
 
+// Has returns whether s is listed in the legal entities index.
 func (i *LegalEntityIndex) Has(s string) bool {
 	for _, t := range i.Ids {
 		if t == s {
@@ -72,6 +59,12 @@ func (i *LegalEntityIndex) Has(s string) bool {
 	return false
 }
 
+// ToStringSlice returns a string slice representation of the index.
+func (i *LegalEntityIndex) ToStringSlice() []string {
+	return i.Ids
+}
+
+// Add adds a legal entity to the index, if it's not yet there.
 func (i *LegalEntityIndex) Add(s string) {
 	if !i.Has(s) {
 		i.Ids = append(i.Ids, s)
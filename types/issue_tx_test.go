@@ -0,0 +1,62 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	wire "github.com/tendermint/go-wire"
+)
+
+func TestIssueTx_SignBytes(t *testing.T) {
+	chainID := "chainID"
+	privKey := crypto.GenPrivKeyEd25519()
+	tx := &IssueTx{
+		Address:   privKey.PubKey().Address(),
+		AccountID: "account_id",
+		Amount:    100,
+		Currency:  "EUR",
+		Nonce:     1,
+	}
+	if err := tx.SignTx(privKey, chainID); err != nil {
+		t.Fatalf("IssueTx.SignTx() error = %v", err)
+	}
+	signedBytes := tx.SignBytes(chainID)
+	tx.Signature = nil
+	expected := append(wire.BinaryBytes(chainID), wire.BinaryBytes(tx)...)
+	if !bytes.Equal(signedBytes, expected) {
+		t.Errorf("IssueTx.SignBytes() = %v, want: %v", signedBytes, expected)
+	}
+}
+
+func TestIssueTx_ValidateBasic(t *testing.T) {
+	addr := crypto.CRandBytes(20)
+	accountID := uuid.NewV4().String()
+	sig := crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))
+	currencies := NewCurrencyMap(NewCurrencyEntry(Currencies["EUR"]), &CurrencyEntry{Symbol: "XAU-G", MinimumUnit: 10})
+	tests := []struct {
+		name string
+		tx   IssueTx
+		want abci.Result
+	}{
+		{"emptyTx", IssueTx{}, abci.ErrBaseInvalidInput},
+		{"invalidSignature", IssueTx{addr, 1, 0, "", accountID, 100, "EUR", nil}, abci.ErrBaseInvalidSignature},
+		{"invalidAccountID", IssueTx{addr, 1, 0, "", "", 100, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"zeroAmount", IssueTx{addr, 1, 0, "", accountID, 0, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"negativeAmount", IssueTx{addr, 1, 0, "", accountID, -100, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"invalidCurrency", IssueTx{addr, 1, 0, "", accountID, 100, "XYZ", sig}, abci.ErrBaseInvalidInput},
+		{"unregisteredCurrency", IssueTx{addr, 1, 0, "", accountID, 100, "USD", sig}, abci.ErrBaseInvalidInput},
+		{"invalidAmountForCurrency", IssueTx{addr, 1, 0, "", accountID, 15, "XAU-G", sig}, abci.ErrBaseInvalidInput},
+		{"invalidNonce", IssueTx{addr, 0, 0, "", accountID, 100, "EUR", sig}, abci.ErrBaseInvalidSequence},
+		{"invalidClientID", IssueTx{addr, 1, 0, "client-id", accountID, 100, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"valid", IssueTx{addr, 1, 0, "", accountID, 100, "EUR", sig}, abci.OK},
+		{"validWithClientID", IssueTx{addr, 1, 0, uuid.NewV4().String(), accountID, 100, "EUR", sig}, abci.OK},
+	}
+	for _, tt := range tests {
+		if got := tt.tx.ValidateBasic(currencies); got.Code != tt.want.Code {
+			t.Errorf("%q. IssueTx.ValidateBasic() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
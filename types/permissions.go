@@ -9,6 +9,11 @@ const (
 	PermCreateAccountTx
 	PermCreateLegalEntityTx
 	PermCreateUserTx
+	PermIssueTx
+	PermRedeemTx
+	PermAddCurrencyTx
+	PermUpdateCurrencyTx
+	PermRetireCurrencyTx
 	PermNone = Perm(0)
 )
 
@@ -17,8 +22,17 @@ var permissionsMapByTxType = map[byte]Perm{
 	TxTypeCreateAccount:     PermCreateAccountTx,
 	TxTypeCreateLegalEntity: PermCreateLegalEntityTx,
 	TxTypeCreateUser:        PermCreateUserTx,
+	TxTypeIssue:             PermIssueTx,
+	TxTypeRedeem:            PermRedeemTx,
+	TxTypeAddCurrency:       PermAddCurrencyTx,
+	TxTypeUpdateCurrency:    PermUpdateCurrencyTx,
+	TxTypeRetireCurrency:    PermRetireCurrencyTx,
 }
 
+// PermCHOnly groups the permissions only the clearing house can be granted.
+var PermCHOnly = NewPermByTxType(TxTypeIssue, TxTypeRedeem,
+	TxTypeAddCurrency, TxTypeUpdateCurrency, TxTypeRetireCurrency)
+
 // NewPermByTxType creates a Perm object by ORing the Tx respective permissions.
 func NewPermByTxType(bs ...byte) Perm {
 	var p Perm
@@ -28,6 +42,15 @@ func NewPermByTxType(bs ...byte) Perm {
 	return p
 }
 
+// IsValid returns whether p only has the bits of known permissions set.
+func (p Perm) IsValid() bool {
+	var all Perm
+	for _, perm := range permissionsMapByTxType {
+		all = all.Add(perm)
+	}
+	return p.Clear(all) == PermNone
+}
+
 // Has returns (p & perms) != 0
 func (p Perm) Has(perms Perm) bool {
 	return (p & perms) != 0
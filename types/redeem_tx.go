@@ -0,0 +1,111 @@
+package types
+
+import (
+	"bytes"
+
+	"github.com/satori/go.uuid"
+	abci "github.com/tendermint/abci/types"
+	common "github.com/tendermint/go-common"
+	crypto "github.com/tendermint/go-crypto"
+	wire "github.com/tendermint/go-wire"
+)
+
+const (
+	// TxTypeRedeem defines RedeemTx's code
+	TxTypeRedeem = byte(0x06)
+)
+
+// RedeemTx defines the attributes of a money redemption, by which
+// the clearing house debits an account and destroys the money.
+type RedeemTx struct {
+	Address          []byte           `json:"address"`                      // Hash of the user's PubKey
+	Nonce            uint64           `json:"nonce"`                        // Must be 1 greater than the user's last nonce
+	ValidUntilHeight uint64           `json:"valid_until_height,omitempty"` // Last height the Tx can be executed at, 0 if it never expires
+	ClientID         string           `json:"client_id,omitempty"`          // Client-generated UUID that makes the Tx idempotent, see CodeTypeDuplicateTx
+	AccountID        string           `json:"account_id"`                   // Account to debit
+	Amount           int64            `json:"amount"`
+	Currency         string           `json:"currency"`
+	Signature        crypto.Signature `json:"signature"`
+}
+
+// SignTx signs the transaction if its address and the privateKey's one match.
+func (tx *RedeemTx) SignTx(privateKey crypto.PrivKey, chainID string) error {
+	sig, err := SignTx(tx.SignBytes(chainID), tx.Address, privateKey)
+	if err != nil {
+		return err
+	}
+	tx.Signature = sig
+	return nil
+}
+
+// SetSignature sets the Tx's signature if addr is the signer's address
+func (tx *RedeemTx) SetSignature(addr []byte, sig crypto.Signature) bool {
+	if !bytes.Equal(tx.Address, addr) {
+		return false
+	}
+	tx.Signature = sig
+	return true
+}
+
+// TxType returns the byte type of RedeemTx
+func (tx *RedeemTx) TxType() byte {
+	return TxTypeRedeem
+}
+
+// Signer returns the address of the user who signs the Tx
+func (tx *RedeemTx) Signer() []byte {
+	return tx.Address
+}
+
+// GetNonce returns the nonce of the user who signs the Tx
+func (tx *RedeemTx) GetNonce() uint64 {
+	return tx.Nonce
+}
+
+// GetValidUntilHeight returns the last height the Tx can be executed at
+func (tx *RedeemTx) GetValidUntilHeight() uint64 {
+	return tx.ValidUntilHeight
+}
+
+// GetClientID returns the client-generated ID of the Tx, if any
+func (tx *RedeemTx) GetClientID() string {
+	return tx.ClientID
+}
+
+// SignBytes generates a byte-to-byte signature
+func (tx *RedeemTx) SignBytes(chainID string) []byte {
+	signBytes := wire.BinaryBytes(chainID)
+	sig := tx.Signature
+	tx.Signature = nil
+	signBytes = append(signBytes, wire.BinaryBytes(tx)...)
+	tx.Signature = sig
+	return signBytes
+}
+
+// ValidateBasic performs basic validation on the Tx,
+// the amount is validated against the currency registry.
+func (tx *RedeemTx) ValidateBasic(currencies CurrencyGetter) abci.Result {
+	if len(tx.Address) != 20 {
+		return abci.ErrBaseInvalidInput.AppendLog("Invalid address length")
+	}
+	if tx.Signature == nil {
+		return abci.ErrBaseInvalidSignature.AppendLog("The transaction must be signed")
+	}
+	if tx.Nonce == 0 {
+		return abci.ErrBaseInvalidSequence.AppendLog("Nonce must be greater than 0")
+	}
+	if res := validateClientID(tx.ClientID); res.IsErr() {
+		return res
+	}
+	if _, err := uuid.FromString(tx.AccountID); err != nil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Invalid account_id: %s", err))
+	}
+	if res := validateMoney(currencies, tx.Amount, tx.Currency); res.IsErr() {
+		return res
+	}
+	return abci.OK
+}
+
+func (tx *RedeemTx) String() string {
+	return common.Fmt("RedeemTx{%x,%v,%q,%v,%v}", tx.Address, tx.Nonce, tx.AccountID, tx.Amount, tx.Currency)
+}
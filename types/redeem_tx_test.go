@@ -0,0 +1,61 @@
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	uuid "github.com/satori/go.uuid"
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+	wire "github.com/tendermint/go-wire"
+)
+
+func TestRedeemTx_SignBytes(t *testing.T) {
+	chainID := "chainID"
+	privKey := crypto.GenPrivKeyEd25519()
+	tx := &RedeemTx{
+		Address:   privKey.PubKey().Address(),
+		AccountID: "account_id",
+		Amount:    100,
+		Currency:  "EUR",
+		Nonce:     1,
+	}
+	if err := tx.SignTx(privKey, chainID); err != nil {
+		t.Fatalf("RedeemTx.SignTx() error = %v", err)
+	}
+	signedBytes := tx.SignBytes(chainID)
+	tx.Signature = nil
+	expected := append(wire.BinaryBytes(chainID), wire.BinaryBytes(tx)...)
+	if !bytes.Equal(signedBytes, expected) {
+		t.Errorf("RedeemTx.SignBytes() = %v, want: %v", signedBytes, expected)
+	}
+}
+
+func TestRedeemTx_ValidateBasic(t *testing.T) {
+	addr := crypto.CRandBytes(20)
+	accountID := uuid.NewV4().String()
+	sig := crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))
+	currencies := NewCurrencyMap(NewCurrencyEntry(Currencies["EUR"]), &CurrencyEntry{Symbol: "XAU-G", MinimumUnit: 10})
+	tests := []struct {
+		name string
+		tx   RedeemTx
+		want abci.Result
+	}{
+		{"emptyTx", RedeemTx{}, abci.ErrBaseInvalidInput},
+		{"invalidSignature", RedeemTx{addr, 1, 0, "", accountID, 100, "EUR", nil}, abci.ErrBaseInvalidSignature},
+		{"invalidAccountID", RedeemTx{addr, 1, 0, "", "", 100, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"zeroAmount", RedeemTx{addr, 1, 0, "", accountID, 0, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"negativeAmount", RedeemTx{addr, 1, 0, "", accountID, -100, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"invalidCurrency", RedeemTx{addr, 1, 0, "", accountID, 100, "XYZ", sig}, abci.ErrBaseInvalidInput},
+		{"unregisteredCurrency", RedeemTx{addr, 1, 0, "", accountID, 100, "USD", sig}, abci.ErrBaseInvalidInput},
+		{"invalidAmountForCurrency", RedeemTx{addr, 1, 0, "", accountID, 15, "XAU-G", sig}, abci.ErrBaseInvalidInput},
+		{"invalidNonce", RedeemTx{addr, 0, 0, "", accountID, 100, "EUR", sig}, abci.ErrBaseInvalidSequence},
+		{"invalidClientID", RedeemTx{addr, 1, 0, "client-id", accountID, 100, "EUR", sig}, abci.ErrBaseInvalidInput},
+		{"valid", RedeemTx{addr, 1, 0, "", accountID, 100, "EUR", sig}, abci.OK},
+	}
+	for _, tt := range tests {
+		if got := tt.tx.ValidateBasic(currencies); got.Code != tt.want.Code {
+			t.Errorf("%q. RedeemTx.ValidateBasic() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
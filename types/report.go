@@ -0,0 +1,66 @@
+package types
+
+import "sort"
+
+// Balance defines the total amount held in a currency. Display is the
+// amount as a decimal string, set only when asked for.
+type Balance struct {
+	Currency string `json:"currency"`
+	Amount   int64  `json:"amount"`
+	Display  string `json:"display,omitempty"`
+}
+
+// Balances maps currencies to total amounts.
+type Balances map[string]int64
+
+// Add adds the balances of the account's wallets.
+func (b Balances) Add(acc *Account) {
+	for _, wal := range acc.Wallets {
+		b[wal.Currency] += wal.Balance
+	}
+}
+
+// Merge adds the amounts of c to b.
+func (b Balances) Merge(c Balances) {
+	for currency, amount := range c {
+		b[currency] += amount
+	}
+}
+
+// ToSlice returns the balances sorted by currency.
+func (b Balances) ToSlice() []Balance {
+	balances := make([]Balance, 0, len(b))
+	for currency, amount := range b {
+		balances = append(balances, Balance{Currency: currency, Amount: amount})
+	}
+	sort.Slice(balances, func(i, j int) bool { return balances[i].Currency < balances[j].Currency })
+	return balances
+}
+
+// EntityBalances defines the aggregated balances of a legal entity's accounts.
+type EntityBalances struct {
+	EntityID string    `json:"entity_id"`
+	Name     string    `json:"name"`
+	ParentID string    `json:"parent_id"`
+	Accounts int       `json:"accounts"` // Number of accounts
+	Balances []Balance `json:"balances"`
+}
+
+// BalanceReport defines the attributes of response's payload. When Rollup is
+// set, Totals include the balances of all the entity's descendants and
+// Entities lists the contribution of each of them.
+type BalanceReport struct {
+	EntityID string           `json:"entity_id"`
+	Rollup   bool             `json:"rollup"`
+	Totals   []Balance        `json:"totals"`
+	Entities []EntityBalances `json:"entities"`
+}
+
+// SupplyReport defines the attributes of response's payload. The ledger is
+// consistent when Violations is empty, that is, for every currency the sum
+// of all wallet balances equals the tracked supply.
+type SupplyReport struct {
+	Supply     []Balance `json:"supply"`
+	Balances   []Balance `json:"balances"`
+	Violations []string  `json:"violations"`
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+func TestRetireCurrencyTx_ValidateBasic(t *testing.T) {
+	addr := crypto.CRandBytes(20)
+	sig := crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))
+	tests := []struct {
+		name string
+		tx   RetireCurrencyTx
+		want abci.Result
+	}{
+		{"emptyTx", RetireCurrencyTx{}, abci.ErrBaseInvalidInput},
+		{"invalidSignature", RetireCurrencyTx{addr, 1, 0, "", "XAU-G", nil}, abci.ErrBaseInvalidSignature},
+		{"invalidSymbol", RetireCurrencyTx{addr, 1, 0, "", "gold", sig}, abci.ErrBaseInvalidInput},
+		{"invalidNonce", RetireCurrencyTx{addr, 0, 0, "", "XAU-G", sig}, abci.ErrBaseInvalidSequence},
+		{"invalidClientID", RetireCurrencyTx{addr, 1, 0, "client-id", "XAU-G", sig}, abci.ErrBaseInvalidInput},
+		{"valid", RetireCurrencyTx{addr, 1, 0, "", "XAU-G", sig}, abci.OK},
+	}
+	for _, tt := range tests {
+		if got := tt.tx.ValidateBasic(); got.Code != tt.want.Code {
+			t.Errorf("%q. RetireCurrencyTx.ValidateBasic() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"fmt"
+)
+
+// Transfer is the record of an executed TransferTx, as kept in the
+// ledger's transfer history.
+type Transfer struct {
+	ID          string `json:"id"`     // See TransferID
+	Height      uint64 `json:"height"` // Height of the block the transfer was executed in
+	Committer   []byte `json:"committer"`
+	SenderID    string `json:"sender_id"`
+	RecipientID string `json:"recipient_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Reference   string `json:"reference,omitempty"`
+	Memo        string `json:"memo,omitempty"`
+}
+
+// NewTransfer records a TransferTx executed at the given height.
+func NewTransfer(tx *TransferTx, height uint64) *Transfer {
+	return &Transfer{
+		ID:          TransferID(tx.Committer.Address, tx.Committer.Nonce),
+		Height:      height,
+		Committer:   tx.Committer.Address,
+		SenderID:    tx.Sender.AccountID,
+		RecipientID: tx.Recipient.AccountID,
+		Amount:      tx.Sender.Amount,
+		Currency:    tx.Sender.Currency,
+		Reference:   tx.Reference,
+		Memo:        tx.Memo,
+	}
+}
+
+// TransferID identifies a transfer by its committer's address and nonce,
+// which no other Tx can share.
+func TransferID(committer []byte, nonce uint64) string {
+	return fmt.Sprintf("%X-%d", committer, nonce)
+}
+
+func (t *Transfer) String() string {
+	if t == nil {
+		return "nil-Transfer"
+	}
+	return fmt.Sprintf("Transfer{%s %v: %s->%s %v %s %q}", t.ID, t.Height, t.SenderID, t.RecipientID, t.Amount, t.Currency, t.Reference)
+}
+
+// TransfersReturned defines the attributes of a transfer history's response
+type TransfersReturned struct {
+	Transfers []*Transfer `json:"transfers"`
+	Next      string      `json:"next,omitempty"` // Cursor of the next page, empty on the last one
+}
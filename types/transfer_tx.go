@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"errors"
 
 	"github.com/satori/go.uuid"
 	abci "github.com/tendermint/abci/types"
@@ -13,6 +14,13 @@ import (
 const (
 	// TxTypeTransfer defines TrasferTx's code
 	TxTypeTransfer = byte(0x01)
+
+	// MaxReferenceLength is the maximum length of a transfer's reference,
+	// that of an ISO 20022 end-to-end identification
+	MaxReferenceLength = 35
+	// MaxMemoLength is the maximum length of a transfer's memo,
+	// that of an ISO 20022 unstructured remittance information
+	MaxMemoLength = 140
 )
 
 // TransferTx defines the attributes of transfer transaction
@@ -20,13 +28,18 @@ type TransferTx struct {
 	Committer      TxTransferCommitter       `json:"committer"`
 	Sender         TxTransferSender          `json:"sender"`
 	Recipient      TxTransferRecipient       `json:"recipient"`
+	Reference      string                    `json:"reference,omitempty"` // Client's reference, e.g. an end-to-end ID
+	Memo           string                    `json:"memo,omitempty"`      // Free text for the recipient
 	CounterSigners []TxTransferCounterSigner `json:"counter_signers"`
 }
 
 // TxTransferCommitter defines the attributes of a transfer's sender
 type TxTransferCommitter struct {
-	Address   []byte           `json:"address"` // Hash of the user's PubKey
-	Signature crypto.Signature `json:"signature"`
+	Address          []byte           `json:"address"`                      // Hash of the user's PubKey
+	Nonce            uint64           `json:"nonce"`                        // Must be 1 greater than the user's last nonce
+	ValidUntilHeight uint64           `json:"valid_until_height,omitempty"` // Last height the Tx can be executed at, 0 if it never expires
+	ClientID         string           `json:"client_id,omitempty"`          // Client-generated UUID that makes the Tx idempotent, see CodeTypeDuplicateTx
+	Signature        crypto.Signature `json:"signature"`
 }
 
 // TxTransferSender defines the attributes of a transfer's sender
@@ -34,7 +47,6 @@ type TxTransferSender struct {
 	AccountID string `json:"account_id"` // Sender's Account ID
 	Amount    int64  `json:"amount"`
 	Currency  string `json:"currency"` //3-letter ISO 4217 code or ""
-	Sequence  int    `json:"sequence"` // Must be 1 greater than the last committed TxInput or 0 if the account is just a countersigner
 }
 
 // TxTransferRecipient defines the attributes of a transfer's recipient
@@ -44,8 +56,8 @@ type TxTransferRecipient struct {
 
 // TxTransferCounterSigner defines the attributes of a transfer's counter signer
 type TxTransferCounterSigner struct {
-	Address   []byte           `json:"address"` // Hash of the user's PubKey
-	Signature crypto.Signature `json:"signature"`
+	Address   []byte           `json:"address"`   // Hash of the user's PubKey
+	Signature crypto.Signature `json:"signature"` // Signature of the TransferTx's SignBytes, like the committer's
 }
 
 //-----------------------------------------------------------------------------
@@ -55,6 +67,26 @@ func (tx *TransferTx) TxType() byte {
 	return TxTypeTransfer
 }
 
+// Signer returns the address of the user who signs the Tx
+func (tx *TransferTx) Signer() []byte {
+	return tx.Committer.Address
+}
+
+// GetNonce returns the nonce of the user who signs the Tx
+func (tx *TransferTx) GetNonce() uint64 {
+	return tx.Committer.Nonce
+}
+
+// GetValidUntilHeight returns the last height the Tx can be executed at
+func (tx *TransferTx) GetValidUntilHeight() uint64 {
+	return tx.Committer.ValidUntilHeight
+}
+
+// GetClientID returns the client-generated ID of the Tx, if any
+func (tx *TransferTx) GetClientID() string {
+	return tx.Committer.ClientID
+}
+
 // SignBytes generates a byte-to-byte signature
 func (tx *TransferTx) SignBytes(chainID string) []byte {
 	signBytes := wire.BinaryBytes(chainID)
@@ -92,20 +124,33 @@ func (tx *TransferTx) String() string {
 	return common.Fmt("TransferTx{%v: %v->%v, %v}", tx.Committer, tx.Sender, tx.Recipient, tx.CounterSigners)
 }
 
-// ValidateBasic validates Tx basic structure.
-func (tx *TransferTx) ValidateBasic() (res abci.Result) {
+// ValidateBasic validates Tx basic structure,
+// the amount is validated against the currency registry.
+func (tx *TransferTx) ValidateBasic(currencies CurrencyGetter) (res abci.Result) {
 	// Check the committer
 	if res := tx.Committer.ValidateBasic(); res.IsErr() {
 		return res
 	}
 	// Check the sender
-	if res := tx.Sender.ValidateBasic(); res.IsErr() {
+	if res := tx.Sender.ValidateBasic(currencies); res.IsErr() {
 		return res
 	}
 	// Check the recipient
 	if res := tx.Recipient.ValidateBasic(); res.IsErr() {
 		return res
 	}
+	// Both sides of a transfer to the sender's account would be stored, the
+	// credit overwriting the debit
+	if tx.Sender.AccountID == tx.Recipient.AccountID {
+		return abci.ErrBaseInvalidOutput.AppendLog("The recipient's account must differ from the sender's")
+	}
+	// Check the reference and the memo
+	if len(tx.Reference) > MaxReferenceLength {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Reference is longer than %d bytes", MaxReferenceLength))
+	}
+	if len(tx.Memo) > MaxMemoLength {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Memo is longer than %d bytes", MaxMemoLength))
+	}
 	// Check the countersigners
 	for _, in := range tx.CounterSigners {
 		if res := in.ValidateBasic(); res.IsErr() {
@@ -115,87 +160,74 @@ func (tx *TransferTx) ValidateBasic() (res abci.Result) {
 	return abci.OK
 }
 
-// SignTx signs the transaction if its address and the privateKey's one match.
+// SignTx signs the transaction as its committer or as one of its counter
+// signers, whichever the privateKey's address matches. All of them sign
+// the same SignBytes, hence the whole transfer.
 func (tx *TransferTx) SignTx(privateKey crypto.PrivKey, chainID string) error {
-	sig, err := SignTx(tx.SignBytes(chainID), tx.Committer.Address, privateKey)
-	if err != nil {
-		return err
+	addr := privateKey.PubKey().Address()
+	if !tx.SetSignature(addr, privateKey.Sign(tx.SignBytes(chainID))) {
+		return errors.New(common.Fmt("SignTx: %x is neither the committer nor a counter signer", addr))
 	}
-	tx.Committer.Signature = sig
-
 	return nil
 }
 
+// MissingSignatures returns the addresses of the committer and the
+// counter signers who haven't signed the transaction yet.
+func (tx *TransferTx) MissingSignatures() [][]byte {
+	missing := [][]byte{}
+	if tx.Committer.Signature == nil {
+		missing = append(missing, tx.Committer.Address)
+	}
+	for _, in := range tx.CounterSigners {
+		if in.Signature == nil {
+			missing = append(missing, in.Address)
+		}
+	}
+	return missing
+}
+
 //-----------------------------------------------------------------------------
 
-// ValidateBasic performs basic validation on a TxInputTransfer
+// ValidateBasic performs basic validation on a TxTransferCommitter
 func (t TxTransferCommitter) ValidateBasic() abci.Result {
 	if len(t.Address) != 20 {
 		return abci.ErrBaseInvalidInput.AppendLog("Invalid address length")
 	}
 	if t.Signature == nil {
-		return abci.ErrBaseInvalidSignature.AppendLog("TxTransferCommitter transaction must be signed")
+		return abci.ErrBaseInvalidSignature.AppendLog("The transaction must be signed")
 	}
-	return abci.OK
-}
-
-// SignBytes generates a byte-to-byte signature.
-func (t TxTransferCommitter) SignBytes(chainID string) []byte {
-	signBytes := wire.BinaryBytes(chainID)
-	sig := t.Signature
-	t.Signature = nil
-	signBytes = append(signBytes, wire.BinaryBytes(t)...)
-	t.Signature = sig
-	return signBytes
-}
-
-// SignTx signs the transaction if its address and the privateKey's one match.
-func (t *TxTransferCommitter) SignTx(privateKey crypto.PrivKey, chainID string) error {
-	sig, err := SignTx(t.SignBytes(chainID), t.Address, privateKey)
-	if err != nil {
-		return err
+	if t.Nonce == 0 {
+		return abci.ErrBaseInvalidSequence.AppendLog("Nonce must be greater than 0")
 	}
-	t.Signature = sig
-	return nil
+	if res := validateClientID(t.ClientID); res.IsErr() {
+		return res
+	}
+	return abci.OK
 }
 
 // String returns a string representation of TxTransferCommitter
 func (t TxTransferCommitter) String() string {
-	return common.Fmt("TxTransferCommitter{%x, %v}", t.Address, t.Signature)
+	return common.Fmt("TxTransferCommitter{%x,%v}", t.Address, t.Nonce)
 }
 
 //-----------------------------------------------------------------------------
 
-// ValidateBasic performs basic validation on a TxInputTransfer
-func (t TxTransferSender) ValidateBasic() abci.Result {
+// ValidateBasic performs basic validation on a TxInputTransfer,
+// the amount is validated against the currency registry.
+func (t TxTransferSender) ValidateBasic(currencies CurrencyGetter) abci.Result {
 	if _, err := uuid.FromString(t.AccountID); err != nil {
 		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Invalid account_id: %s", err))
 	}
-	if t.Amount <= 0 {
-		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Amount must be non-negative: %q", t.Amount))
-	}
-	if len(t.Currency) != 3 {
-		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Currency must be either empty or a 3-letter ISO 4217 compliant code: %q", t.Currency))
-	}
 	// Validate currency and amount
-	currency, ok := Currencies[t.Currency]
-	if !ok {
-		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Unsupported currency: %q", t.Currency))
-	}
-	// Validate the amount against the currency
-	if !currency.ValidateAmount(t.Amount) {
-		return abci.ErrBaseInvalidInput.AppendLog(
-			common.Fmt("Invalid amount %d for currency %s", t.Amount, currency.Symbol()))
-	}
-	if t.Sequence <= 0 {
-		return abci.ErrBaseInvalidSequence.AppendLog(common.Fmt("Sequence must be greater than 0"))
+	if res := validateMoney(currencies, t.Amount, t.Currency); res.IsErr() {
+		return res
 	}
 	return abci.OK
 }
 
 // String returns a string representation of TxTransferSender
 func (t TxTransferSender) String() string {
-	return common.Fmt("TxTransferSender{%v,%v,%v}", t.Amount, t.Currency, t.Sequence)
+	return common.Fmt("TxTransferSender{%v,%v}", t.Amount, t.Currency)
 }
 
 //-----------------------------------------------------------------------------
@@ -230,19 +262,3 @@ func (t TxTransferCounterSigner) ValidateBasic() abci.Result {
 func (t TxTransferCounterSigner) String() string {
 	return common.Fmt("TxTransferCounterSigner{%x,%v}", t.Address, t.Signature)
 }
-
-// SignBytes generates a byte-to-byte signature.
-func (t TxTransferCounterSigner) SignBytes(chainID string) []byte {
-	signBytes := wire.BinaryBytes(chainID)
-	sig := t.Signature
-	t.Signature = nil
-	signBytes = append(signBytes, wire.BinaryBytes(t)...)
-	t.Signature = sig
-	return signBytes
-}
-
-// SignTx signs the transaction if its address and the privateKey's one match.
-func (t *TxTransferCounterSigner) SignTx(privateKey crypto.PrivKey, chainID string) error {
-	t.Signature = privateKey.Sign(t.SignBytes(chainID))
-	return nil
-}
@@ -3,6 +3,8 @@ package types
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/satori/go.uuid"
@@ -17,13 +19,13 @@ func TestTransferTx_SignBytes(t *testing.T) {
 	tx := &TransferTx{
 		Committer: TxTransferCommitter{
 			Address:   []byte("test_sender_address"),
+			Nonce:     1,
 			Signature: privKey.Sign(crypto.CRandBytes(128)),
 		},
 		Sender: TxTransferSender{
 			AccountID: uuid.NewV4().String(),
 			Amount:    99999999,
 			Currency:  "USD",
-			Sequence:  1,
 		},
 		CounterSigners: []TxTransferCounterSigner{
 			TxTransferCounterSigner{
@@ -64,7 +66,6 @@ func TestTransferTx_SetSignature(t *testing.T) {
 			AccountID: uuid.NewV4().String(),
 			Amount:    99999999,
 			Currency:  "USD",
-			Sequence:  1,
 		},
 		CounterSigners: []TxTransferCounterSigner{
 			TxTransferCounterSigner{
@@ -106,10 +107,13 @@ func TestTransferTx_SetSignature(t *testing.T) {
 
 func TestTransferTx_ValidateBasic(t *testing.T) {
 	signature := crypto.GenPrivKeyEd25519().Sign([]byte("test_content"))
+	currencies := NewCurrencyMap(ISOCurrencies()...)
 	type fields struct {
 		Committer      TxTransferCommitter
 		Sender         TxTransferSender
 		Recipient      TxTransferRecipient
+		Reference      string
+		Memo           string
 		CounterSigners []TxTransferCounterSigner
 	}
 	tests := []struct {
@@ -119,46 +123,74 @@ func TestTransferTx_ValidateBasic(t *testing.T) {
 	}{
 		{"emptySender", fields{Sender: TxTransferSender{}}, abci.ErrBaseInvalidInput},
 		{"invalidSender", fields{Sender: TxTransferSender{AccountID: uuid.NewV4().String()}}, abci.ErrBaseInvalidInput},
-		{"invalidSequence", fields{
+		{"invalidNonce", fields{
 			Sender: TxTransferSender{
 				AccountID: uuid.NewV4().String(),
 				Currency:  "USD",
 				Amount:    100,
 			},
 			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Signature: signature},
-		}, abci.ErrBaseInvalidSequence},
+			Recipient: TxTransferRecipient{AccountID: uuid.NewV4().String()}}, abci.ErrBaseInvalidSequence},
 		{"emptyRecipient", fields{
 			Sender: TxTransferSender{
 				AccountID: uuid.NewV4().String(),
 				Currency:  "USD",
-				Amount:    100,
-				Sequence:  1},
-			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Signature: signature},
+				Amount:    100},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
 		}, abci.ErrBaseInvalidOutput},
 		{"validWithoutConterSigners", fields{
 			Sender: TxTransferSender{
 				AccountID: uuid.NewV4().String(),
 				Currency:  "USD",
-				Amount:    100,
-				Sequence:  1},
-			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Signature: signature},
+				Amount:    100},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
 			Recipient: TxTransferRecipient{AccountID: uuid.NewV4().String()}}, abci.OK},
+		{"sameAccount", fields{
+			Sender: TxTransferSender{
+				AccountID: "a",
+				Currency:  "USD",
+				Amount:    100},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
+			Recipient: TxTransferRecipient{AccountID: "a"}}, abci.ErrBaseInvalidOutput},
+		{"referenceTooLong", fields{
+			Sender: TxTransferSender{
+				AccountID: uuid.NewV4().String(),
+				Currency:  "USD",
+				Amount:    100},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
+			Recipient: TxTransferRecipient{AccountID: uuid.NewV4().String()},
+			Reference: strings.Repeat("x", MaxReferenceLength+1)}, abci.ErrBaseInvalidInput},
+		{"memoTooLong", fields{
+			Sender: TxTransferSender{
+				AccountID: uuid.NewV4().String(),
+				Currency:  "USD",
+				Amount:    100},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
+			Recipient: TxTransferRecipient{AccountID: uuid.NewV4().String()},
+			Memo:      strings.Repeat("x", MaxMemoLength+1)}, abci.ErrBaseInvalidInput},
+		{"validWithReferenceAndMemo", fields{
+			Sender: TxTransferSender{
+				AccountID: uuid.NewV4().String(),
+				Currency:  "USD",
+				Amount:    100},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
+			Recipient: TxTransferRecipient{AccountID: uuid.NewV4().String()},
+			Reference: strings.Repeat("x", MaxReferenceLength),
+			Memo:      strings.Repeat("x", MaxMemoLength)}, abci.OK},
 		{"invalidCurrency", fields{
 			Sender: TxTransferSender{
 				AccountID: uuid.NewV4().String(),
 				Currency:  "invalid",
-				Amount:    100,
-				Sequence:  1},
-			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Signature: signature},
+				Amount:    100},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
 			Recipient: TxTransferRecipient{AccountID: uuid.NewV4().String()}}, abci.ErrBaseInvalidInput},
 		{"emptyCounterSigner", fields{
 			Sender: TxTransferSender{
 				AccountID: uuid.NewV4().String(),
 				Currency:  "USD",
 				Amount:    100,
-				Sequence:  1,
 			},
-			Committer:      TxTransferCommitter{Address: crypto.CRandBytes(20), Signature: signature},
+			Committer:      TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
 			CounterSigners: []TxTransferCounterSigner{TxTransferCounterSigner{}},
 			Recipient:      TxTransferRecipient{AccountID: uuid.NewV4().String()}}, abci.ErrBaseInvalidInput},
 		{"invalidCounterSignature", fields{
@@ -166,9 +198,8 @@ func TestTransferTx_ValidateBasic(t *testing.T) {
 				AccountID: uuid.NewV4().String(),
 				Currency:  "USD",
 				Amount:    100,
-				Sequence:  3,
 			},
-			Committer:      TxTransferCommitter{Address: crypto.CRandBytes(20), Signature: signature},
+			Committer:      TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
 			CounterSigners: []TxTransferCounterSigner{TxTransferCounterSigner{Address: crypto.CRandBytes(20)}},
 			Recipient:      TxTransferRecipient{AccountID: uuid.NewV4().String()}}, abci.ErrBaseInvalidSignature},
 		{"validWithCounterSignatures", fields{
@@ -176,9 +207,8 @@ func TestTransferTx_ValidateBasic(t *testing.T) {
 				AccountID: uuid.NewV4().String(),
 				Currency:  "USD",
 				Amount:    100,
-				Sequence:  3,
 			},
-			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Signature: signature},
+			Committer: TxTransferCommitter{Address: crypto.CRandBytes(20), Nonce: 1, Signature: signature},
 			CounterSigners: []TxTransferCounterSigner{TxTransferCounterSigner{
 				Address:   crypto.CRandBytes(20),
 				Signature: crypto.GenPrivKeyEd25519().Sign([]byte("test_content")),
@@ -189,15 +219,18 @@ func TestTransferTx_ValidateBasic(t *testing.T) {
 			Committer:      tt.fields.Committer,
 			Sender:         tt.fields.Sender,
 			Recipient:      tt.fields.Recipient,
+			Reference:      tt.fields.Reference,
+			Memo:           tt.fields.Memo,
 			CounterSigners: tt.fields.CounterSigners,
 		}
-		if got := tx.ValidateBasic(); got.Code != tt.want.Code {
+		if got := tx.ValidateBasic(currencies); got.Code != tt.want.Code {
 			t.Errorf("%q. TransferTx.ValidateBasic() = %v, want: %v", tt.name, got, tt.want)
 		}
 	}
 }
 
 func TestTxTransferSender_ValidateBasic(t *testing.T) {
+	currencies := NewCurrencyMap(NewCurrencyEntry(Currencies["USD"]), &CurrencyEntry{Symbol: "ACME-2030", MinimumUnit: 100})
 	tests := []struct {
 		name string
 		tx   TxTransferSender
@@ -224,14 +257,33 @@ func TestTxTransferSender_ValidateBasic(t *testing.T) {
 				AccountID: uuid.NewV4().String(),
 				Amount:    100,
 				Currency:  "XXX",
-				Sequence:  1,
+			}, abci.ErrBaseInvalidInput,
+		},
+		{
+			"customInstrument", TxTransferSender{
+				AccountID: uuid.NewV4().String(),
+				Amount:    200,
+				Currency:  "ACME-2030",
+			}, abci.OK,
+		},
+		{
+			"invalidAmountForInstrument", TxTransferSender{
+				AccountID: uuid.NewV4().String(),
+				Amount:    150,
+				Currency:  "ACME-2030",
+			}, abci.ErrBaseInvalidInput,
+		},
+		{
+			"unregisteredCurrency", TxTransferSender{
+				AccountID: uuid.NewV4().String(),
+				Amount:    100,
+				Currency:  "EUR",
 			}, abci.ErrBaseInvalidInput,
 		},
 		{
 			"invalidAccount", TxTransferSender{
 				Amount:   100,
 				Currency: "USD",
-				Sequence: 1,
 			}, abci.ErrBaseInvalidInput,
 		},
 		{
@@ -239,21 +291,12 @@ func TestTxTransferSender_ValidateBasic(t *testing.T) {
 				AccountID: uuid.NewV4().String(),
 				Amount:    100,
 				Currency:  "USD",
-				Sequence:  1,
 			}, abci.OK,
 		},
-		{
-			"invalidSequence", TxTransferSender{
-				AccountID: uuid.NewV4().String(),
-				Amount:    100,
-				Currency:  "USD",
-				Sequence:  0,
-			}, abci.ErrBaseInvalidSequence,
-		},
 	}
 
 	for _, tc := range tests {
-		if v := tc.tx.ValidateBasic(); tc.want.Code != v.Code {
+		if v := tc.tx.ValidateBasic(currencies); tc.want.Code != v.Code {
 			t.Errorf("%q. TxTransferSender.ValidateBasic() got = %v, want %v", tc.name, v, tc.want)
 		}
 	}
@@ -324,6 +367,8 @@ func TestTxTransferCounterSigner_String(t *testing.T) {
 func TestTxTransferCommitter_ValidateBasic(t *testing.T) {
 	type fields struct {
 		Address   []byte
+		Nonce     uint64
+		ClientID  string
 		Signature crypto.Signature
 	}
 	tests := []struct {
@@ -342,9 +387,32 @@ func TestTxTransferCommitter_ValidateBasic(t *testing.T) {
 				Address: crypto.CRandBytes(20),
 			}, abci.ErrBaseInvalidSignature,
 		},
+		{
+			"invalidNonce", fields{
+				Address:   crypto.CRandBytes(20),
+				Signature: crypto.GenPrivKeyEd25519().Sign([]byte("test_content")),
+			}, abci.ErrBaseInvalidSequence,
+		},
+		{
+			"invalidClientID", fields{
+				Address:   crypto.CRandBytes(20),
+				Nonce:     1,
+				ClientID:  "client-id",
+				Signature: crypto.GenPrivKeyEd25519().Sign([]byte("test_content")),
+			}, abci.ErrBaseInvalidInput,
+		},
 		{
 			"validInput", fields{
 				Address:   crypto.CRandBytes(20),
+				Nonce:     1,
+				Signature: crypto.GenPrivKeyEd25519().Sign([]byte("test_content")),
+			}, abci.OK,
+		},
+		{
+			"validInputWithClientID", fields{
+				Address:   crypto.CRandBytes(20),
+				Nonce:     1,
+				ClientID:  uuid.NewV4().String(),
 				Signature: crypto.GenPrivKeyEd25519().Sign([]byte("test_content")),
 			}, abci.OK,
 		},
@@ -353,6 +421,8 @@ func TestTxTransferCommitter_ValidateBasic(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tx := TxTransferCommitter{
 				Address:   tt.fields.Address,
+				Nonce:     tt.fields.Nonce,
+				ClientID:  tt.fields.ClientID,
 				Signature: tt.fields.Signature,
 			}
 			if got := tx.ValidateBasic(); got.Code != tt.want.Code {
@@ -365,119 +435,39 @@ func TestTxTransferCommitter_ValidateBasic(t *testing.T) {
 func TestTransferTx_SignTx(t *testing.T) {
 	privKey := crypto.GenPrivKeyEd25519()
 	addr := privKey.PubKey().Address()
-	type fields struct {
-		Committer      TxTransferCommitter
-		Sender         TxTransferSender
-		Recipient      TxTransferRecipient
-		CounterSigners []TxTransferCounterSigner
-	}
-	type args struct {
-		privateKey crypto.PrivKey
-		chainID    string
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
-	}{
-		{
-			"addressMismatch",
-			fields{TxTransferCommitter{Address: []byte{}}, TxTransferSender{}, TxTransferRecipient{}, nil},
-			args{privKey, "test"},
-			true,
-		},
-		{
-			"validSignature",
-			fields{TxTransferCommitter{Address: addr}, TxTransferSender{}, TxTransferRecipient{}, nil},
-			args{privKey, "test"},
-			false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tx := &TransferTx{
-				Committer:      tt.fields.Committer,
-				Sender:         tt.fields.Sender,
-				Recipient:      tt.fields.Recipient,
-				CounterSigners: tt.fields.CounterSigners,
-			}
-			if err := tx.SignTx(tt.args.privateKey, tt.args.chainID); (err != nil) != tt.wantErr {
-				t.Errorf("TransferTx.SignTx() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestTxTransferCommitter_SignTx(t *testing.T) {
-	privKey := crypto.GenPrivKeyEd25519()
-	addr := privKey.PubKey().Address()
-	type fields struct {
-		Address   []byte
-		Signature crypto.Signature
-	}
-	type args struct {
-		privateKey crypto.PrivKey
-		chainID    string
-	}
-	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
-	}{
-		{
-			"addressMismatch",
-			fields{[]byte{}, nil},
-			args{privKey, "test"},
-			true,
-		},
-		{
-			"validSignature",
-			fields{addr, nil},
-			args{privKey, "test"},
-			false,
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tx := &TxTransferCommitter{
-				Address:   tt.fields.Address,
-				Signature: tt.fields.Signature,
-			}
-			if err := tx.SignTx(tt.args.privateKey, tt.args.chainID); (err != nil) != tt.wantErr {
-				t.Errorf("TxTransferCommitter.SignTx() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
-	}
-}
-
-func TestTxTransferCounterSigner_SignTx(t *testing.T) {
-	type fields struct {
-		Address   []byte
-		Signature crypto.Signature
-	}
-	type args struct {
-		privateKey crypto.PrivKey
-		chainID    string
-	}
+	other := crypto.CRandBytes(20)
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name           string
+		committer      []byte
+		counterSigners []TxTransferCounterSigner
+		wantErr        bool
+		wantMissing    [][]byte
 	}{
-		{"alwaysOK", fields{}, args{crypto.GenPrivKeyEd25519(), "test"}, false},
+		{"addressMismatch", []byte{}, nil, true, [][]byte{{}}},
+		{"validSignature", addr, nil, false, [][]byte{}},
+		{"validCounterSignature", other, []TxTransferCounterSigner{{Address: addr}}, false, [][]byte{other}},
+		{"missingCounterSignature", addr, []TxTransferCounterSigner{{Address: other}}, false, [][]byte{other}},
 	}
 	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tx := &TxTransferCounterSigner{
-				Address:   tt.fields.Address,
-				Signature: tt.fields.Signature,
-			}
-			if err := tx.SignTx(tt.args.privateKey, tt.args.chainID); (err != nil) != tt.wantErr {
-				t.Errorf("TxTransferCounterSigner.SignTx() error = %v, wantErr %v", err, tt.wantErr)
+		tx := &TransferTx{
+			Committer:      TxTransferCommitter{Address: tt.committer},
+			CounterSigners: tt.counterSigners,
+		}
+		if err := tx.SignTx(privKey, "test"); (err != nil) != tt.wantErr {
+			t.Errorf("%q. TransferTx.SignTx() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got := tx.MissingSignatures(); !reflect.DeepEqual(got, tt.wantMissing) {
+			t.Errorf("%q. TransferTx.MissingSignatures() = %X, want %X", tt.name, got, tt.wantMissing)
+		}
+		// Counter signers sign the whole transfer, like the committer
+		signBytes := tx.SignBytes("test")
+		if sig := tx.Committer.Signature; sig != nil && !privKey.PubKey().VerifyBytes(signBytes, sig) {
+			t.Errorf("%q. TransferTx.SignTx() committer's signature doesn't match the transfer", tt.name)
+		}
+		for _, in := range tx.CounterSigners {
+			if in.Signature != nil && !privKey.PubKey().VerifyBytes(signBytes, in.Signature) {
+				t.Errorf("%q. TransferTx.SignTx() counter signer's signature doesn't match the transfer", tt.name)
 			}
-		})
+		}
 	}
 }
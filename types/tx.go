@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 
+	"github.com/satori/go.uuid"
 	abci "github.com/tendermint/abci/types"
 	common "github.com/tendermint/go-common"
 	"github.com/tendermint/go-crypto"
@@ -16,13 +17,34 @@ type Tx interface {
 	SignBytes(chainID string) []byte
 }
 
-// SignedTx extends Tx with a method to generate signatures.
+// SignedTx extends Tx with methods to generate signatures, or to set
+// signatures of its SignBytes generated elsewhere, e.g. by a remote signer.
 type SignedTx interface {
 	TxType() byte
 	SignBytes(chainID string) []byte
 	SignTx(privateKey crypto.PrivKey, chainID string) error
+	SetSignature(addr []byte, sig crypto.Signature) bool
 }
 
+// NoncedTx extends Tx with the nonce of the user who signs it. A user's
+// nonces must be used in order, starting from 1, so that Txs can't be replayed.
+// A Tx may also expire: it can't be executed after its ValidUntilHeight,
+// unless that is 0.
+// A Tx may carry a ClientID, which makes its submission idempotent: once the
+// Tx is executed, a Tx of the same signer with the same ClientID is rejected
+// with CodeTypeDuplicateTx instead of being executed again.
+type NoncedTx interface {
+	Tx
+	Signer() []byte
+	GetNonce() uint64
+	GetValidUntilHeight() uint64
+	GetClientID() string
+}
+
+// CodeTypeDuplicateTx is the result code of a Tx whose ClientID was already
+// executed. The result's data is the original Tx's TxResult as JSON.
+const CodeTypeDuplicateTx = abci.CodeType(1001)
+
 // TxExecutor validates Tx execution permission
 type TxExecutor interface {
 	CanExecTx(byte) bool
@@ -45,6 +67,11 @@ var _ = wire.RegisterInterface(
 	wire.ConcreteType{O: &CreateAccountTx{}, Byte: TxTypeCreateAccount},
 	wire.ConcreteType{O: &CreateLegalEntityTx{}, Byte: TxTypeCreateLegalEntity},
 	wire.ConcreteType{O: &CreateUserTx{}, Byte: TxTypeCreateUser},
+	wire.ConcreteType{O: &IssueTx{}, Byte: TxTypeIssue},
+	wire.ConcreteType{O: &RedeemTx{}, Byte: TxTypeRedeem},
+	wire.ConcreteType{O: &AddCurrencyTx{}, Byte: TxTypeAddCurrency},
+	wire.ConcreteType{O: &UpdateCurrencyTx{}, Byte: TxTypeUpdateCurrency},
+	wire.ConcreteType{O: &RetireCurrencyTx{}, Byte: TxTypeRetireCurrency},
 )
 
 // SignTx signs the transaction if its address and the privateKey's one match.
@@ -55,3 +82,18 @@ func SignTx(signedBytes []byte, addr []byte, privKey crypto.PrivKey) (crypto.Sig
 	}
 	return privKey.Sign(signedBytes), nil
 }
+
+// validateClientID checks that a Tx's ClientID, if any, is a UUID.
+// A Tx without ClientID is still executed at most once, as resending the
+// same signed Tx reuses its nonce. The ClientID is needed by a client that
+// may sign the Tx again with another nonce, e.g. after a timeout: the client
+// SDK thus gives one to every Tx it signs or broadcasts.
+func validateClientID(clientID string) abci.Result {
+	if len(clientID) == 0 {
+		return abci.OK
+	}
+	if _, err := uuid.FromString(clientID); err != nil {
+		return abci.ErrBaseInvalidInput.AppendLog(common.Fmt("Invalid client_id: %s", err))
+	}
+	return abci.OK
+}
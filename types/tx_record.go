@@ -0,0 +1,55 @@
+package types
+
+import (
+	"fmt"
+
+	abci "github.com/tendermint/abci/types"
+	"github.com/tendermint/go-wire"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// TxRecord is the record of a Tx delivered in a block, whether its execution
+// succeeded or not, by which anyone can find out what happened to a Tx.
+type TxRecord struct {
+	Hash   []byte        `json:"hash"`   // See TxHash
+	Height uint64        `json:"height"` // Height of the block the Tx was delivered in
+	Type   byte          `json:"type"`
+	Signer []byte        `json:"signer"` // Address of the user who signed the Tx
+	Code   abci.CodeType `json:"code"`
+	Log    string        `json:"log,omitempty"`
+	Tx     []byte        `json:"tx"` // The Tx as delivered, see DecodeTx
+}
+
+// NewTxRecord records the result of a Tx delivered at the given height.
+func NewTxRecord(txBytes []byte, tx NoncedTx, height uint64, res abci.Result) *TxRecord {
+	return &TxRecord{
+		Hash:   TxHash(txBytes),
+		Height: height,
+		Type:   tx.TxType(),
+		Signer: tx.Signer(),
+		Code:   res.Code,
+		Log:    res.Log,
+		Tx:     txBytes,
+	}
+}
+
+// TxHash returns the hash Tendermint identifies a Tx's bytes by.
+func TxHash(txBytes []byte) []byte {
+	hasher := ripemd160.New()
+	hasher.Write(wire.BinaryBytes(txBytes))
+	return hasher.Sum(nil)
+}
+
+// DecodeTx decodes the recorded Tx with the Tx wire registry.
+func (r *TxRecord) DecodeTx() (Tx, error) {
+	var tx Tx
+	err := wire.ReadBinaryBytes(r.Tx, &tx)
+	return tx, err
+}
+
+func (r *TxRecord) String() string {
+	if r == nil {
+		return "nil-TxRecord"
+	}
+	return fmt.Sprintf("TxRecord{%X %v: %X %X %v %q}", r.Hash, r.Height, r.Type, r.Signer, r.Code, r.Log)
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"fmt"
+
+	abci "github.com/tendermint/abci/types"
+)
+
+// TxResult is the record of an executed Tx that carried a ClientID,
+// by which clients find out whether a Tx they submitted was executed.
+type TxResult struct {
+	ClientID string        `json:"client_id"`
+	Signer   []byte        `json:"signer"` // Address of the user who signed the Tx
+	Nonce    uint64        `json:"nonce"`
+	Height   uint64        `json:"height"` // Height of the block the Tx was executed in
+	Code     abci.CodeType `json:"code"`
+	Data     []byte        `json:"data,omitempty"`
+	Log      string        `json:"log,omitempty"`
+}
+
+// NewTxResult records the result of a Tx executed at the given height.
+func NewTxResult(tx NoncedTx, height uint64, res abci.Result) *TxResult {
+	return &TxResult{
+		ClientID: tx.GetClientID(),
+		Signer:   tx.Signer(),
+		Nonce:    tx.GetNonce(),
+		Height:   height,
+		Code:     res.Code,
+		Data:     res.Data,
+		Log:      res.Log,
+	}
+}
+
+func (r *TxResult) String() string {
+	if r == nil {
+		return "nil-TxResult"
+	}
+	return fmt.Sprintf("TxResult{%s %X/%v %v: %v %q}", r.ClientID, r.Signer, r.Nonce, r.Height, r.Code, r.Log)
+}
@@ -61,3 +61,38 @@ func TestSignTx(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedTx_SetSignature(t *testing.T) {
+	privKey := crypto.GenPrivKeyEd25519()
+	addr := privKey.PubKey().Address()
+	sig := privKey.Sign([]byte("sign bytes"))
+	txs := []SignedTx{
+		&CreateUserTx{Address: addr},
+		&CreateAccountTx{Address: addr},
+		&CreateLegalEntityTx{Address: addr},
+		&IssueTx{Address: addr},
+		&RedeemTx{Address: addr},
+		&AddCurrencyTx{Address: addr},
+		&UpdateCurrencyTx{Address: addr},
+		&RetireCurrencyTx{Address: addr},
+		&TransferTx{Committer: TxTransferCommitter{Address: addr}},
+	}
+	for _, tx := range txs {
+		if tx.SetSignature(crypto.CRandBytes(20), sig) {
+			t.Errorf("%T.SetSignature() of another address = true, want false", tx)
+		}
+		if !tx.SetSignature(addr, sig) {
+			t.Errorf("%T.SetSignature() = false, want true", tx)
+		}
+		if got := signatureOf(tx); !reflect.DeepEqual(got, sig) {
+			t.Errorf("%T.SetSignature() set %v, want %v", tx, got, sig)
+		}
+	}
+}
+
+func signatureOf(tx SignedTx) crypto.Signature {
+	if transfer, ok := tx.(*TransferTx); ok {
+		return transfer.Committer.Signature
+	}
+	return reflect.ValueOf(tx).Elem().FieldByName("Signature").Interface().(crypto.Signature)
+}
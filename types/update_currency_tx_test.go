@@ -0,0 +1,34 @@
+package types
+
+import (
+	"testing"
+
+	abci "github.com/tendermint/abci/types"
+	crypto "github.com/tendermint/go-crypto"
+)
+
+func TestUpdateCurrencyTx_ValidateBasic(t *testing.T) {
+	addr := crypto.CRandBytes(20)
+	sig := crypto.GenPrivKeyEd25519().Sign(crypto.CRandBytes(20))
+	currency := CurrencyEntry{Symbol: "XAU-G", Name: "Gold gram", DecimalPlaces: 3, MinimumUnit: 1}
+	retired := currency
+	retired.Retired = true
+	tests := []struct {
+		name string
+		tx   UpdateCurrencyTx
+		want abci.Result
+	}{
+		{"emptyTx", UpdateCurrencyTx{}, abci.ErrBaseInvalidInput},
+		{"invalidSignature", UpdateCurrencyTx{addr, 1, 0, "", currency, nil}, abci.ErrBaseInvalidSignature},
+		{"invalidCurrency", UpdateCurrencyTx{addr, 1, 0, "", CurrencyEntry{Symbol: "gold"}, sig}, abci.ErrBaseInvalidInput},
+		{"retiredCurrency", UpdateCurrencyTx{addr, 1, 0, "", retired, sig}, abci.ErrBaseInvalidInput},
+		{"invalidNonce", UpdateCurrencyTx{addr, 0, 0, "", currency, sig}, abci.ErrBaseInvalidSequence},
+		{"invalidClientID", UpdateCurrencyTx{addr, 1, 0, "client-id", currency, sig}, abci.ErrBaseInvalidInput},
+		{"valid", UpdateCurrencyTx{addr, 1, 0, "", currency, sig}, abci.OK},
+	}
+	for _, tt := range tests {
+		if got := tt.tx.ValidateBasic(); got.Code != tt.want.Code {
+			t.Errorf("%q. UpdateCurrencyTx.ValidateBasic() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -8,10 +8,11 @@ import (
 
 // User defines the attribute of a ledger's user
 type User struct {
-	PubKey      crypto.PubKey `json:"pub_key"`     // May be nil, if not known.
-	Name        string        `json:"name"`        // Human-readable identifier, mandatory
-	EntityID    string        `json:"entity_id"`   // LegalEntity's ID
-	Permissions Perm          `json:"permissions"` // User is disabled if empty
+	PubKey      crypto.PubKey `json:"pub_key"`         // May be nil, if not known.
+	Name        string        `json:"name"`            // Human-readable identifier, mandatory
+	EntityID    string        `json:"entity_id"`       // LegalEntity's ID
+	Permissions Perm          `json:"permissions"`     // User is disabled if empty
+	Nonce       uint64        `json:"nonce,omitempty"` // Nonce of the user's last Tx
 }
 
 // NewUser initializes a new user
@@ -29,7 +30,8 @@ func NewUser(pubKey crypto.PubKey, name string, entityID string, permissions Per
 // Equal provides an equality operator
 func (u *User) Equal(v *User) bool {
 	if u != nil && v != nil {
-		return u.PubKey.Equals(v.PubKey) && u.Name == v.Name && u.EntityID == v.EntityID && u.Permissions == v.Permissions
+		return u.PubKey.Equals(v.PubKey) && u.Name == v.Name && u.EntityID == v.EntityID && u.Permissions == v.Permissions &&
+			u.Nonce == v.Nonce
 	}
 	return u == v
 }
@@ -51,6 +53,13 @@ func (u *User) String() string {
 	return fmt.Sprintf("User{%s %q %v}", u.EntityID, u.Name, u.Permissions)
 }
 
+// UsersReturned defines the attributes of response's payload.
+// It must be encoded with go-wire's JSON codec because of PubKey.
+type UsersReturned struct {
+	Users []*User `json:"users"`
+	Next  string  `json:"next,omitempty"` // Cursor of the next page, empty on the last one
+}
+
 //--------------------------------------------
 
 // UserGetter is implemented by any value that has a GetUser
@@ -21,7 +21,7 @@ func TestNewUser(t *testing.T) {
 	}{
 		{"nilPubKey", args{nil, "test", "entity", 0}, nil},
 		{"emptyName", args{crypto.GenPrivKeyEd25519().PubKey(), "", "entity", 0}, nil},
-		{"nonNil", args{privKey.PubKey(), "test", "entity", 0}, &User{privKey.PubKey(), "test", "entity", 0}},
+		{"nonNil", args{privKey.PubKey(), "test", "entity", 0}, &User{privKey.PubKey(), "test", "entity", 0, 0}},
 	}
 	for _, tt := range tests {
 		if got := NewUser(tt.args.pubKey, tt.args.name, tt.args.entityID, tt.args.permissions); !got.Equal(tt.want) {
@@ -47,8 +47,9 @@ func TestUser_Equal(t *testing.T) {
 		args   args
 		want   bool
 	}{
-		{"equal", fields{privKey.PubKey(), "test", "entity", 0}, args{&User{privKey.PubKey(), "test", "entity", 0}}, true},
-		{"notEqual", fields{privKey.PubKey(), "test", "", 0}, args{&User{privKey.PubKey(), "test", "entity", 0}}, false},
+		{"equal", fields{privKey.PubKey(), "test", "entity", 0}, args{&User{privKey.PubKey(), "test", "entity", 0, 0}}, true},
+		{"notEqual", fields{privKey.PubKey(), "test", "", 0}, args{&User{privKey.PubKey(), "test", "entity", 0, 0}}, false},
+		{"nonceNotEqual", fields{privKey.PubKey(), "test", "entity", 0}, args{&User{privKey.PubKey(), "test", "entity", 0, 1}}, false},
 	}
 	for _, tt := range tests {
 		u := &User{